@@ -4,28 +4,89 @@ import (
 	"2gis-parser/internal/adapter/logger"
 	"2gis-parser/internal/adapter/twogis"
 	"2gis-parser/internal/config"
+	"2gis-parser/internal/domain"
+	"2gis-parser/internal/helper"
 	"2gis-parser/internal/store"
 	"2gis-parser/internal/usecase"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/schedule"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// defaultSchedule starts a crawl an hour after the previous one finished
+const defaultSchedule = "@every 1h"
+
 func main() {
 	l := logger.New("development")
 
+	// Cancel everything on SIGINT/SIGTERM so in-flight work can finish and the process exits cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	err := godotenv.Load()
 	if err != nil {
 		l.Info("No .env file found, using environment variables")
 	}
 
 	cfg := config.Load()
-	if cfg.TwoGisAPIKey == "" {
-		l.Fatal("TWO_GIS_API_KEY not found in environment, using default key for testing")
-	}
+
+	// Parse command line flags
+	var (
+		collectRubrics = flag.Bool("collect-rubrics", false, "Collect rubrics data from tourism keywords")
+		regionID       = flag.String("region", "67", "Region ID for rubrics collection (default: 67 for Almaty)")
+		regions        = flag.String("regions", "", "Comma separated region IDs to crawl (default: all Kazakhstan regions)")
+		rubrics        = flag.String("rubrics", "", "Comma separated rubric IDs to crawl (default: loaded from -rubrics-file)")
+		rubricsFile    = flag.String("rubrics-file", usecase.DefaultRubricsFile, "Path to filtered rubrics CSV file")
+		fresh          = flag.Bool("fresh", false, "Discard crawl checkpoints and start from the first region")
+		resumeAge      = flag.Duration("resume-age", store.DefaultResumeAge, "Start over instead of resuming an unfinished crawl whose oldest checkpoint is older than this")
+		tiling         = flag.Bool("tiling", false, "Split regions into a grid of cells to get past per-query result caps")
+		fullRefresh    = flag.Bool("full-refresh", false, "Fetch full details of every business instead of only those changed since the last run")
+		fullRefreshAge = flag.Duration("full-refresh-age", store.DefaultFullRefreshAge, "Fetch full details of businesses not fully fetched for this long even if unchanged")
+		sweep          = flag.Bool("sweep", true, "Re-check businesses no longer listed after a complete rubric crawl and soft-delete closed ones")
+		closureMisses  = flag.Int("closure-misses", store.DefaultClosureMisses, "Consecutive failed re-checks before a business is soft-deleted")
+		keywordsFile   = flag.String("keywords", usecase.DefaultKeywordsFile, "Path to keywords CSV file")
+		discover       = flag.Bool("discover", false, "Search every tourism keyword in every region and ingest businesses the rubric crawl misses")
+		outputFile     = flag.String("output", "rubrics_data.csv", "Output CSV file for rubrics data")
+		rubricTreeFile = flag.String("rubric-tree", "rubric_tree.json", "Output JSON file for the rubric hierarchy")
+		suggestedFile  = flag.String("suggested-rubrics", usecase.DefaultRubricsFile, "Output CSV file for suggested rubrics, read by the parser")
+		minKeywordHits = flag.Int("min-keyword-hits", twogis.DefaultMinKeywordHits, "Minimum number of tourism keywords returning a rubric to suggest it")
+		diffRubrics    = flag.Bool("diff", false, "With -collect-rubrics, only show suggested rubrics added or removed since the last export")
+		singleBusiness = flag.String("business", "", "Fetch and store a single business by ID")
+		recordDir      = flag.String("record", "", "Save raw 2GIS responses to this fixtures directory")
+		replayDir      = flag.String("replay", "", "Serve 2GIS responses from this fixtures directory instead of the API")
+		remapTypes     = flag.String("remap-types", "", "Re-map accommodation types of existing rows of a source (2gis or booking) and exit")
+		remapAll       = flag.Bool("remap-all", false, "With -remap-types, re-map rows already mapped with the current taxonomy version")
+		backfill       = flag.Bool("backfill-regions", false, "Locate the region and city of existing rows of every source from their coordinates and exit")
+		backfillAll    = flag.Bool("backfill-all", false, "With -backfill-regions, also re-locate rows that already have a region, e.g. after the boundaries changed")
+		collectAttrs   = flag.Bool("collect-attributes", false, "Build a catalog of 2GIS attributes of the selected regions and rubrics without storing businesses")
+		attrCatalog    = flag.String("attribute-catalog", "attribute_catalog.json", "Output JSON file for the attribute catalog")
+		amenityMapping = flag.String("amenity-mapping", "amenity_mapping.json", "Output JSON file for the draft amenity mapping to review")
+		metricsAddr    = flag.String("metrics-addr", metrics.Addr(), "Address to serve Prometheus /metrics on while parsing, empty to disable")
+		scheduleSpec   = flag.String("schedule", schedule.Spec(defaultSchedule), "Cron expression or descriptor (e.g. \"0 */2 * * *\", \"@every 1h\") crawl runs are scheduled with")
+		jitter         = flag.Duration("jitter", 2*time.Minute, "Maximum random delay added to every scheduled run")
+		runOnStart     = flag.Bool("run-on-start", true, "Start the first crawl immediately instead of at the first scheduled time")
+		controlAddr    = flag.String("control-addr", schedule.ControlAddr(), "Address to serve the run control endpoint on, empty to disable")
+	)
+	// Inserts are written in bulk with COPY, the worker pool these flags configured is gone.
+	// They are still accepted so existing deployments keep starting.
+	flag.Bool("parallel", true, "Deprecated, has no effect: inserts are written in bulk")
+	flag.Int("workers", 5, "Deprecated, has no effect: inserts are written in bulk")
+	flag.Parse()
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "parallel" || f.Name == "workers" {
+			l.Info("-%s is deprecated and has no effect, inserts are written in bulk", f.Name)
+		}
+	})
 
 	// Initialize database connection
 	dbConfig := store.LoadConfigFromEnv()
@@ -35,32 +96,37 @@ func main() {
 	}
 	defer dbStore.Close()
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	api := twogis.NewAPI(client, cfg.TwoGisAPIKey, l)
-	parser := usecase.NewParserWithStore(api, l, dbStore)
+	if *remapTypes != "" {
+		l.Info("Re-mapping accommodation types of %s rows", *remapTypes)
+		summary, err := dbStore.RemapAccommodationTypes(ctx, *remapTypes, *remapAll)
+		if err != nil {
+			l.Fatal("Failed to re-map accommodation types: %v", err)
+		}
+		l.Info("Re-mapped %s rows to taxonomy version %d: %d checked, %d changed type, %d updated",
+			*remapTypes, summary.Version, summary.Checked, summary.Changed, summary.Updated)
+		return
+	}
 
-	// Parse command line flags
-	var (
-		collectRubrics = flag.Bool("collect-rubrics", false, "Collect rubrics data from tourism keywords")
-		regionID       = flag.String("region", "67", "Region ID for rubrics collection (default: 67 for Almaty)")
-		keywordsFile   = flag.String("keywords", "docks/tourism_keywords.csv", "Path to keywords CSV file")
-		outputFile     = flag.String("output", "rubrics_data.csv", "Output CSV file for rubrics data")
-		singleBusiness = flag.String("business", "", "Fetch and store a single business by ID")
-		parallel       = flag.Bool("parallel", true, "Use parallel processing for database inserts (default: true)")
-		workers        = flag.Int("workers", 5, "Number of parallel workers for database inserts (default: 5)")
-	)
-	flag.Parse()
+	if *backfill {
+		l.Info("Locating regions and cities of existing rows")
+		summary, err := dbStore.BackfillRegions(ctx, *backfillAll)
+		if err != nil {
+			l.Fatal("Failed to locate regions: %v", err)
+		}
+		l.Info("Located regions: %d checked, %d in a region, %d outside every region, %d updated",
+			summary.Checked, summary.Located, summary.Outside, summary.Updated)
+		return
+	}
 
-	// Configure parallel processing if specified
-	if *parallel {
-		l.Info("Parallel processing enabled with %d workers", *workers)
-	} else {
-		l.Info("Sequential processing enabled")
+	api, err := newAPI(cfg, l, *recordDir, *replayDir)
+	if err != nil {
+		l.Fatal("Failed to create 2GIS API client: %v", err)
 	}
+	parser := usecase.NewParserWithStore(api, l, dbStore)
 
 	if *singleBusiness != "" {
 		l.Info("Fetching single business with ID: %s", *singleBusiness)
-		if err := parser.RunSingleBusiness(*singleBusiness); err != nil {
+		if err := parser.RunSingleBusiness(ctx, *singleBusiness); err != nil {
 			l.Fatal("Failed to fetch single business: %v", err)
 		}
 		return
@@ -68,50 +134,208 @@ func main() {
 
 	if *collectRubrics {
 		l.Info("Starting rubrics collection process")
-		if err := collectRubricsData(api, l, *regionID, *keywordsFile, *outputFile); err != nil {
+		opts := rubricsOptions{
+			RegionID:       *regionID,
+			KeywordsFile:   *keywordsFile,
+			OutputFile:     *outputFile,
+			TreeFile:       *rubricTreeFile,
+			SuggestedFile:  *suggestedFile,
+			MinKeywordHits: *minKeywordHits,
+			DiffOnly:       *diffRubrics,
+		}
+		if err := collectRubricsData(ctx, api, l, opts); err != nil {
 			l.Fatal("Failed to collect rubrics: %v", err)
 		}
 		return
 	}
 
-	for {
-		if err := parser.Run(); err != nil {
-			l.Fatal("failed to run parser: %v", err)
+	go func() {
+		if err := metrics.Serve(ctx, *metricsAddr); err != nil {
+			l.Error("Failed to serve metrics on %s: %v", *metricsAddr, err)
+		}
+	}()
+
+	// Fixtures may lack the detail requests of a sweep and say nothing about businesses today,
+	// so a replayed crawl must never soft-delete anything
+	if *replayDir != "" && *sweep {
+		l.Info("Closure sweep disabled while replaying fixtures")
+		*sweep = false
+	}
+
+	runOpts := usecase.RunOptions{
+		RegionIDs:      helper.SplitList(*regions),
+		RubricIDs:      helper.SplitList(*rubrics),
+		RubricsFile:    *rubricsFile,
+		Fresh:          *fresh,
+		ResumeAge:      *resumeAge,
+		Tiling:         *tiling,
+		FullRefresh:    *fullRefresh,
+		FullRefreshAge: *fullRefreshAge,
+		Sweep:          *sweep,
+		ClosureMisses:  *closureMisses,
+	}
+
+	if *discover {
+		discoveryOpts := usecase.DiscoveryOptions{RunOptions: runOpts, KeywordsFile: *keywordsFile}
+		if _, err := parser.RunDiscovery(ctx, discoveryOpts); err != nil {
+			if errors.Is(err, context.Canceled) {
+				l.Info("Shutdown requested, discovery stopped")
+				return
+			}
+			l.Fatal("Failed to run keyword discovery: %v", err)
+		}
+		return
+	}
+
+	if *collectAttrs {
+		l.Info("Starting attribute catalog collection")
+		attrOpts := usecase.AttributeOptions{
+			RunOptions:  runOpts,
+			CatalogFile: *attrCatalog,
+			MappingFile: *amenityMapping,
 		}
-		l.Info("Parser run completed, sleeping for 10 minutes before next run")
-		time.Sleep(60 * time.Minute)
+		if _, err := parser.CollectAttributes(ctx, attrOpts, dbStore.AmenityMapping()); err != nil {
+			l.Fatal("Failed to collect attributes: %v", err)
+		}
+		return
+	}
+
+	scheduler, err := schedule.New(*scheduleSpec, *jitter, func(ctx context.Context) error {
+		err := parser.Run(ctx, runOpts)
+		// Only the first run may discard checkpoints or force a full refresh, later runs resume as usual
+		runOpts.Fresh = false
+		runOpts.FullRefresh = false
+		return err
+	}, l)
+	if err != nil {
+		l.Fatal("Failed to create scheduler: %v", err)
+	}
+
+	go func() {
+		if err := scheduler.Serve(ctx, *controlAddr); err != nil {
+			l.Error("Failed to serve run control on %s: %v", *controlAddr, err)
+		}
+	}()
+
+	l.Info("Scheduling crawl runs with %q, jitter up to %v", *scheduleSpec, *jitter)
+	scheduler.Run(ctx, *runOnStart)
+	l.Info("Shutdown requested, parser stopped cleanly")
+}
+
+// newAPI creates the 2GIS client, optionally recording responses to or replaying them from a fixtures directory
+func newAPI(cfg *config.Config, l *logger.Logger, recordDir, replayDir string) (*twogis.API, error) {
+	if recordDir != "" && replayDir != "" {
+		return nil, errors.New("-record and -replay cannot be used together")
 	}
+
+	if replayDir != "" {
+		l.Info("Replaying 2GIS responses from %s", replayDir)
+		return twogis.NewReplayAPI(replayDir, l)
+	}
+
+	if cfg.TwoGisAPIKey == "" {
+		return nil, errors.New("TWO_GIS_API_KEY not found in environment")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	limiter := twogis.NewRateLimiter(cfg.TwoGisRequestsPerSecond, twogis.DefaultBurst)
+
+	if recordDir != "" {
+		l.Info("Recording 2GIS responses to %s", recordDir)
+		return twogis.NewRecordingAPI(client, cfg.TwoGisAPIKey, l, limiter, recordDir)
+	}
+	return twogis.NewAPIWithLimiter(client, cfg.TwoGisAPIKey, l, limiter), nil
+}
+
+// rubricsOptions configures the rubrics collection
+type rubricsOptions struct {
+	RegionID       string
+	KeywordsFile   string
+	OutputFile     string
+	TreeFile       string
+	SuggestedFile  string
+	MinKeywordHits int
+	DiffOnly       bool // only report changes of the suggested rubrics, write nothing
 }
 
-func collectRubricsData(api *twogis.API, l *logger.Logger, regionID, keywordsFile, outputFile string) error {
+func collectRubricsData(ctx context.Context, api *twogis.API, l *logger.Logger, opts rubricsOptions) error {
 	// Create rubric collector
 	collector := twogis.NewRubricCollector(api, l)
 
 	// Load keywords from CSV file
-	keywords, err := collector.LoadKeywordsFromCSV(keywordsFile)
+	keywords, err := collector.LoadKeywordsFromCSV(opts.KeywordsFile)
 	if err != nil {
 		return fmt.Errorf("failed to load keywords: %w", err)
 	}
 
-	l.Info("Loaded %d keywords from %s", len(keywords), keywordsFile)
+	l.Info("Loaded %d keywords from %s", len(keywords), opts.KeywordsFile)
 
 	// Collect rubrics from keywords
-	rubrics, err := collector.CollectRubricsFromKeywords(keywords, regionID)
+	rubrics, err := collector.CollectRubricsFromKeywords(ctx, keywords, opts.RegionID)
 	if err != nil {
 		return fmt.Errorf("failed to collect rubrics: %w", err)
 	}
 
 	l.Info("Collected %d unique rubrics", len(rubrics))
 
+	suggestions := collector.SuggestRubrics(rubrics, len(keywords), opts.MinKeywordHits)
+	suggested := make([]domain.RubricData, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		suggested = append(suggested, suggestion.Rubric)
+	}
+
+	// Compare with the previous export, a missing file means everything is new
+	previous, err := collector.LoadRubricsFromCSV(opts.SuggestedFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load previous rubrics: %w", err)
+		}
+		previous = nil
+	}
+	diff := collector.DiffRubrics(previous, suggested)
+	printRubricDiff(l, opts.SuggestedFile, diff)
+
+	if opts.DiffOnly {
+		return nil
+	}
+
 	// Generate detailed report
 	collector.GenerateRubricReport(rubrics)
 
 	// Save rubrics to CSV file
-	if err := collector.SaveRubricsToCSV(rubrics, outputFile); err != nil {
+	if err := collector.SaveRubricsToCSV(rubrics, opts.OutputFile); err != nil {
 		return fmt.Errorf("failed to save rubrics to CSV: %w", err)
 	}
 
-	l.Info("Rubrics data saved to %s", outputFile)
+	l.Info("Rubrics data saved to %s", opts.OutputFile)
+
+	tree, err := collector.BuildRubricTree(ctx, rubrics, len(keywords), opts.RegionID)
+	if err != nil {
+		return fmt.Errorf("failed to build rubric tree: %w", err)
+	}
+	if err := collector.SaveRubricTreeJSON(tree, opts.TreeFile); err != nil {
+		return err
+	}
+
+	if err := collector.SaveSuggestedRubricsCSV(suggestions, opts.SuggestedFile); err != nil {
+		return fmt.Errorf("failed to save suggested rubrics: %w", err)
+	}
 
 	return nil
 }
+
+// printRubricDiff logs suggested rubrics added or removed compared to the previous export
+func printRubricDiff(l *logger.Logger, filename string, diff domain.RubricDiff) {
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 {
+		l.Info("Suggested rubrics are unchanged since the last export to %s", filename)
+		return
+	}
+
+	l.Info("Suggested rubrics compared to %s: %d added, %d removed", filename, len(diff.Added), len(diff.Removed))
+	for _, rubric := range diff.Added {
+		l.Info("  + %s %s (%d keyword hits, %d businesses)", rubric.ID, rubric.Name, rubric.KeywordHits, rubric.Count)
+	}
+	for _, rubric := range diff.Removed {
+		l.Info("  - %s %s", rubric.ID, rubric.Name)
+	}
+}
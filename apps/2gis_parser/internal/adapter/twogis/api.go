@@ -3,17 +3,21 @@ package twogis
 import (
 	"2gis-parser/internal/domain"
 	"2gis-parser/internal/helper"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
+	"sync/atomic"
 )
 
 type API struct {
-	client *http.Client
-	apiKey string
-	logger Logger
+	client   *http.Client
+	apiKey   string
+	logger   Logger
+	limiter  *RateLimiter
+	requests atomic.Int64 // requests sent, retries included
 }
 
 type Logger interface {
@@ -21,74 +25,84 @@ type Logger interface {
 	Error(msg string, args ...interface{})
 }
 
+// Default request rate used when no rate limiter is configured
+const (
+	DefaultRequestsPerSecond = 5
+	DefaultBurst             = 5
+)
+
 func NewAPI(client *http.Client, apiKey string, logger Logger) *API {
-	return &API{client: client, apiKey: apiKey, logger: logger}
+	return NewAPIWithLimiter(client, apiKey, logger, NewRateLimiter(DefaultRequestsPerSecond, DefaultBurst))
 }
 
-func (a *API) FetchBusinesses(categoryID string, regionID string, fullInfo bool) ([]domain.BusinessDetail, error) {
-	var allBusinesses []domain.BusinessDetail
-	page := 1
-	pageSize := 50
+// NewAPIWithLimiter creates an API client whose requests all go through the given rate limiter
+func NewAPIWithLimiter(client *http.Client, apiKey string, logger Logger, limiter *RateLimiter) *API {
+	return &API{client: client, apiKey: apiKey, logger: logger, limiter: limiter}
+}
 
-	for {
-		// Build the base URL
-		url := fmt.Sprintf("https://catalog.api.2gis.com/3.0/items?rubric_id=%s&key=%s&region_id=%s", categoryID, a.apiKey, regionID)
-
-		// Add fields if fullInfo is true
-		if fullInfo {
-			// Load fields from CSV file
-			fields, err := helper.LoadFieldsFromCSV("docks/fields.csv")
-			if err != nil {
-				a.logger.Error("Failed to load fields from CSV: %v", err)
-				return nil, fmt.Errorf("failed to load fields from CSV: %w", err)
-			}
+// RequestCount returns the number of requests sent since the client was created, retries included
+func (a *API) RequestCount() int64 {
+	return a.requests.Load()
+}
 
-			if len(fields) > 0 {
-				url += "&fields=" + strings.Join(fields, ",")
-			}
-		}
+// businessesPageSize is the page size used for every items request
+const businessesPageSize = 50
 
-		// Add pagination parameters
-		url += fmt.Sprintf("&page=%d&page_size=%d", page, pageSize)
+// detailBatchSize is the number of IDs requested at once from the byid endpoint
+const detailBatchSize = 20
 
-		// a.logger.Info("Fetching businesses from 2GIS API (page %d): %s", page, url)
+// detailFields are the fields requested for full business details
+const detailFields = "items.point,items.full_address_name,items.rubrics,items.schedule,items.description,items.flags,items.reviews,items.statistics,items.dates.updated_at,items.caption,items.stat,items.schedule_special,items.attribute_groups,items.reg_bc_url,items.address,items.links,items.summary,items.contact_groups,items.external_content"
 
-		resp, err := a.client.Get(url)
-		if err != nil {
-			a.logger.Error("Failed to make API request for businesses (page %d): %v", page, err)
-			// If it's the first page, return the error, otherwise return what we have
-			if page == 1 {
-				return nil, fmt.Errorf("failed to make API request: %w", err)
-			}
-			a.logger.Info("Error on page %d, returning %d businesses collected so far", page, len(allBusinesses))
-			break
-		}
-		defer resp.Body.Close()
+// lightweightFields are requested when full info is not needed, enough to decide whether
+// a business changed since it was stored and which rubrics it belongs to
+const lightweightFields = "items.dates.updated_at,items.rubrics"
 
-		if resp.StatusCode != http.StatusOK {
-			a.logger.Error("API request failed with status code: %d (page %d)", resp.StatusCode, page)
-			// If it's the first page, return the error, otherwise return what we have
-			if page == 1 {
-				return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
-			}
-			a.logger.Info("Error on page %d, returning %d businesses collected so far", page, len(allBusinesses))
-			break
+// FetchBusinesses paginates businesses of a rubric in a region starting from startPage.
+// When onPage is set it receives every page right after it is fetched, so callers can
+// store results and checkpoint progress before the next request is made.
+func (a *API) FetchBusinesses(ctx context.Context, categoryID string, regionID string, fullInfo bool, startPage int, onPage domain.PageHandler) ([]domain.BusinessDetail, error) {
+	query := fmt.Sprintf("rubric_id=%s&region_id=%s", categoryID, regionID)
+	label := fmt.Sprintf("rubric ID: %s in region: %s", categoryID, regionID)
+	return a.paginate(ctx, query, label, fullInfo, startPage, onPage)
+}
+
+// FetchBusinessesByKeyword paginates the search results of a keyword in a region.
+// Only the lightweight field set is requested, full details are fetched separately.
+func (a *API) FetchBusinessesByKeyword(ctx context.Context, keyword string, regionID string, onPage domain.PageHandler) ([]domain.BusinessDetail, error) {
+	query := fmt.Sprintf("q=%s&region_id=%s&type=branch", url.QueryEscape(keyword), regionID)
+	label := fmt.Sprintf("keyword: '%s' in region: %s", keyword, regionID)
+	return a.paginate(ctx, query, label, false, 1, onPage)
+}
+
+// paginate walks the items endpoint for the given query from startPage until the results run out
+func (a *API) paginate(ctx context.Context, query, label string, fullInfo bool, startPage int, onPage domain.PageHandler) ([]domain.BusinessDetail, error) {
+	var allBusinesses []domain.BusinessDetail
+	if startPage < 1 {
+		startPage = 1
+	}
+	page := startPage
+	pageSize := businessesPageSize
+
+	for {
+		url, err := a.itemsURL(query, fullInfo, page, pageSize)
+		if err != nil {
+			return nil, err
 		}
 
-		// Use BusinessByIdResponse since the structure is the same for items endpoint
-		var businessResponse domain.BusinessByIdResponse
-		if err := json.NewDecoder(resp.Body).Decode(&businessResponse); err != nil {
-			a.logger.Error("Failed to decode businesses API response (page %d): %v", page, err)
-			// If it's the first page, return the error, otherwise return what we have
-			if page == 1 {
-				return nil, fmt.Errorf("failed to decode API response: %w", err)
+		businessResponse, err := a.fetchBusinessesPage(ctx, url)
+		if err != nil {
+			a.logger.Error("Failed to fetch businesses (page %d): %v", page, err)
+			// If it's the first page, return the error, otherwise report what we have as incomplete
+			if page == startPage {
+				return nil, err
 			}
-			a.logger.Info("Error decoding page %d, returning %d businesses collected so far", page, len(allBusinesses))
-			break
+			a.logger.Error("Error on page %d, returning %d businesses collected so far as partial result", page, len(allBusinesses))
+			return allBusinesses, &domain.PartialResultError{Page: page, Collected: len(allBusinesses), Err: err}
 		}
 
 		pageBusinesses := businessResponse.Result.Items
-		a.logger.Info("Successfully fetched %d businesses on page %d for rubric ID: %s in region: %s", len(pageBusinesses), page, categoryID, regionID)
+		a.logger.Info("Successfully fetched %d businesses on page %d for %s", len(pageBusinesses), page, label)
 
 		// If no businesses returned, we've reached the end
 		if len(pageBusinesses) == 0 {
@@ -98,7 +112,11 @@ func (a *API) FetchBusinesses(categoryID string, regionID string, fullInfo bool)
 
 		// Add businesses from this page to our collection
 		allBusinesses = append(allBusinesses, pageBusinesses...)
-		time.Sleep(100 * time.Millisecond) // Respectful delay between requests
+		if onPage != nil {
+			if err := onPage(page, pageBusinesses); err != nil {
+				return allBusinesses, fmt.Errorf("failed to handle page %d: %w", page, err)
+			}
+		}
 		// If we got fewer businesses than the page size, we've reached the end
 		if len(pageBusinesses) < pageSize {
 			a.logger.Info("Received %d businesses (less than page size %d), reached end of results", len(pageBusinesses), pageSize)
@@ -108,28 +126,78 @@ func (a *API) FetchBusinesses(categoryID string, regionID string, fullInfo bool)
 		page++
 	}
 
-	a.logger.Info("Completed pagination for rubric ID: %s in region: %s. Total businesses collected: %d", categoryID, regionID, len(allBusinesses))
+	a.logger.Info("Completed pagination for %s. Total businesses collected: %d", label, len(allBusinesses))
 	return allBusinesses, nil
 }
 
+// itemsURL builds an items endpoint URL for the query with the requested page
+func (a *API) itemsURL(query string, fullInfo bool, page, pageSize int) (string, error) {
+	url := fmt.Sprintf("https://catalog.api.2gis.com/3.0/items?%s&key=%s", query, a.apiKey)
+
+	// Add fields if fullInfo is true
+	if fullInfo {
+		// Load fields from CSV file
+		fields, err := helper.LoadFieldsFromCSV("docks/fields.csv")
+		if err != nil {
+			a.logger.Error("Failed to load fields from CSV: %v", err)
+			return "", fmt.Errorf("failed to load fields from CSV: %w", err)
+		}
+
+		if len(fields) > 0 {
+			url += "&fields=" + strings.Join(fields, ",")
+		}
+	} else {
+		url += "&fields=" + lightweightFields
+	}
+
+	// Add pagination parameters
+	url += fmt.Sprintf("&page=%d&page_size=%d", page, pageSize)
+	return url, nil
+}
+
+// fetchBusinessesPage requests and decodes a single page of the items endpoint
+func (a *API) fetchBusinessesPage(ctx context.Context, url string) (domain.BusinessByIdResponse, error) {
+	var businessResponse domain.BusinessByIdResponse
+
+	resp, err := a.get(ctx, url)
+	if err != nil {
+		return businessResponse, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return businessResponse, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+	}
+
+	// Use BusinessByIdResponse since the structure is the same for items endpoint
+	if err := json.NewDecoder(resp.Body).Decode(&businessResponse); err != nil {
+		return businessResponse, fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	return businessResponse, nil
+}
+
 // FetchBusinessesByRubrics fetches businesses by rubric IDs (original implementation)
 func (a *API) FetchBusinessesByRubrics(rubricIDs []string, regionID string) ([]domain.BusinessDetail, error) {
 	return nil, nil
 }
 
-func (a *API) FetchBusinessDetail(id string) (domain.BusinessDetail, error) {
+func (a *API) FetchBusinessDetail(ctx context.Context, id string) (domain.BusinessDetail, error) {
 	// Construct the API URL with all required fields
-	url := fmt.Sprintf("https://catalog.api.2gis.com/3.0/items/byid?key=%s&id=%s&fields=items.point,items.full_address_name,items.rubrics,items.schedule,items.description,items.flags,items.reviews,items.statistics,items.dates.updated_at,items.caption,items.stat,items.schedule_special,items.attribute_groups,items.reg_bc_url,items.address,items.links,items.summary", a.apiKey, id)
+	url := fmt.Sprintf("https://catalog.api.2gis.com/3.0/items/byid?key=%s&id=%s&fields=%s", a.apiKey, id, detailFields)
 
 	a.logger.Info("Fetching business detail from 2GIS API: %s", url)
 
-	resp, err := a.client.Get(url)
+	resp, err := a.get(ctx, url)
 	if err != nil {
 		a.logger.Error("Failed to make API request for business detail: %v", err)
-		return domain.BusinessDetail{}, fmt.Errorf("failed to make API request: %w", err)
+		return domain.BusinessDetail{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.BusinessDetail{}, fmt.Errorf("%w: %s", domain.ErrBusinessNotFound, id)
+	}
 	if resp.StatusCode != http.StatusOK {
 		a.logger.Error("API request failed with status code: %d", resp.StatusCode)
 		return domain.BusinessDetail{}, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
@@ -143,7 +211,7 @@ func (a *API) FetchBusinessDetail(id string) (domain.BusinessDetail, error) {
 
 	if len(businessResponse.Result.Items) == 0 {
 		a.logger.Error("No business found with ID: %s", id)
-		return domain.BusinessDetail{}, fmt.Errorf("no business found with ID: %s", id)
+		return domain.BusinessDetail{}, fmt.Errorf("%w: %s", domain.ErrBusinessNotFound, id)
 	}
 
 	businessDetail := businessResponse.Result.Items[0]
@@ -152,16 +220,42 @@ func (a *API) FetchBusinessDetail(id string) (domain.BusinessDetail, error) {
 	return businessDetail, nil
 }
 
+// FetchBusinessDetails fetches full details of several businesses, requesting them in batches.
+// Businesses the API no longer returns are missing from the result.
+func (a *API) FetchBusinessDetails(ctx context.Context, ids []string) ([]domain.BusinessDetail, error) {
+	var businesses []domain.BusinessDetail
+
+	for start := 0; start < len(ids); start += detailBatchSize {
+		end := start + detailBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		url := fmt.Sprintf("https://catalog.api.2gis.com/3.0/items/byid?key=%s&id=%s&fields=%s",
+			a.apiKey, strings.Join(ids[start:end], ","), detailFields)
+
+		businessResponse, err := a.fetchBusinessesPage(ctx, url)
+		if err != nil {
+			a.logger.Error("Failed to fetch details of %d businesses: %v", end-start, err)
+			return businesses, err
+		}
+		businesses = append(businesses, businessResponse.Result.Items...)
+	}
+
+	a.logger.Info("Fetched full details of %d of %d businesses", len(businesses), len(ids))
+	return businesses, nil
+}
+
 // FetchRegions fetches all available regions from 2GIS API for Kazakhstan
-func (a *API) FetchRegions() ([]domain.Region, error) {
+func (a *API) FetchRegions(ctx context.Context) ([]domain.Region, error) {
 	url := fmt.Sprintf("https://catalog.api.2gis.com/2.0/region/list?key=%s&locale=ru_RU&country_code_filter=kz", a.apiKey)
 
 	a.logger.Info("Fetching regions from 2GIS API: %s", url)
 
-	resp, err := a.client.Get(url)
+	resp, err := a.get(ctx, url)
 	if err != nil {
 		a.logger.Error("Failed to make API request: %v", err)
-		return nil, fmt.Errorf("failed to make API request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -0,0 +1,243 @@
+package twogis
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// replayRequestsPerSecond effectively disables rate limiting when serving fixtures
+const replayRequestsPerSecond = 1000
+
+// ErrFixtureNotFound is returned in replay mode for requests without a recorded response.
+// It is not a 404 of the API, so nothing is concluded about the business it asked for.
+var ErrFixtureNotFound = errors.New("no recorded 2GIS response")
+
+// fixture is a recorded API response as stored on disk
+type fixture struct {
+	URL    string          `json:"url"` // request URL without the API key
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// FixtureStore reads and writes recorded 2GIS responses in a directory.
+// Responses are keyed by request URL with the API key removed, so recordings
+// contain no credentials and can be replayed with any key.
+type FixtureStore struct {
+	dir string
+}
+
+// NewFixtureStore creates a fixture store for the given directory
+func NewFixtureStore(dir string) *FixtureStore {
+	return &FixtureStore{dir: dir}
+}
+
+// NewRecordingAPI creates an API client that saves every raw response to the fixtures directory
+func NewRecordingAPI(client *http.Client, apiKey string, logger Logger, limiter *RateLimiter, dir string) (*API, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	recordingClient := *client
+	recordingClient.Transport = &recordingTransport{base: base, fixtures: NewFixtureStore(dir), logger: logger}
+
+	return NewAPIWithLimiter(&recordingClient, apiKey, logger, limiter), nil
+}
+
+// NewReplayAPI creates an API client that serves responses from the fixtures directory
+// without touching the network. Requests without a recording fail with ErrFixtureNotFound.
+func NewReplayAPI(dir string, logger Logger) (*API, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixtures directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixtures path %s is not a directory", dir)
+	}
+
+	client := &http.Client{Transport: &replayTransport{fixtures: NewFixtureStore(dir), logger: logger}}
+	limiter := NewRateLimiter(replayRequestsPerSecond, replayRequestsPerSecond)
+	return NewAPIWithLimiter(client, "replay", logger, limiter), nil
+}
+
+// Save stores a response for the given request URL
+func (fs *FixtureStore) Save(requestURL string, status int, body []byte) error {
+	canonical, err := canonicalFixtureURL(requestURL)
+	if err != nil {
+		return err
+	}
+
+	// Keep JSON bodies readable, anything else is stored as a JSON string
+	rawBody := json.RawMessage(body)
+	if !json.Valid(body) {
+		encoded, err := json.Marshal(string(body))
+		if err != nil {
+			return fmt.Errorf("failed to encode fixture body: %w", err)
+		}
+		rawBody = encoded
+	}
+
+	data, err := json.MarshalIndent(fixture{URL: canonical, Status: status, Body: rawBody}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	path, err := fs.path(requestURL)
+	if err != nil {
+		return err
+	}
+
+	// Write through a temporary file so concurrent readers never see partial fixtures
+	tmp, err := os.CreateTemp(fs.dir, ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("failed to create fixture file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save fixture file: %w", err)
+	}
+	return nil
+}
+
+// Load returns the recorded status and body for the given request URL.
+// The boolean is false when nothing was recorded for the URL.
+func (fs *FixtureStore) Load(requestURL string) (int, []byte, bool, error) {
+	path, err := fs.path(requestURL)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, false, nil
+		}
+		return 0, nil, false, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	var recorded fixture
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return 0, nil, false, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+
+	body := []byte(recorded.Body)
+	if len(body) > 0 && body[0] == '"' {
+		var text string
+		if err := json.Unmarshal(body, &text); err == nil {
+			body = []byte(text)
+		}
+	}
+	return recorded.Status, body, true, nil
+}
+
+// path returns the fixture file for a request URL, named after the endpoint and a hash of the query
+func (fs *FixtureStore) path(requestURL string) (string, error) {
+	canonical, err := canonicalFixtureURL(requestURL)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := url.Parse(canonical)
+	if err != nil {
+		return "", fmt.Errorf("invalid fixture URL: %w", err)
+	}
+
+	endpoint := strings.ReplaceAll(strings.Trim(parsed.Path, "/"), "/", "_")
+	if endpoint == "" {
+		endpoint = "root"
+	}
+	sum := sha1.Sum([]byte(canonical))
+	return filepath.Join(fs.dir, fmt.Sprintf("%s_%s.json", endpoint, hex.EncodeToString(sum[:8]))), nil
+}
+
+// canonicalFixtureURL removes the API key and sorts query parameters
+func canonicalFixtureURL(requestURL string) (string, error) {
+	parsed, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid request URL: %w", err)
+	}
+	query := parsed.Query()
+	query.Del("key")
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// recordingTransport passes requests through and saves every response body
+type recordingTransport struct {
+	base     http.RoundTripper
+	fixtures *FixtureStore
+	logger   Logger
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.fixtures.Save(req.URL.String(), resp.StatusCode, body); err != nil {
+		t.logger.Error("Failed to record 2GIS response: %v", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from recorded fixtures
+type replayTransport struct {
+	fixtures *FixtureStore
+	logger   Logger
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body, ok, err := t.fixtures.Load(req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		canonical, err := canonicalFixtureURL(req.URL.String())
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w for %s", ErrFixtureNotFound, canonical)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package twogis
+
+import (
+	"2gis-parser/internal/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Info(msg string, args ...interface{})  {}
+func (discardLogger) Error(msg string, args ...interface{}) {}
+
+func TestFixtureStoreIgnoresKeyAndQueryOrder(t *testing.T) {
+	fixtures := NewFixtureStore(t.TempDir())
+	recorded := "https://catalog.api.2gis.com/3.0/items?rubric_id=269&region_id=67&key=secret&page=1"
+	if err := fixtures.Save(recorded, 200, []byte(`{"result":{"items":[]}}`)); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	status, body, ok, err := fixtures.Load("https://catalog.api.2gis.com/3.0/items?page=1&key=other&region_id=67&rubric_id=269")
+	if err != nil || !ok || status != 200 {
+		t.Fatalf("Load() = %d, %v, %v, want the recorded response", status, ok, err)
+	}
+	// JSON bodies are stored indented
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil || compact.String() != `{"result":{"items":[]}}` {
+		t.Fatalf("Load() body = %s, want the recorded body", body)
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	api, err := NewReplayAPI(t.TempDir(), discardLogger{})
+	if err != nil {
+		t.Fatalf("NewReplayAPI() = %v", err)
+	}
+
+	_, err = api.FetchBusinessDetail(context.Background(), "70000001023456789")
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Fatalf("FetchBusinessDetail() = %v, want ErrFixtureNotFound", err)
+	}
+	if errors.Is(err, domain.ErrBusinessNotFound) {
+		t.Fatalf("FetchBusinessDetail() = %v, a missing fixture must not report the business as gone", err)
+	}
+	if got := api.RequestCount(); got != 1 {
+		t.Fatalf("RequestCount() = %d, want 1, missing fixtures are not retried", got)
+	}
+}
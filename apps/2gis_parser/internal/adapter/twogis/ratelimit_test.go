@@ -0,0 +1,111 @@
+package twogis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(rate float64, burst int) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(rate, burst)
+	limiter.now = clock.now
+	limiter.last = clock.t
+	return limiter, clock
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	type step struct {
+		advance time.Duration // clock moves forward before the reservation
+		pause   time.Duration // PauseFor before the reservation
+		want    time.Duration
+	}
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{
+			name: "burst is free, then requests queue behind each other",
+			rate: 2, burst: 3,
+			steps: []step{{want: 0}, {want: 0}, {want: 0}, {want: 500 * time.Millisecond}, {want: time.Second}},
+		},
+		{
+			name: "tokens refill with time",
+			rate: 1, burst: 2,
+			steps: []step{{want: 0}, {want: 0}, {advance: time.Second, want: 0}, {want: time.Second}},
+		},
+		{
+			name: "refill is capped at the burst",
+			rate: 10, burst: 2,
+			steps: []step{{want: 0}, {want: 0}, {advance: 10 * time.Second, want: 0}, {want: 0}, {want: 100 * time.Millisecond}},
+		},
+		{
+			name: "Retry-After pause blocks even with tokens left",
+			rate: 1, burst: 5,
+			steps: []step{{pause: 5 * time.Second, want: 5 * time.Second}, {advance: 5 * time.Second, want: 0}},
+		},
+		{
+			name: "queue delay longer than the pause wins",
+			rate: 1, burst: 1,
+			steps: []step{{want: 0}, {pause: 200 * time.Millisecond, want: time.Second}},
+		},
+		{
+			name: "invalid rate and burst fall back to one request per second",
+			rate: 0, burst: 0,
+			steps: []step{{want: 0}, {want: time.Second}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, clock := newTestLimiter(tt.rate, tt.burst)
+			for i, step := range tt.steps {
+				clock.t = clock.t.Add(step.advance)
+				if step.pause > 0 {
+					limiter.PauseFor(step.pause)
+				}
+				if got := limiter.reserve(); absDuration(got-step.want) > time.Microsecond {
+					t.Fatalf("step %d: reserve() = %v, want %v", i, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterPauseKeepsLongest(t *testing.T) {
+	limiter, _ := newTestLimiter(100, 10)
+	limiter.PauseFor(10 * time.Second)
+	limiter.PauseFor(time.Second)
+
+	if got := limiter.reserve(); got != 10*time.Second {
+		t.Fatalf("reserve() = %v, want the longer pause of 10s", got)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter, _ := newTestLimiter(1, 1)
+	if waited, err := limiter.Wait(context.Background()); waited != 0 || err != nil {
+		t.Fatalf("Wait() with a token left = %v, %v, want 0, nil", waited, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.PauseFor(time.Hour)
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,124 @@
+package twogis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mytravel/pkg/metrics"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsSource is the source label of 2GIS request metrics
+const metricsSource = "2gis"
+
+const (
+	maxRetries     = 4
+	baseRetryDelay = 500 * time.Millisecond
+	maxRetryDelay  = 30 * time.Second
+)
+
+// get sends a GET request through the shared rate limiter, retrying network errors,
+// 429 and 5xx responses with exponential backoff. Other responses are returned as is,
+// a missing fixture in replay mode fails right away.
+func (a *API) get(ctx context.Context, url string) (*http.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryDelay(attempt)
+			a.logger.Info("Retrying 2GIS request in %v (attempt %d/%d): %v", delay, attempt, maxRetries, lastErr)
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		wait, err := a.limiter.Wait(ctx)
+		metrics.ObserveRateLimitWait(metricsSource, wait)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create API request: %w", err)
+		}
+		endpoint := strings.Trim(req.URL.Path, "/")
+
+		a.requests.Add(1)
+		requestStart := time.Now()
+		resp, err := a.client.Do(req)
+		if err != nil {
+			metrics.ObserveRequest(metricsSource, endpoint, 0, time.Since(requestStart))
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if errors.Is(err, ErrFixtureNotFound) {
+				return nil, err
+			}
+			lastErr = fmt.Errorf("failed to make API request: %w", err)
+			continue
+		}
+		metrics.ObserveRequest(metricsSource, endpoint, resp.StatusCode, time.Since(requestStart))
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			lastErr = fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+			if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				a.logger.Info("2GIS asked to retry after %v", wait)
+				a.limiter.PauseFor(wait)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// sleepContext sleeps for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryDelay returns the exponential backoff for an attempt with jitter applied
+func retryDelay(attempt int) time.Duration {
+	backoff := baseRetryDelay << (attempt - 1)
+	if backoff > maxRetryDelay {
+		backoff = maxRetryDelay
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,57 @@
+package twogis
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "missing", value: "", wantOK: false},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "seconds with spaces", value: " 7 ", want: 7 * time.Second, wantOK: true},
+		{name: "zero seconds", value: "0", want: 0, wantOK: true},
+		{name: "negative seconds", value: "-1", wantOK: false},
+		{name: "garbage", value: "soon", wantOK: false},
+		{name: "date in the past", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfterFutureDate(t *testing.T) {
+	value := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+
+	got, ok := parseRetryAfter(value)
+	// HTTP dates have a resolution of one second
+	if !ok || got < 88*time.Second || got > 90*time.Second {
+		t.Fatalf("parseRetryAfter(%q) = %v, %v, want about 90s", value, got, ok)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		backoff := baseRetryDelay << (attempt - 1)
+		if backoff > maxRetryDelay {
+			backoff = maxRetryDelay
+		}
+		for i := 0; i < 50; i++ {
+			if got := retryDelay(attempt); got < backoff/2 || got > backoff {
+				t.Fatalf("retryDelay(%d) = %v, want between %v and %v", attempt, got, backoff/2, backoff)
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package twogis
 import (
 	"2gis-parser/internal/domain"
 	"2gis-parser/internal/helper"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -10,7 +11,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // RubricCollector handles rubric collection and management
@@ -28,7 +28,7 @@ func NewRubricCollector(api *API, logger Logger) *RubricCollector {
 }
 
 // SearchBusinesses searches for businesses by keyword in a specific region
-func (rc *RubricCollector) SearchBusinesses(keyword, regionID string, pageSize int) (*domain.BusinessSearchResponse, error) {
+func (rc *RubricCollector) SearchBusinesses(ctx context.Context, keyword, regionID string, pageSize int) (*domain.BusinessSearchResponse, error) {
 	// URL encode the keyword
 	encodedKeyword := url.QueryEscape(keyword)
 
@@ -38,7 +38,7 @@ func (rc *RubricCollector) SearchBusinesses(keyword, regionID string, pageSize i
 
 	rc.logger.Info("Searching businesses with keyword '%s' in region %s", keyword, regionID)
 
-	resp, err := rc.api.client.Get(apiURL)
+	resp, err := rc.api.get(ctx, apiURL)
 	if err != nil {
 		rc.logger.Error("Failed to make search API request: %v", err)
 		return nil, fmt.Errorf("failed to make search API request: %w", err)
@@ -63,7 +63,7 @@ func (rc *RubricCollector) SearchBusinesses(keyword, regionID string, pageSize i
 }
 
 // CollectRubricsFromKeywords searches for businesses using multiple keywords and collects all unique rubrics
-func (rc *RubricCollector) CollectRubricsFromKeywords(keywords []string, regionID string) ([]domain.RubricData, error) {
+func (rc *RubricCollector) CollectRubricsFromKeywords(ctx context.Context, keywords []string, regionID string) ([]domain.RubricData, error) {
 	rubricMap := make(map[string]*domain.RubricData)
 
 	rc.logger.Info("Starting rubric collection for %d keywords in region %s", len(keywords), regionID)
@@ -72,14 +72,18 @@ func (rc *RubricCollector) CollectRubricsFromKeywords(keywords []string, regionI
 		rc.logger.Info("Processing keyword %d/%d: %s", i+1, len(keywords), keyword)
 
 		// Search with current keyword
-		searchResp, err := rc.SearchBusinesses(keyword, regionID, 50)
+		searchResp, err := rc.SearchBusinesses(ctx, keyword, regionID, 50)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			rc.logger.Error("Failed to search businesses for keyword '%s': %v", keyword, err)
 			continue // Continue with next keyword instead of failing completely
 		}
 
 		// Process rubrics from search results
 		businessCount := 0
+		keywordRubrics := make(map[string]bool)
 		for _, business := range searchResp.Result.Items {
 			businessCount++
 			for _, rubric := range business.Rubrics {
@@ -97,13 +101,17 @@ func (rc *RubricCollector) CollectRubricsFromKeywords(keywords []string, regionI
 						Count:    1,
 					}
 				}
+
+				// Count every keyword once per rubric, however many businesses it returned
+				if !keywordRubrics[key] {
+					keywordRubrics[key] = true
+					rubricMap[key].KeywordHits++
+					rubricMap[key].Keywords = append(rubricMap[key].Keywords, keyword)
+				}
 			}
 		}
 
 		rc.logger.Info("Processed %d businesses for keyword '%s'", businessCount, keyword)
-
-		// Add delay to avoid rate limiting
-		time.Sleep(200 * time.Millisecond)
 	}
 
 	// Convert map to slice
@@ -128,7 +136,7 @@ func (rc *RubricCollector) SaveRubricsToCSV(rubrics []domain.RubricData, filenam
 	defer writer.Flush()
 
 	// Write header
-	header := []string{"ID", "Alias", "Name", "Kind", "ParentID", "ShortID", "Count"}
+	header := []string{"ID", "Alias", "Name", "Kind", "ParentID", "ShortID", "Count", "KeywordHits"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
@@ -143,6 +151,7 @@ func (rc *RubricCollector) SaveRubricsToCSV(rubrics []domain.RubricData, filenam
 			rubric.ParentID,
 			strconv.Itoa(rubric.ShortID),
 			strconv.Itoa(rubric.Count),
+			strconv.Itoa(rubric.KeywordHits),
 		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("failed to write CSV record: %w", err)
@@ -0,0 +1,359 @@
+package twogis
+
+import (
+	"2gis-parser/internal/domain"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxParentDepth limits how far up the hierarchy parent groups are resolved
+const maxParentDepth = 3
+
+// DefaultMinKeywordHits is how many tourism keywords must return a rubric before it is suggested
+const DefaultMinKeywordHits = 2
+
+// suggestedRubricsHeader is the header of the suggested rubrics CSV, compatible with filtered_rubricks.csv
+var suggestedRubricsHeader = []string{"ID", "Alias", "Name", "Kind", "ParentID", "ShortID", "Count", "KeywordHits", "Score", "Explanation"}
+
+// rubricResponse represents the response of the 2GIS rubric API
+type rubricResponse struct {
+	Meta struct {
+		Code int `json:"code"`
+	} `json:"meta"`
+	Result struct {
+		Items []struct {
+			ID       string `json:"id"`
+			Alias    string `json:"alias"`
+			Name     string `json:"name"`
+			Type     string `json:"type"`
+			ParentID string `json:"parent_id"`
+		} `json:"items"`
+	} `json:"result"`
+}
+
+// FetchRubric fetches a rubric or rubric group by ID
+func (a *API) FetchRubric(ctx context.Context, id string) (domain.Rubric, error) {
+	url := fmt.Sprintf("https://catalog.api.2gis.com/2.0/catalog/rubric/get?key=%s&id=%s", a.apiKey, id)
+
+	resp, err := a.get(ctx, url)
+	if err != nil {
+		return domain.Rubric{}, fmt.Errorf("failed to make rubric API request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return domain.Rubric{}, fmt.Errorf("rubric API request failed with status code: %d", resp.StatusCode)
+	}
+
+	var rubricResp rubricResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rubricResp); err != nil {
+		return domain.Rubric{}, fmt.Errorf("failed to decode rubric API response: %w", err)
+	}
+	if len(rubricResp.Result.Items) == 0 {
+		return domain.Rubric{}, fmt.Errorf("rubric %s not found", id)
+	}
+
+	item := rubricResp.Result.Items[0]
+	return domain.Rubric{ID: item.ID, Alias: item.Alias, Name: item.Name, Kind: item.Type, ParentID: item.ParentID}, nil
+}
+
+// BuildRubricTree arranges collected rubrics under their parent groups and scores them by
+// the share of tourism keywords that returned them. Parent groups are looked up in the API;
+// groups that cannot be resolved are kept with their ID only.
+func (rc *RubricCollector) BuildRubricTree(ctx context.Context, rubrics []domain.RubricData, keywordsTotal int, regionID string) (domain.RubricTree, error) {
+	nodes := make(map[string]*domain.RubricNode, len(rubrics))
+	for _, rubric := range rubrics {
+		nodes[rubric.ID] = &domain.RubricNode{
+			ID:          rubric.ID,
+			Alias:       rubric.Alias,
+			Name:        rubric.Name,
+			Kind:        rubric.Kind,
+			ParentID:    rubric.ParentID,
+			Count:       rubric.Count,
+			KeywordHits: rubric.KeywordHits,
+			Score:       rubricScore(rubric.KeywordHits, keywordsTotal),
+			Keywords:    rubric.Keywords,
+		}
+	}
+
+	// Resolve parent groups that were not returned as rubrics themselves
+	pending := make([]string, 0)
+	for _, node := range nodes {
+		if node.ParentID != "" {
+			pending = append(pending, node.ParentID)
+		}
+	}
+	for depth := 0; depth < maxParentDepth && len(pending) > 0; depth++ {
+		var next []string
+		for _, parentID := range pending {
+			if _, exists := nodes[parentID]; exists {
+				continue
+			}
+			parent := &domain.RubricNode{ID: parentID, Kind: "group"}
+			group, err := rc.api.FetchRubric(ctx, parentID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return domain.RubricTree{}, ctx.Err()
+				}
+				rc.logger.Error("Failed to fetch rubric group %s: %v", parentID, err)
+			} else {
+				parent.Alias = group.Alias
+				parent.Name = group.Name
+				parent.ParentID = group.ParentID
+				if group.ParentID != "" {
+					next = append(next, group.ParentID)
+				}
+			}
+			nodes[parentID] = parent
+		}
+		pending = next
+	}
+
+	// Link children to parents, everything without a known parent becomes a root
+	var roots []*domain.RubricNode
+	for _, node := range nodes {
+		if parent, ok := nodes[node.ParentID]; ok && node.ParentID != node.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	// Groups inherit the activity of their children so the tree can be sorted top down
+	for _, root := range roots {
+		aggregateRubricNode(root, keywordsTotal)
+	}
+	sortRubricNodes(roots)
+
+	return domain.RubricTree{
+		RegionID:      regionID,
+		KeywordsTotal: keywordsTotal,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Roots:         roots,
+	}, nil
+}
+
+// SuggestRubrics picks primary rubrics returned by at least minKeywordHits tourism keywords
+func (rc *RubricCollector) SuggestRubrics(rubrics []domain.RubricData, keywordsTotal, minKeywordHits int) []domain.RubricSuggestion {
+	if minKeywordHits < 1 {
+		minKeywordHits = 1
+	}
+
+	var suggestions []domain.RubricSuggestion
+	for _, rubric := range rubrics {
+		if rubric.Kind != "primary" {
+			continue
+		}
+		if rubric.KeywordHits < minKeywordHits {
+			continue
+		}
+
+		suggestions = append(suggestions, domain.RubricSuggestion{
+			Rubric: rubric,
+			Score:  rubricScore(rubric.KeywordHits, keywordsTotal),
+			Explanation: fmt.Sprintf("returned by %d of %d tourism keywords (%s); seen on %d businesses",
+				rubric.KeywordHits, keywordsTotal, strings.Join(rubric.Keywords, ", "), rubric.Count),
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Rubric.Count > suggestions[j].Rubric.Count
+	})
+	return suggestions
+}
+
+// SaveRubricTreeJSON exports the rubric tree as indented JSON
+func (rc *RubricCollector) SaveRubricTreeJSON(tree domain.RubricTree, filename string) error {
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rubric tree: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write rubric tree: %w", err)
+	}
+
+	rc.logger.Info("Successfully saved rubric tree to %s", filename)
+	return nil
+}
+
+// SaveSuggestedRubricsCSV writes suggestions in the filtered rubrics format read by Parser.Run
+func (rc *RubricCollector) SaveSuggestedRubricsCSV(suggestions []domain.RubricSuggestion, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(suggestedRubricsHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, suggestion := range suggestions {
+		rubric := suggestion.Rubric
+		record := []string{
+			rubric.ID,
+			rubric.Alias,
+			rubric.Name,
+			rubric.Kind,
+			rubric.ParentID,
+			strconv.Itoa(rubric.ShortID),
+			strconv.Itoa(rubric.Count),
+			strconv.Itoa(rubric.KeywordHits),
+			strconv.FormatFloat(suggestion.Score, 'f', 3, 64),
+			suggestion.Explanation,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	rc.logger.Info("Successfully saved %d suggested rubrics to %s", len(suggestions), filename)
+	return nil
+}
+
+// LoadRubricsFromCSV loads rubrics from a rubrics CSV file, columns are matched by header name
+func (rc *RubricCollector) LoadRubricsFromCSV(filename string) ([]domain.RubricData, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rubrics file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rubrics CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[strings.TrimSpace(column)] = i
+	}
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rubrics []domain.RubricData
+	for _, record := range records[1:] {
+		id := value(record, "ID")
+		if id == "" {
+			continue
+		}
+		shortID, _ := strconv.Atoi(value(record, "ShortID"))
+		count, _ := strconv.Atoi(value(record, "Count"))
+		keywordHits, _ := strconv.Atoi(value(record, "KeywordHits"))
+		rubrics = append(rubrics, domain.RubricData{
+			ID:          id,
+			Alias:       value(record, "Alias"),
+			Name:        value(record, "Name"),
+			Kind:        value(record, "Kind"),
+			ParentID:    value(record, "ParentID"),
+			ShortID:     shortID,
+			Count:       count,
+			KeywordHits: keywordHits,
+		})
+	}
+	return rubrics, nil
+}
+
+// DiffRubrics compares a previous rubric list with a new one by rubric ID
+func (rc *RubricCollector) DiffRubrics(previous, current []domain.RubricData) domain.RubricDiff {
+	previousIDs := make(map[string]bool, len(previous))
+	for _, rubric := range previous {
+		previousIDs[rubric.ID] = true
+	}
+	currentIDs := make(map[string]bool, len(current))
+	for _, rubric := range current {
+		currentIDs[rubric.ID] = true
+	}
+
+	var diff domain.RubricDiff
+	for _, rubric := range current {
+		if !previousIDs[rubric.ID] {
+			diff.Added = append(diff.Added, rubric)
+		}
+	}
+	for _, rubric := range previous {
+		if !currentIDs[rubric.ID] {
+			diff.Removed = append(diff.Removed, rubric)
+		}
+	}
+	return diff
+}
+
+// rubricScore returns the share of keywords that returned a rubric, rounded to three decimals
+func rubricScore(keywordHits, keywordsTotal int) float64 {
+	if keywordsTotal <= 0 {
+		return 0
+	}
+	return math.Round(float64(keywordHits)/float64(keywordsTotal)*1000) / 1000
+}
+
+// aggregateRubricNode sums counts of groups from their children and returns the node keyword hits
+func aggregateRubricNode(node *domain.RubricNode, keywordsTotal int) {
+	if len(node.Children) == 0 {
+		return
+	}
+
+	keywords := make(map[string]bool)
+	for _, keyword := range node.Keywords {
+		keywords[keyword] = true
+	}
+	for _, child := range node.Children {
+		aggregateRubricNode(child, keywordsTotal)
+		if node.Kind == "group" {
+			node.Count += child.Count
+		}
+		for _, keyword := range child.Keywords {
+			keywords[keyword] = true
+		}
+	}
+
+	// A group is returned by every keyword that returned one of its rubrics
+	if node.Kind == "group" {
+		node.Keywords = node.Keywords[:0]
+		for keyword := range keywords {
+			node.Keywords = append(node.Keywords, keyword)
+		}
+		sort.Strings(node.Keywords)
+		node.KeywordHits = len(node.Keywords)
+		node.Score = rubricScore(node.KeywordHits, keywordsTotal)
+	}
+}
+
+// sortRubricNodes orders siblings by score and then by business count, recursively
+func sortRubricNodes(nodes []*domain.RubricNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Score != nodes[j].Score {
+			return nodes[i].Score > nodes[j].Score
+		}
+		if nodes[i].Count != nodes[j].Count {
+			return nodes[i].Count > nodes[j].Count
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	for _, node := range nodes {
+		sortRubricNodes(node.Children)
+	}
+}
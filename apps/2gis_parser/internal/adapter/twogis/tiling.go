@@ -0,0 +1,163 @@
+package twogis
+
+import (
+	"2gis-parser/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// tileGridSize is the number of rows and columns a region bounding box is split into first
+	tileGridSize = 4
+	// maxTileDepth limits how many times a dense cell is subdivided again
+	maxTileDepth = 6
+	// tileResultCap is the number of results after which 2GIS pagination stops returning items,
+	// cells reporting at least this many results are subdivided instead of paginated
+	tileResultCap = 500
+)
+
+// FetchBusinessesTiled fetches businesses of a rubric in a region by splitting the region
+// bounding box into a grid and querying every cell by polygon. Cells that hit the result cap
+// are subdivided again, and businesses found in several cells are reported only once.
+// onPage receives every page of new businesses with a running page counter.
+func (a *API) FetchBusinessesTiled(ctx context.Context, categoryID string, regionID string, fullInfo bool, onPage domain.PageHandler) ([]domain.BusinessDetail, error) {
+	bounds, err := a.FetchRegionBounds(ctx, regionID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tiledFetch{
+		api:        a,
+		categoryID: categoryID,
+		regionID:   regionID,
+		fullInfo:   fullInfo,
+		onPage:     onPage,
+		seen:       make(map[string]bool),
+	}
+
+	cells := bounds.Grid(tileGridSize, tileGridSize)
+	a.logger.Info("Tiling region %s for rubric %s into %d cells", regionID, categoryID, len(cells))
+
+	for _, cell := range cells {
+		if err := t.fetchCell(ctx, cell, 0); err != nil {
+			return t.businesses, err
+		}
+	}
+
+	a.logger.Info("Completed tiled search for rubric ID: %s in region: %s. %d cells queried, %d unique businesses, %d duplicates skipped",
+		categoryID, regionID, t.cells, len(t.businesses), t.duplicates)
+	return t.businesses, nil
+}
+
+// FetchRegionBounds returns the bounding box of a 2GIS region
+func (a *API) FetchRegionBounds(ctx context.Context, regionID string) (domain.BoundingBox, error) {
+	url := fmt.Sprintf("https://catalog.api.2gis.com/2.0/region/get?id=%s&fields=items.bounds&key=%s", regionID, a.apiKey)
+
+	resp, err := a.get(ctx, url)
+	if err != nil {
+		a.logger.Error("Failed to make API request for region bounds: %v", err)
+		return domain.BoundingBox{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		a.logger.Error("API request failed with status code: %d", resp.StatusCode)
+		return domain.BoundingBox{}, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+	}
+
+	var regionsResponse domain.RegionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&regionsResponse); err != nil {
+		return domain.BoundingBox{}, fmt.Errorf("failed to decode API response: %w", err)
+	}
+
+	if len(regionsResponse.Result.Items) == 0 || regionsResponse.Result.Items[0].Bounds == "" {
+		return domain.BoundingBox{}, fmt.Errorf("no bounds found for region %s", regionID)
+	}
+
+	return domain.ParseWKTBounds(regionsResponse.Result.Items[0].Bounds)
+}
+
+// tiledFetch holds the state of a single tiled search
+type tiledFetch struct {
+	api        *API
+	categoryID string
+	regionID   string
+	fullInfo   bool
+	onPage     domain.PageHandler
+
+	seen       map[string]bool
+	businesses []domain.BusinessDetail
+	pages      int
+	cells      int
+	duplicates int
+}
+
+// fetchCell queries a cell, subdividing it when it reports more results than can be paginated
+func (t *tiledFetch) fetchCell(ctx context.Context, cell domain.BoundingBox, depth int) error {
+	t.cells++
+
+	query := fmt.Sprintf("rubric_id=%s&region_id=%s&polygon=%s", t.categoryID, t.regionID, url.QueryEscape(cell.WKT()))
+	firstURL, err := t.api.itemsURL(query, t.fullInfo, 1, businessesPageSize)
+	if err != nil {
+		return err
+	}
+
+	firstPage, err := t.api.fetchBusinessesPage(ctx, firstURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tile %s: %w", cell.WKT(), err)
+	}
+
+	total := firstPage.Result.Total
+	if total >= tileResultCap && depth < maxTileDepth {
+		t.api.logger.Info("Tile at depth %d has %d results (cap %d), subdividing", depth, total, tileResultCap)
+		for _, child := range cell.Split() {
+			if err := t.fetchCell(ctx, child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if total >= tileResultCap {
+		t.api.logger.Error("Tile at maximum depth still has %d results, some businesses may be missed", total)
+	}
+
+	if err := t.handlePage(firstPage.Result.Items); err != nil {
+		return err
+	}
+	if len(firstPage.Result.Items) < businessesPageSize {
+		return nil
+	}
+
+	label := fmt.Sprintf("rubric ID: %s tile at depth %d", t.categoryID, depth)
+	_, err = t.api.paginate(ctx, query, label, t.fullInfo, 2, func(page int, businesses []domain.BusinessDetail) error {
+		return t.handlePage(businesses)
+	})
+	return err
+}
+
+// handlePage drops businesses already found in other cells and passes the rest on
+func (t *tiledFetch) handlePage(businesses []domain.BusinessDetail) error {
+	fresh := make([]domain.BusinessDetail, 0, len(businesses))
+	for _, business := range businesses {
+		if t.seen[business.ID] {
+			t.duplicates++
+			continue
+		}
+		t.seen[business.ID] = true
+		fresh = append(fresh, business)
+	}
+
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	t.businesses = append(t.businesses, fresh...)
+	t.pages++
+	if t.onPage != nil {
+		return t.onPage(t.pages, fresh)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package twogis
+
+import (
+	"2gis-parser/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// tileRegion is the region the tiling fixtures are recorded for, a 2x2 degree box split into
+// 0.5 degree cells
+var tileRegion = domain.BoundingBox{MinLon: 76, MinLat: 43, MaxLon: 78, MaxLat: 45}
+
+// tileFixtures records the responses a tiled search of rubric 269 in region 67 replays
+type tileFixtures struct {
+	t        *testing.T
+	dir      string
+	fixtures *FixtureStore
+	urls     *API // builds request URLs the same way the replayed client does
+}
+
+func newTileFixtures(t *testing.T) *tileFixtures {
+	t.Helper()
+	dir := t.TempDir()
+	f := &tileFixtures{t: t, dir: dir, fixtures: NewFixtureStore(dir), urls: NewAPI(http.DefaultClient, "replay", discardLogger{})}
+	f.save("https://catalog.api.2gis.com/2.0/region/get?id=67&fields=items.bounds&key=replay",
+		map[string]interface{}{"result": map[string]interface{}{"items": []map[string]string{{"id": "67", "bounds": tileRegion.WKT()}}}})
+	// Every cell of the first grid is empty unless a test records it again
+	for _, cell := range tileRegion.Grid(tileGridSize, tileGridSize) {
+		f.cell(cell, 0)
+	}
+	return f
+}
+
+// cell records the first page of a cell reporting total results and listing ids
+func (f *tileFixtures) cell(cell domain.BoundingBox, total int, ids ...string) {
+	f.t.Helper()
+	query := fmt.Sprintf("rubric_id=269&region_id=67&polygon=%s", url.QueryEscape(cell.WKT()))
+	requestURL, err := f.urls.itemsURL(query, false, 1, businessesPageSize)
+	if err != nil {
+		f.t.Fatalf("itemsURL() = %v", err)
+	}
+	items := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, map[string]string{"id": id, "name": "Business " + id})
+	}
+	f.save(requestURL, map[string]interface{}{"result": map[string]interface{}{"total": total, "items": items}})
+}
+
+func (f *tileFixtures) save(requestURL string, body interface{}) {
+	f.t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		f.t.Fatalf("encode fixture: %v", err)
+	}
+	if err := f.fixtures.Save(requestURL, http.StatusOK, data); err != nil {
+		f.t.Fatalf("Save() = %v", err)
+	}
+}
+
+// fetch replays a tiled search and returns the IDs of the businesses and of every page handed on
+func (f *tileFixtures) fetch() (*API, []string, [][]string) {
+	f.t.Helper()
+	api, err := NewReplayAPI(f.dir, discardLogger{})
+	if err != nil {
+		f.t.Fatalf("NewReplayAPI() = %v", err)
+	}
+
+	var pages [][]string
+	onPage := func(page int, businesses []domain.BusinessDetail) error {
+		if page != len(pages)+1 {
+			f.t.Fatalf("onPage() got page %d, want %d", page, len(pages)+1)
+		}
+		pages = append(pages, businessIDs(businesses))
+		return nil
+	}
+	businesses, err := api.FetchBusinessesTiled(context.Background(), "269", "67", false, onPage)
+	if err != nil {
+		f.t.Fatalf("FetchBusinessesTiled() = %v", err)
+	}
+	return api, businessIDs(businesses), pages
+}
+
+func businessIDs(businesses []domain.BusinessDetail) []string {
+	ids := make([]string, 0, len(businesses))
+	for _, business := range businesses {
+		ids = append(ids, business.ID)
+	}
+	return ids
+}
+
+func TestFetchBusinessesTiledSplitsCellsAtResultCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int // reported by the first cell, which also lists business "parent"
+		wantIDs   []string
+		wantCalls int64
+	}{
+		{name: "below the cap is paginated", total: tileResultCap - 1, wantIDs: []string{"parent"}, wantCalls: 1 + 16},
+		{name: "at the cap is subdivided", total: tileResultCap, wantIDs: []string{"a", "b"}, wantCalls: 1 + 16 + 4},
+		{name: "above the cap is subdivided", total: 2000, wantIDs: []string{"a", "b"}, wantCalls: 1 + 16 + 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTileFixtures(t)
+			dense := tileRegion.Grid(tileGridSize, tileGridSize)[0]
+			f.cell(dense, tt.total, "parent")
+			children := dense.Split()
+			f.cell(children[0], 1, "a")
+			f.cell(children[1], 1, "b")
+			f.cell(children[2], 0)
+			f.cell(children[3], 0)
+
+			api, ids, _ := f.fetch()
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Fatalf("FetchBusinessesTiled() = %v, want %v", ids, tt.wantIDs)
+			}
+			if got := api.RequestCount(); got != tt.wantCalls {
+				t.Fatalf("RequestCount() = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFetchBusinessesTiledStopsAtMaxDepth(t *testing.T) {
+	f := newTileFixtures(t)
+	// The first quadrant stays over the cap at every depth, the others are empty
+	dense := tileRegion.Grid(tileGridSize, tileGridSize)[0]
+	for depth := 0; depth < maxTileDepth; depth++ {
+		f.cell(dense, 900)
+		children := dense.Split()
+		for _, child := range children[1:] {
+			f.cell(child, 0)
+		}
+		dense = children[0]
+	}
+	f.cell(dense, 900, "deepest")
+
+	api, ids, _ := f.fetch()
+	if fmt.Sprint(ids) != "[deepest]" {
+		t.Fatalf("FetchBusinessesTiled() = %v, want the businesses of the cell at maximum depth", ids)
+	}
+	if got, want := api.RequestCount(), int64(1+16+4*maxTileDepth); got != want {
+		t.Fatalf("RequestCount() = %d, want %d, cells at maximum depth are not split again", got, want)
+	}
+}
+
+func TestFetchBusinessesTiledSkipsDuplicates(t *testing.T) {
+	f := newTileFixtures(t)
+	cells := tileRegion.Grid(tileGridSize, tileGridSize)
+	// Businesses on a cell border are returned by both cells
+	f.cell(cells[0], 2, "a", "b")
+	f.cell(cells[1], 2, "b", "c")
+	f.cell(cells[2], 1, "a")
+
+	_, ids, pages := f.fetch()
+	if fmt.Sprint(ids) != "[a b c]" {
+		t.Fatalf("FetchBusinessesTiled() = %v, want every business once", ids)
+	}
+	// A page without new businesses is not handed on
+	if fmt.Sprint(pages) != "[[a b] [c]]" {
+		t.Fatalf("pages = %v, want [[a b] [c]]", pages)
+	}
+}
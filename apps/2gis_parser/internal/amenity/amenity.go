@@ -0,0 +1,174 @@
+package amenity
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MappingsDirEnv overrides the embedded mapping files with <dir>/<source>.json
+const MappingsDirEnv = "AMENITY_MAPPINGS_DIR"
+
+//go:embed mappings/*.json
+var embeddedMappings embed.FS
+
+// keyPattern restricts amenity keys to the snake_case keys stored in the amenities column
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Mapping maps source attribute tags to canonical amenity keys.
+// A tag mapped to an empty key was reviewed and is not an amenity.
+// Names are lowercase substrings of attribute names, used only for tags
+// that have not been reviewed yet.
+type Mapping struct {
+	Source  string            `json:"source"`
+	Version int               `json:"version"`
+	Tags    map[string]string `json:"tags"`
+	Names   map[string]string `json:"names,omitempty"`
+	Review  []string          `json:"review,omitempty"` // tags added from the catalog that still need a decision
+
+	names  []string // name substrings sorted for a stable match order
+	review map[string]bool
+}
+
+// ForSource loads the mapping of a source from MappingsDirEnv if set, otherwise from the embedded files
+func ForSource(source string) (*Mapping, error) {
+	if dir := os.Getenv(MappingsDirEnv); dir != "" {
+		return Load(filepath.Join(dir, source+".json"))
+	}
+
+	data, err := embeddedMappings.ReadFile("mappings/" + source + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no amenity mapping for source %s", source)
+	}
+	return parse(data, source)
+}
+
+// Load reads a mapping file from disk
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read amenity mapping: %w", err)
+	}
+	return parse(data, path)
+}
+
+// parse decodes and validates a mapping, tags and names are matched case-insensitively
+func parse(data []byte, name string) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode amenity mapping %s: %w", name, err)
+	}
+
+	if mapping.Version < 1 {
+		return nil, fmt.Errorf("amenity mapping %s has no version", name)
+	}
+
+	tags := make(map[string]string, len(mapping.Tags))
+	for tag, key := range mapping.Tags {
+		if key != "" && !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("amenity mapping %s: tag %q maps to invalid key %q", name, tag, key)
+		}
+		tags[normalize(tag)] = key
+	}
+	mapping.Tags = tags
+
+	names := make(map[string]string, len(mapping.Names))
+	for substring, key := range mapping.Names {
+		if !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("amenity mapping %s: name %q maps to invalid key %q", name, substring, key)
+		}
+		names[normalize(substring)] = key
+		mapping.names = append(mapping.names, normalize(substring))
+	}
+	mapping.Names = names
+	sort.Strings(mapping.names)
+
+	mapping.review = make(map[string]bool, len(mapping.Review))
+	for _, tag := range mapping.Review {
+		mapping.review[normalize(tag)] = true
+	}
+
+	return &mapping, nil
+}
+
+// Map returns the amenity key of an attribute and whether its tag has been reviewed.
+// Unreviewed tags fall back to the attribute name rules, an empty key means no amenity.
+func (m *Mapping) Map(tag, name string) (string, bool) {
+	tag = normalize(tag)
+	if key, ok := m.Tags[tag]; ok && !m.review[tag] {
+		return key, true
+	}
+	return m.MatchName(name), false
+}
+
+// MatchName returns the amenity key suggested by the attribute name rules
+func (m *Mapping) MatchName(name string) string {
+	name = normalize(name)
+	if name == "" {
+		return ""
+	}
+	for _, substring := range m.names {
+		if strings.Contains(name, substring) {
+			return m.Names[substring]
+		}
+	}
+	return ""
+}
+
+// Draft returns a copy of the mapping extended with the given tags. Tags that are
+// not reviewed yet get the key suggested by the name rules and are listed for review.
+func (m *Mapping) Draft(tagNames map[string]string) *Mapping {
+	draft := &Mapping{
+		Source:  m.Source,
+		Version: m.Version,
+		Tags:    make(map[string]string, len(m.Tags)+len(tagNames)),
+		Names:   m.Names,
+	}
+	for tag, key := range m.Tags {
+		draft.Tags[tag] = key
+	}
+
+	review := make(map[string]bool)
+	for tag := range m.review {
+		review[tag] = true
+	}
+	for tag, name := range tagNames {
+		tag = normalize(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := draft.Tags[tag]; ok {
+			continue
+		}
+		draft.Tags[tag] = m.MatchName(name)
+		review[tag] = true
+		draft.Version = m.Version + 1 // the draft changes the mapping once it is reviewed
+	}
+
+	for tag := range review {
+		draft.Review = append(draft.Review, tag)
+	}
+	sort.Strings(draft.Review)
+	return draft
+}
+
+// Save writes the mapping as indented JSON
+func (m *Mapping) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode amenity mapping: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write amenity mapping: %w", err)
+	}
+	return nil
+}
+
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
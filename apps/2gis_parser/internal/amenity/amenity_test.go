@@ -0,0 +1,127 @@
+package amenity
+
+import "testing"
+
+const testMapping = `{
+  "source": "2gis",
+  "version": 2,
+  "tags": {
+    "Wi-Fi": "wifi",
+    "parking_free": "parking",
+    "card_payment": "",
+    "breakfast_buffet": "breakfast"
+  },
+  "names": {
+    "завтрак": "breakfast",
+    "кафе": "cafe",
+    "ресторан": "restaurant",
+    "парковка": "parking",
+    "бесплатная парковка": "free_parking"
+  },
+  "review": ["breakfast_buffet"]
+}`
+
+func TestMapResolutionOrder(t *testing.T) {
+	mapping, err := parse([]byte(testMapping), "test")
+	if err != nil {
+		t.Fatalf("parse() = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		tag          string
+		attrName     string
+		wantKey      string
+		wantReviewed bool
+	}{
+		{name: "reviewed tag", tag: "parking_free", attrName: "Бесплатная парковка", wantKey: "parking", wantReviewed: true},
+		{name: "tags are matched case-insensitively", tag: " WI-FI ", attrName: "Wi-Fi", wantKey: "wifi", wantReviewed: true},
+		{name: "tag reviewed as no amenity wins over a matching name", tag: "card_payment", attrName: "Ресторан", wantKey: "", wantReviewed: true},
+		{name: "tag under review falls back to the name", tag: "breakfast_buffet", attrName: "Шведский стол", wantKey: "", wantReviewed: false},
+		{name: "tag under review with a matching name", tag: "breakfast_buffet", attrName: "Завтрак включён", wantKey: "breakfast", wantReviewed: false},
+		{name: "unknown tag falls back to the name", tag: "cafe", attrName: "Летнее КАФЕ", wantKey: "cafe", wantReviewed: false},
+		{name: "unknown tag and name", tag: "sauna", attrName: "Сауна", wantKey: "", wantReviewed: false},
+		{name: "empty tag and name", wantKey: "", wantReviewed: false},
+		// Name substrings are tried in sorted order: "бесплатная парковка" is tried before
+		// "парковка" because it sorts first, not because it is longer
+		{name: "first sorted name substring wins", tag: "new_tag", attrName: "Бесплатная парковка", wantKey: "free_parking", wantReviewed: false},
+		{name: "first sorted name substring wins over a later one", tag: "new_tag", attrName: "Ресторан и кафе", wantKey: "cafe", wantReviewed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, reviewed := mapping.Map(tt.tag, tt.attrName)
+			if key != tt.wantKey || reviewed != tt.wantReviewed {
+				t.Fatalf("Map(%q, %q) = %q, %v, want %q, %v", tt.tag, tt.attrName, key, reviewed, tt.wantKey, tt.wantReviewed)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "missing version", data: `{"tags": {"wifi": "wifi"}}`},
+		{name: "tag with an invalid key", data: `{"version": 1, "tags": {"wifi": "Wi-Fi"}}`},
+		{name: "name with an empty key", data: `{"version": 1, "names": {"wifi": ""}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parse([]byte(tt.data), "test"); err == nil {
+				t.Fatalf("parse(%s) succeeded, want an error", tt.data)
+			}
+		})
+	}
+}
+
+func TestDraft(t *testing.T) {
+	mapping, err := parse([]byte(testMapping), "test")
+	if err != nil {
+		t.Fatalf("parse() = %v", err)
+	}
+
+	draft := mapping.Draft(map[string]string{
+		"wi-fi":      "Wi-Fi",              // reviewed already, kept as is
+		"Lunch_Cafe": "Кафе на территории", // new, suggested by the name rules
+		"sauna":      "Сауна",              // new, no suggestion
+		"":           "Без тега",
+	})
+
+	if draft.Version != 3 {
+		t.Fatalf("draft version = %d, want 3 because new tags were added", draft.Version)
+	}
+	wantTags := map[string]string{
+		"wi-fi": "wifi", "parking_free": "parking", "card_payment": "", "breakfast_buffet": "breakfast",
+		"lunch_cafe": "cafe", "sauna": "",
+	}
+	if len(draft.Tags) != len(wantTags) {
+		t.Fatalf("draft tags = %v, want %v", draft.Tags, wantTags)
+	}
+	for tag, key := range wantTags {
+		if got, ok := draft.Tags[tag]; !ok || got != key {
+			t.Fatalf("draft tag %q = %q, %v, want %q", tag, got, ok, key)
+		}
+	}
+	wantReview := []string{"breakfast_buffet", "lunch_cafe", "sauna"}
+	if len(draft.Review) != len(wantReview) {
+		t.Fatalf("draft review = %v, want %v", draft.Review, wantReview)
+	}
+	for i, tag := range wantReview {
+		if draft.Review[i] != tag {
+			t.Fatalf("draft review = %v, want %v", draft.Review, wantReview)
+		}
+	}
+}
+
+func TestEmbeddedMapping(t *testing.T) {
+	mapping, err := ForSource("2gis")
+	if err != nil {
+		t.Fatalf("ForSource(\"2gis\") = %v", err)
+	}
+	if key, _ := mapping.Map("", "Завтрак включён"); key != "breakfast" {
+		t.Fatalf("embedded mapping maps breakfast to %q", key)
+	}
+}
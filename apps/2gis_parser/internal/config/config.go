@@ -2,14 +2,27 @@ package config
 
 import (
 	"os"
+	"strconv"
 )
 
 type Config struct {
 	TwoGisAPIKey string
+	// TwoGisRequestsPerSecond limits requests made by every 2GIS API call combined
+	TwoGisRequestsPerSecond float64
 }
 
 func Load() *Config {
 	return &Config{
-		TwoGisAPIKey: os.Getenv("TWO_GIS_API_KEY"),
+		TwoGisAPIKey:            os.Getenv("TWO_GIS_API_KEY"),
+		TwoGisRequestsPerSecond: getEnvFloat("TWO_GIS_RPS", 5),
 	}
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
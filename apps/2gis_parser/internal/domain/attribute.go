@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// AttributeCatalogEntry is an attribute as seen in 2GIS attribute groups with its frequency
+type AttributeCatalogEntry struct {
+	Tag      string `json:"tag"`
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Count    int    `json:"count"`             // businesses carrying the attribute in this group
+	Amenity  string `json:"amenity,omitempty"` // amenity key the current mapping assigns
+	Reviewed bool   `json:"reviewed"`          // the tag is listed in the reviewed mapping
+}
+
+// AttributeCatalog collects every attribute tag, name and group seen on fetched businesses
+type AttributeCatalog struct {
+	GeneratedAt string                   `json:"generated_at"`
+	Businesses  int                      `json:"businesses"`
+	Entries     []*AttributeCatalogEntry `json:"entries"`
+
+	entries map[string]*AttributeCatalogEntry
+	seen    map[string]bool // business IDs already added
+}
+
+// NewAttributeCatalog creates an empty attribute catalog
+func NewAttributeCatalog() *AttributeCatalog {
+	return &AttributeCatalog{
+		entries: make(map[string]*AttributeCatalogEntry),
+		seen:    make(map[string]bool),
+	}
+}
+
+// Add records the attributes of a business, businesses returned by several rubrics are counted once
+func (c *AttributeCatalog) Add(business BusinessDetail) {
+	if business.ID != "" {
+		if c.seen[business.ID] {
+			return
+		}
+		c.seen[business.ID] = true
+	}
+	c.Businesses++
+
+	counted := make(map[string]bool)
+	for _, group := range business.AttributeGroups {
+		for _, attr := range group.Attributes {
+			tag := strings.TrimSpace(attr.Tag)
+			name := strings.TrimSpace(attr.Name)
+			if tag == "" && name == "" {
+				continue
+			}
+
+			key := tag + "\x00" + name + "\x00" + group.Name
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+
+			entry, ok := c.entries[key]
+			if !ok {
+				entry = &AttributeCatalogEntry{Tag: tag, Name: name, Group: group.Name}
+				c.entries[key] = entry
+				c.Entries = append(c.Entries, entry)
+			}
+			entry.Count++
+		}
+	}
+}
+
+// Tags returns every tag in the catalog with its most frequent attribute name
+func (c *AttributeCatalog) Tags() map[string]string {
+	best := make(map[string]*AttributeCatalogEntry)
+	for _, entry := range c.Entries {
+		if entry.Tag == "" {
+			continue
+		}
+		if current, ok := best[entry.Tag]; !ok || entry.Count > current.Count {
+			best[entry.Tag] = entry
+		}
+	}
+
+	tags := make(map[string]string, len(best))
+	for tag, entry := range best {
+		tags[tag] = entry.Name
+	}
+	return tags
+}
+
+// Sort orders entries by frequency, most common first
+func (c *AttributeCatalog) Sort() {
+	sort.Slice(c.Entries, func(i, j int) bool {
+		if c.Entries[i].Count != c.Entries[j].Count {
+			return c.Entries[i].Count > c.Entries[j].Count
+		}
+		if c.Entries[i].Tag != c.Entries[j].Tag {
+			return c.Entries[i].Tag < c.Entries[j].Tag
+		}
+		return c.Entries[i].Group < c.Entries[j].Group
+	})
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "encoding/json"
+
 // - Название объекта
 // - Координаты (GPS)
 // - Адрес
@@ -51,9 +53,10 @@ package domain
 
 // Region represents a 2GIS region
 type Region struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Type string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Bounds string `json:"bounds,omitempty"` // WKT polygon, only returned when requested
 }
 
 // RegionsResponse represents the API response for regions list
@@ -69,6 +72,10 @@ type RegionsResponse struct {
 	} `json:"result"`
 }
 
+// PageHandler is called with every page of businesses as soon as it is fetched.
+// Returning an error stops the pagination.
+type PageHandler func(page int, businesses []BusinessDetail) error
+
 // BusinessByIdResponse represents the API response for business details by ID
 type BusinessByIdResponse struct {
 	Meta struct {
@@ -84,27 +91,38 @@ type BusinessByIdResponse struct {
 
 // BusinessDetail represents detailed business information from 2GIS API
 type BusinessDetail struct {
-	ID              string           `json:"id"`
-	Name            string           `json:"name"`
-	Caption         string           `json:"caption"`
-	FullName        string           `json:"full_name"`
-	FullAddressName string           `json:"full_address_name"`
-	AddressName     string           `json:"address_name"`
-	Address         Address          `json:"address"`
-	Point           Point            `json:"point"`
-	PurposeName     string           `json:"purpose_name"`
-	Type            string           `json:"type"`
-	AttributeGroups []AttributeGroup `json:"attribute_groups"`
-	Rubrics         []Rubric         `json:"rubrics"`
-	Schedule        Schedule         `json:"schedule"`
-	Reviews         ReviewInfo       `json:"reviews"`
-	Dates           Dates            `json:"dates"`
-	Flags           Flags            `json:"flags"`
-	Links           Links            `json:"links"`
-	Statistics      Statistics       `json:"statistics"`
-	Stat            Stat             `json:"stat"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Caption         string            `json:"caption"`
+	FullName        string            `json:"full_name"`
+	FullAddressName string            `json:"full_address_name"`
+	AddressName     string            `json:"address_name"`
+	Address         Address           `json:"address"`
+	Point           Point             `json:"point"`
+	PurposeName     string            `json:"purpose_name"`
+	Type            string            `json:"type"`
+	AttributeGroups []AttributeGroup  `json:"attribute_groups"`
+	Rubrics         []Rubric          `json:"rubrics"`
+	ContactGroups   []ContactGroup    `json:"contact_groups"`
+	ExternalContent []ExternalContent `json:"external_content"`
+	Schedule        Schedule          `json:"schedule"`
+	ScheduleSpecial json.RawMessage   `json:"schedule_special"`
+	Reviews         ReviewInfo        `json:"reviews"`
+	Dates           Dates             `json:"dates"`
+	Flags           Flags             `json:"flags"`
+	Links           Links             `json:"links"`
+	Statistics      Statistics        `json:"statistics"`
+	Stat            Stat              `json:"stat"`
+
+	DiscoveryMethod string `json:"-"` // DiscoveryRubric or DiscoveryKeyword, set by the parser
 }
 
+// Ways a business can be found by the parser
+const (
+	DiscoveryRubric  = "rubric"  // returned by the rubric crawl
+	DiscoveryKeyword = "keyword" // returned only by a tourism keyword search
+)
+
 // Address represents the address information
 type Address struct {
 	BuildingID string             `json:"building_id"`
@@ -142,6 +160,47 @@ type Attribute struct {
 	Tag  string `json:"tag"`
 }
 
+// ContactGroup represents a group of contacts, e.g. of a single department
+type ContactGroup struct {
+	Name     string    `json:"name"`
+	Contacts []Contact `json:"contacts"`
+}
+
+// Contact represents a single contact such as a phone, email, website or messenger
+type Contact struct {
+	Type      string `json:"type"` // phone, email, website, whatsapp, telegram, instagram, vkontakte, ...
+	Value     string `json:"value"`
+	Text      string `json:"text"`
+	PrintText string `json:"print_text"`
+	URL       string `json:"url"`
+	Comment   string `json:"comment"`
+}
+
+// ExternalContent represents media attached to the business, such as photo albums
+type ExternalContent struct {
+	Type         string         `json:"type"`    // photo_album, ...
+	Subtype      string         `json:"subtype"` // common, food, interior, ...
+	Count        int            `json:"count"`
+	MainPhotoURL string         `json:"main_photo_url"`
+	URL          string         `json:"url"`
+	Items        []ContentPhoto `json:"items"`
+}
+
+// ContentPhoto represents a single photo of an external content album
+type ContentPhoto struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Photo represents a stored photo of an accommodation
+type Photo struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Source string `json:"source"`
+}
+
 // Rubric represents business category/rubric
 type Rubric struct {
 	ID       string `json:"id"`
@@ -154,13 +213,15 @@ type Rubric struct {
 
 // Schedule represents business working hours
 type Schedule struct {
-	Mon *DaySchedule `json:"Mon,omitempty"`
-	Tue *DaySchedule `json:"Tue,omitempty"`
-	Wed *DaySchedule `json:"Wed,omitempty"`
-	Thu *DaySchedule `json:"Thu,omitempty"`
-	Fri *DaySchedule `json:"Fri,omitempty"`
-	Sat *DaySchedule `json:"Sat,omitempty"`
-	Sun *DaySchedule `json:"Sun,omitempty"`
+	Is24x7  bool         `json:"is_24x7"`
+	Comment string       `json:"comment"`
+	Mon     *DaySchedule `json:"Mon,omitempty"`
+	Tue     *DaySchedule `json:"Tue,omitempty"`
+	Wed     *DaySchedule `json:"Wed,omitempty"`
+	Thu     *DaySchedule `json:"Thu,omitempty"`
+	Fri     *DaySchedule `json:"Fri,omitempty"`
+	Sat     *DaySchedule `json:"Sat,omitempty"`
+	Sun     *DaySchedule `json:"Sun,omitempty"`
 }
 
 // DaySchedule represents working hours for a specific day
@@ -272,4 +333,7 @@ type RubricData struct {
 	ParentID string `json:"parent_id"`
 	ShortID  int    `json:"short_id"`
 	Count    int    `json:"count"` // How many times this rubric appeared
+
+	KeywordHits int      `json:"keyword_hits"`       // How many keywords returned this rubric
+	Keywords    []string `json:"keywords,omitempty"` // Keywords that returned this rubric
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBusinessNotFound is returned when the source no longer knows a business ID
+var ErrBusinessNotFound = errors.New("business not found")
+
+// PartialResultError is returned when pagination failed after some pages were already collected.
+// The businesses returned alongside it are incomplete.
+type PartialResultError struct {
+	Page      int // Page that failed
+	Collected int // Businesses collected before the failure
+	Err       error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("partial result: page %d failed after %d businesses collected: %v", e.Page, e.Collected, e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BoundingBox represents a rectangular area in WGS84 coordinates
+type BoundingBox struct {
+	MinLon float64 `json:"min_lon"`
+	MinLat float64 `json:"min_lat"`
+	MaxLon float64 `json:"max_lon"`
+	MaxLat float64 `json:"max_lat"`
+}
+
+// Grid splits the box into rows x cols equal cells
+func (b BoundingBox) Grid(rows, cols int) []BoundingBox {
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+
+	lonStep := (b.MaxLon - b.MinLon) / float64(cols)
+	latStep := (b.MaxLat - b.MinLat) / float64(rows)
+
+	cells := make([]BoundingBox, 0, rows*cols)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			cells = append(cells, BoundingBox{
+				MinLon: b.MinLon + float64(col)*lonStep,
+				MinLat: b.MinLat + float64(row)*latStep,
+				MaxLon: b.MinLon + float64(col+1)*lonStep,
+				MaxLat: b.MinLat + float64(row+1)*latStep,
+			})
+		}
+	}
+	return cells
+}
+
+// Split divides the box into four quadrants
+func (b BoundingBox) Split() []BoundingBox {
+	return b.Grid(2, 2)
+}
+
+// WKT returns the box as a closed WKT polygon in "lon lat" order as expected by 2GIS
+func (b BoundingBox) WKT() string {
+	return fmt.Sprintf("POLYGON((%s %s,%s %s,%s %s,%s %s,%s %s))",
+		formatCoord(b.MinLon), formatCoord(b.MinLat),
+		formatCoord(b.MaxLon), formatCoord(b.MinLat),
+		formatCoord(b.MaxLon), formatCoord(b.MaxLat),
+		formatCoord(b.MinLon), formatCoord(b.MaxLat),
+		formatCoord(b.MinLon), formatCoord(b.MinLat),
+	)
+}
+
+// ParseWKTBounds returns the bounding box of a WKT POLYGON or MULTIPOLYGON
+func ParseWKTBounds(wkt string) (BoundingBox, error) {
+	start := strings.Index(wkt, "(")
+	end := strings.LastIndex(wkt, ")")
+	if start < 0 || end <= start {
+		return BoundingBox{}, fmt.Errorf("invalid WKT geometry: %q", wkt)
+	}
+
+	body := strings.NewReplacer("(", "", ")", "").Replace(wkt[start:end])
+
+	var box BoundingBox
+	found := false
+	for _, pair := range strings.Split(body, ",") {
+		coords := strings.Fields(pair)
+		if len(coords) < 2 {
+			continue
+		}
+		lon, err := strconv.ParseFloat(coords[0], 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("invalid WKT longitude %q: %w", coords[0], err)
+		}
+		lat, err := strconv.ParseFloat(coords[1], 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("invalid WKT latitude %q: %w", coords[1], err)
+		}
+
+		if !found {
+			box = BoundingBox{MinLon: lon, MinLat: lat, MaxLon: lon, MaxLat: lat}
+			found = true
+			continue
+		}
+		if lon < box.MinLon {
+			box.MinLon = lon
+		}
+		if lon > box.MaxLon {
+			box.MaxLon = lon
+		}
+		if lat < box.MinLat {
+			box.MinLat = lat
+		}
+		if lat > box.MaxLat {
+			box.MaxLat = lat
+		}
+	}
+
+	if !found {
+		return BoundingBox{}, fmt.Errorf("WKT geometry has no coordinates: %q", wkt)
+	}
+	return box, nil
+}
+
+func formatCoord(value float64) string {
+	return strconv.FormatFloat(value, 'f', 6, 64)
+}
@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlmatyTimezone is the timezone opening hours are interpreted in unless stated otherwise
+const AlmatyTimezone = "Asia/Almaty"
+
+// almatyFallback is used when the system has no timezone database (Kazakhstan is UTC+5 all year)
+var almatyFallback = time.FixedZone(AlmatyTimezone, 5*60*60)
+
+// weekdayKeys maps time.Weekday to the day keys of the normalized opening hours
+var weekdayKeys = [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// OpeningHours is the normalized opening_hours JSON stored for an accommodation
+type OpeningHours struct {
+	Timezone string                    `json:"timezone"`
+	Is24x7   bool                      `json:"is_24x7"`
+	Comment  string                    `json:"comment,omitempty"`
+	Week     map[string][]TimeInterval `json:"week"` // mon..sun, a missing day means closed
+	Special  []SpecialHours            `json:"special,omitempty"`
+}
+
+// TimeInterval is an "HH:MM" opening interval, To <= From means it ends on the next day
+type TimeInterval struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SpecialHours overrides the weekly schedule for a date range, e.g. holidays or a season
+type SpecialHours struct {
+	DateFrom string         `json:"date_from"` // YYYY-MM-DD, inclusive
+	DateTo   string         `json:"date_to"`   // YYYY-MM-DD, inclusive
+	Closed   bool           `json:"closed"`
+	Hours    []TimeInterval `json:"hours,omitempty"` // empty means the weekly schedule applies
+	Comment  string         `json:"comment,omitempty"`
+}
+
+// specialScheduleEntry is a single entry of the 2GIS schedule_special field
+type specialScheduleEntry struct {
+	Date         string        `json:"date"`
+	DateFrom     string        `json:"date_from"`
+	DateTo       string        `json:"date_to"`
+	IsDayOff     bool          `json:"is_day_off"`
+	IsClosed     bool          `json:"is_closed"`
+	WorkingHours []WorkingHour `json:"working_hours"`
+	Comment      string        `json:"comment"`
+}
+
+// NewOpeningHours normalizes a 2GIS schedule and its special schedule.
+// It returns nil when the business has no known opening hours.
+func NewOpeningHours(schedule Schedule, scheduleSpecial json.RawMessage) *OpeningHours {
+	hours := &OpeningHours{
+		Timezone: AlmatyTimezone,
+		Is24x7:   schedule.Is24x7,
+		Comment:  strings.TrimSpace(schedule.Comment),
+		Week:     make(map[string][]TimeInterval),
+		Special:  ParseScheduleSpecial(scheduleSpecial),
+	}
+
+	days := map[string]*DaySchedule{
+		"mon": schedule.Mon, "tue": schedule.Tue, "wed": schedule.Wed, "thu": schedule.Thu,
+		"fri": schedule.Fri, "sat": schedule.Sat, "sun": schedule.Sun,
+	}
+	for key, day := range days {
+		if intervals := toIntervals(day); len(intervals) > 0 {
+			hours.Week[key] = intervals
+		}
+	}
+
+	if !hours.Is24x7 && len(hours.Week) == 0 && len(hours.Special) == 0 {
+		return nil
+	}
+	return hours
+}
+
+// ParseScheduleSpecial decodes the 2GIS schedule_special field, which may be a single
+// entry or a list of entries. Unknown shapes are ignored rather than failing the business.
+func ParseScheduleSpecial(raw json.RawMessage) []SpecialHours {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var entries []specialScheduleEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		var entry specialScheduleEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil
+		}
+		entries = []specialScheduleEntry{entry}
+	}
+
+	var special []SpecialHours
+	for _, entry := range entries {
+		from, to := entry.DateFrom, entry.DateTo
+		if entry.Date != "" {
+			from, to = entry.Date, entry.Date
+		}
+		if from == "" {
+			continue
+		}
+		if to == "" {
+			to = from
+		}
+
+		hours := make([]TimeInterval, 0, len(entry.WorkingHours))
+		for _, wh := range entry.WorkingHours {
+			hours = append(hours, TimeInterval{From: wh.From, To: wh.To})
+		}
+
+		special = append(special, SpecialHours{
+			DateFrom: from,
+			DateTo:   to,
+			Closed:   entry.IsDayOff || entry.IsClosed,
+			Hours:    hours,
+			Comment:  strings.TrimSpace(entry.Comment),
+		})
+	}
+	return special
+}
+
+// IsOpenAt reports whether the business is open at t, interpreted in the schedule timezone
+func (h OpeningHours) IsOpenAt(t time.Time) bool {
+	local := t.In(h.location())
+	minute := local.Hour()*60 + local.Minute()
+
+	today, closed := h.intervalsOn(local)
+	if closed {
+		return false
+	}
+	if h.Is24x7 {
+		return true
+	}
+
+	for _, interval := range today {
+		from, to, ok := interval.minutes()
+		if !ok {
+			continue
+		}
+		if to > from && minute >= from && minute < to {
+			return true
+		}
+		if to <= from && minute >= from {
+			return true
+		}
+	}
+
+	// Intervals of the previous day that run past midnight
+	yesterday, _ := h.intervalsOn(local.AddDate(0, 0, -1))
+	for _, interval := range yesterday {
+		from, to, ok := interval.minutes()
+		if ok && to <= from && minute < to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// intervalsOn returns the opening intervals of a day and whether the day is explicitly closed.
+// Special hours with dates that are not valid YYYY-MM-DD dates are ignored.
+func (h OpeningHours) intervalsOn(day time.Time) ([]TimeInterval, bool) {
+	date := day.Format(time.DateOnly)
+	for _, special := range h.Special {
+		if !validDate(special.DateFrom) || !validDate(special.DateTo) ||
+			date < special.DateFrom || date > special.DateTo {
+			continue
+		}
+		if special.Closed {
+			return nil, true
+		}
+		if len(special.Hours) > 0 {
+			return special.Hours, false
+		}
+	}
+	return h.Week[weekdayKeys[day.Weekday()]], false
+}
+
+// location returns the schedule timezone, Asia/Almaty when it is empty or unknown
+func (h OpeningHours) location() *time.Location {
+	if h.Timezone != "" {
+		if loc, err := time.LoadLocation(h.Timezone); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(AlmatyTimezone)
+	if err != nil {
+		return almatyFallback
+	}
+	return loc
+}
+
+func validDate(value string) bool {
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// minutes returns the interval bounds as minutes since midnight
+func (i TimeInterval) minutes() (int, int, bool) {
+	from, ok := parseClock(i.From)
+	if !ok {
+		return 0, 0, false
+	}
+	to, ok := parseClock(i.To)
+	if !ok {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// parseClock parses "H:MM" or "HH:MM" into minutes since midnight, "24:00" is allowed.
+// accommodation_is_open_at in the database accepts exactly the same values.
+func parseClock(value string) (int, bool) {
+	hoursPart, minutesPart, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || len(hoursPart) < 1 || len(hoursPart) > 2 || len(minutesPart) != 2 ||
+		!isDigits(hoursPart) || !isDigits(minutesPart) {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(hoursPart)
+	minutes, _ := strconv.Atoi(minutesPart)
+	if hours > 24 || minutes > 59 {
+		return 0, false
+	}
+	total := hours*60 + minutes
+	if total > 24*60 {
+		return 0, false
+	}
+	return total, true
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func toIntervals(day *DaySchedule) []TimeInterval {
+	if day == nil {
+		return nil
+	}
+	intervals := make([]TimeInterval, 0, len(day.WorkingHours))
+	for _, wh := range day.WorkingHours {
+		if wh.From == "" || wh.To == "" {
+			continue
+		}
+		intervals = append(intervals, TimeInterval{From: wh.From, To: wh.To})
+	}
+	return intervals
+}
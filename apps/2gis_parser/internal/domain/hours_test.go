@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// openAtMigration creates accommodation_is_open_at and tolerates objects that already exist, it
+// is applied in a rolled back transaction to check the SQL function against the same cases as IsOpenAt
+const openAtMigration = "../../../../infrastructure/database/migrations/0002_parser_tracking.up.sql"
+
+const (
+	weekdayHours   = `{"week": {"mon": [{"from": "09:00", "to": "18:00"}], "tue": [{"from": "09:00", "to": "18:00"}]}}`
+	overnightHours = `{"week": {"fri": [{"from": "22:00", "to": "03:00"}]}}`
+)
+
+// isOpenAtCases are shared by the Go and the SQL implementation. at is the wall clock time in
+// zone, 2025-06-02 is a Monday and 2025-06-06 a Friday.
+var isOpenAtCases = []struct {
+	name  string
+	hours string
+	at    string
+	zone  string
+	want  bool
+}{
+	{name: "within an interval", hours: weekdayHours, at: "2025-06-02 10:00", want: true},
+	{name: "before opening", hours: weekdayHours, at: "2025-06-02 08:59", want: false},
+	{name: "closing time is exclusive", hours: weekdayHours, at: "2025-06-02 18:00", want: false},
+	{name: "day without hours is closed", hours: weekdayHours, at: "2025-06-04 12:00", want: false},
+	{name: "overnight interval before midnight", hours: overnightHours, at: "2025-06-06 23:30", want: true},
+	{name: "overnight interval after midnight", hours: overnightHours, at: "2025-06-07 02:59", want: true},
+	{name: "overnight interval ended", hours: overnightHours, at: "2025-06-07 03:00", want: false},
+	{name: "overnight interval does not open the next evening", hours: overnightHours, at: "2025-06-07 23:00", want: false},
+	{
+		name:  "24:00 closes at midnight",
+		hours: `{"week": {"sat": [{"from": "10:00", "to": "24:00"}]}}`,
+		at:    "2025-06-07 23:59", want: true,
+	},
+	{
+		name:  "24:00 does not run into the next day",
+		hours: `{"week": {"sat": [{"from": "10:00", "to": "24:00"}]}}`,
+		at:    "2025-06-08 00:00", want: false,
+	},
+	{name: "24x7", hours: `{"is_24x7": true, "week": {}}`, at: "2025-06-04 03:00", want: true},
+	{
+		name:  "24x7 closed on a special day",
+		hours: `{"is_24x7": true, "special": [{"date_from": "2025-06-02", "date_to": "2025-06-02", "closed": true}]}`,
+		at:    "2025-06-02 12:00", want: false,
+	},
+	{
+		name:  "24x7 open after the special day",
+		hours: `{"is_24x7": true, "special": [{"date_from": "2025-06-02", "date_to": "2025-06-02", "closed": true}]}`,
+		at:    "2025-06-03 00:00", want: true,
+	},
+	{
+		name: "special hours replace the week",
+		hours: `{"week": {"mon": [{"from": "09:00", "to": "18:00"}]},
+			"special": [{"date_from": "2025-06-01", "date_to": "2025-06-03", "hours": [{"from": "12:00", "to": "14:00"}]}]}`,
+		at: "2025-06-02 10:00", want: false,
+	},
+	{
+		name: "within special hours",
+		hours: `{"week": {"mon": [{"from": "09:00", "to": "18:00"}]},
+			"special": [{"date_from": "2025-06-01", "date_to": "2025-06-03", "hours": [{"from": "12:00", "to": "14:00"}]}]}`,
+		at: "2025-06-02 13:00", want: true,
+	},
+	{
+		name: "special day without hours keeps the week",
+		hours: `{"week": {"mon": [{"from": "09:00", "to": "18:00"}]},
+			"special": [{"date_from": "2025-06-02", "date_to": "2025-06-02", "comment": "sanitary day"}]}`,
+		at: "2025-06-02 10:00", want: true,
+	},
+	{
+		name: "closed special day stops the overnight interval of the day before",
+		hours: `{"week": {"fri": [{"from": "22:00", "to": "03:00"}]},
+			"special": [{"date_from": "2025-06-07", "date_to": "2025-06-07", "closed": true}]}`,
+		at: "2025-06-07 01:00", want: false,
+	},
+	{
+		name: "closed day before has no overnight interval",
+		hours: `{"week": {"fri": [{"from": "22:00", "to": "03:00"}]},
+			"special": [{"date_from": "2025-06-06", "date_to": "2025-06-06", "closed": true}]}`,
+		at: "2025-06-07 01:00", want: false,
+	},
+	{
+		name: "malformed intervals are skipped",
+		hours: `{"week": {"mon": [{"from": "", "to": "18:00"}, {"from": "9am", "to": "6pm"},
+			{"from": "25:00", "to": "26:00"}, {"from": "09:60", "to": "18:00"}, {"from": "09:00", "to": "18:00"}]}}`,
+		at: "2025-06-02 10:00", want: true,
+	},
+	{
+		name:  "only malformed intervals are closed",
+		hours: `{"week": {"mon": [{"from": "9", "to": "18"}, {"from": "09:00:00", "to": "18:00:00"}]}}`,
+		at:    "2025-06-02 10:00", want: false,
+	},
+	{name: "clock without a leading zero", hours: `{"week": {"mon": [{"from": "9:00", "to": "18:00"}]}}`, at: "2025-06-02 09:00", want: true},
+	{
+		name: "special hours with invalid dates are ignored",
+		hours: `{"week": {"mon": [{"from": "09:00", "to": "18:00"}]},
+			"special": [{"date_from": "soon", "date_to": "2025-06-02", "closed": true},
+				{"date_from": "2025-06-02", "date_to": "2025-02-30", "closed": true}]}`,
+		at: "2025-06-02 10:00", want: true,
+	},
+	{
+		name:  "unknown timezone falls back to Asia/Almaty",
+		hours: `{"timezone": "Mars/Olympus", "week": {"mon": [{"from": "09:00", "to": "18:00"}]}}`,
+		at:    "2025-06-02 10:00", want: true,
+	},
+	{
+		name:  "schedule timezone",
+		hours: `{"timezone": "UTC", "week": {"mon": [{"from": "09:00", "to": "18:00"}]}}`,
+		at:    "2025-06-02 17:30", zone: "UTC", want: true,
+	},
+	{
+		name:  "schedule timezone after closing",
+		hours: `{"timezone": "UTC", "week": {"mon": [{"from": "09:00", "to": "18:00"}]}}`,
+		at:    "2025-06-02 18:30", zone: "UTC", want: false,
+	},
+}
+
+// caseZone is the timezone the wall clock time of a case is given in, Asia/Almaty by default
+func caseZone(zone string) string {
+	if zone == "" {
+		return AlmatyTimezone
+	}
+	return zone
+}
+
+func TestOpeningHoursIsOpenAt(t *testing.T) {
+	for _, tt := range isOpenAtCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var hours OpeningHours
+			if err := json.Unmarshal([]byte(tt.hours), &hours); err != nil {
+				t.Fatalf("decode hours: %v", err)
+			}
+			loc := OpeningHours{Timezone: caseZone(tt.zone)}.location()
+			at, err := time.ParseInLocation("2006-01-02 15:04", tt.at, loc)
+			if err != nil {
+				t.Fatalf("parse time: %v", err)
+			}
+
+			if got := hours.IsOpenAt(at); got != tt.want {
+				t.Fatalf("IsOpenAt(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   int
+		wantOK bool
+	}{
+		{value: "00:00", want: 0, wantOK: true},
+		{value: "9:05", want: 545, wantOK: true},
+		{value: " 18:30 ", want: 1110, wantOK: true},
+		{value: "24:00", want: 1440, wantOK: true},
+		{value: "24:01"},
+		{value: "12:60"},
+		{value: "123:00"},
+		{value: "12:5"},
+		{value: "12"},
+		{value: "-1:00"},
+		{value: "ab:cd"},
+		{value: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseClock(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseClock(%q) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestAccommodationIsOpenAtSQL runs the IsOpenAt cases against accommodation_is_open_at in the
+// database of TEST_DATABASE_URL, e.g. the docker-compose Postgres. It is skipped without one.
+func TestAccommodationIsOpenAtSQL(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	migration, err := os.ReadFile(openAtMigration)
+	if err != nil {
+		t.Fatalf("read migration: %v", err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(string(migration)); err != nil {
+		t.Fatalf("apply migration: %v", err)
+	}
+
+	for _, tt := range isOpenAtCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			// The wall clock time is converted to a moment in the case zone by the database
+			// itself, so both sides agree on the zone offset whatever their tz data
+			err := tx.QueryRow(`SELECT accommodation_is_open_at($1::jsonb, $2::timestamp AT TIME ZONE $3)`,
+				tt.hours, tt.at, caseZone(tt.zone)).Scan(&got)
+			if err != nil {
+				t.Fatalf("accommodation_is_open_at: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("accommodation_is_open_at(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+// RubricNode is a rubric in the 2GIS rubric hierarchy together with its tourism score
+type RubricNode struct {
+	ID          string        `json:"id"`
+	Alias       string        `json:"alias,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	Kind        string        `json:"kind,omitempty"` // primary, additional or group for parent nodes
+	ParentID    string        `json:"parent_id,omitempty"`
+	Count       int           `json:"count"`
+	KeywordHits int           `json:"keyword_hits"`
+	Score       float64       `json:"score"` // share of tourism keywords that returned the rubric
+	Keywords    []string      `json:"keywords,omitempty"`
+	Children    []*RubricNode `json:"children,omitempty"`
+}
+
+// RubricTree is the exported rubric hierarchy
+type RubricTree struct {
+	RegionID      string        `json:"region_id"`
+	KeywordsTotal int           `json:"keywords_total"`
+	GeneratedAt   string        `json:"generated_at"`
+	Roots         []*RubricNode `json:"roots"`
+}
+
+// RubricSuggestion is a rubric proposed for crawling with the reason it was picked
+type RubricSuggestion struct {
+	Rubric      RubricData
+	Score       float64
+	Explanation string
+}
+
+// RubricDiff lists rubrics added to and removed from a rubric list
+type RubricDiff struct {
+	Added   []RubricData
+	Removed []RubricData
+}
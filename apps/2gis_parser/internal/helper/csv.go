@@ -51,3 +51,50 @@ func LoadFieldsFromCSV(filename string) ([]string, error) {
 	}
 	return fields, nil
 }
+
+// LoadRubricIDsFromCSV loads rubric IDs from a rubrics CSV file with an "ID" header column
+func LoadRubricIDsFromCSV(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rubrics file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rubrics CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Find the ID column from the header, fall back to the first column
+	idColumn := 0
+	for i, column := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(column), "ID") {
+			idColumn = i
+			break
+		}
+	}
+
+	var ids []string
+	for _, record := range records[1:] {
+		if len(record) > idColumn && strings.TrimSpace(record[idColumn]) != "" {
+			ids = append(ids, strings.TrimSpace(record[idColumn]))
+		}
+	}
+	return ids, nil
+}
+
+// SplitList splits a comma separated flag value into trimmed, non-empty items
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
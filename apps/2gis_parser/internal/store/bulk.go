@@ -0,0 +1,402 @@
+package store
+
+import (
+	"2gis-parser/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"mytravel/pkg/metrics"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// bulkBatchSize is the number of rows copied and upserted in one transaction
+const bulkBatchSize = 500
+
+// Row outcomes of a bulk upsert, also used as parsing_logs operations
+const (
+	OutcomeInserted  = "insert"
+	OutcomeUpdated   = "update"
+	OutcomeUnchanged = "skip"
+	OutcomeFailed    = "failed"
+)
+
+// stagingColumns are the accommodation columns written by the 2GIS bulk upsert, in COPY order
+var stagingColumns = []string{
+	"name", "latitude", "longitude", "address", "accommodation_type",
+	"phone", "email", "social_media_links", "website_url", "social_media_page",
+	"service_description", "room_count", "capacity", "price_range_min", "price_range_max",
+	"photos", "rating", "review_count", "reviews", "amenities",
+	"verification_status", "source_website", "source_url", "external_id", "opening_hours",
+	"source_categories", "taxonomy_version", "discovery_method", "source_updated_at",
+	"region", "city", "region_kato",
+}
+
+// RowResult is the outcome of a single business in a bulk upsert
+type RowResult struct {
+	ExternalID string
+	Outcome    string // OutcomeInserted, OutcomeUpdated, OutcomeUnchanged or OutcomeFailed
+	Err        error
+}
+
+// UpsertSummary reports the outcome of every row of a bulk upsert
+type UpsertSummary struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Failed    int
+	Rows      []RowResult
+}
+
+// add records a row result and updates the counters
+func (s *UpsertSummary) add(result RowResult) {
+	switch result.Outcome {
+	case OutcomeInserted:
+		s.Inserted++
+	case OutcomeUpdated:
+		s.Updated++
+	case OutcomeUnchanged:
+		s.Unchanged++
+	default:
+		s.Failed++
+	}
+	s.Rows = append(s.Rows, result)
+}
+
+// merge adds the rows of another summary
+func (s *UpsertSummary) merge(other UpsertSummary) {
+	for _, row := range other.Rows {
+		s.add(row)
+	}
+}
+
+// Err returns an error describing failed rows, nil when every row was stored
+func (s UpsertSummary) Err() error {
+	if s.Failed == 0 {
+		return nil
+	}
+	for _, row := range s.Rows {
+		if row.Err != nil {
+			return fmt.Errorf("%d of %d rows failed, first failure %s: %w", s.Failed, len(s.Rows), row.ExternalID, row.Err)
+		}
+	}
+	return fmt.Errorf("%d of %d rows failed", s.Failed, len(s.Rows))
+}
+
+// UpsertBusinessDetails stores businesses in transactions of bulkBatchSize rows. Every batch is
+// copied into a staging table and upserted with a single statement that skips rows whose stored
+// values are identical, so unchanged businesses cost no write. A batch the database rejects is
+// retried row by row to find the failing rows. The returned error is only set when the batch
+// could not be processed at all, per-row failures are reported in the summary.
+func (ps *PostgresStore) UpsertBusinessDetails(ctx context.Context, businesses []domain.BusinessDetail) (UpsertSummary, error) {
+	var summary UpsertSummary
+
+	// Businesses not written yet stay in the queue depth until their batch is done
+	queued := len(businesses)
+	metrics.AddQueued("2gis", queued)
+	defer func() { metrics.AddQueued("2gis", -queued) }()
+
+	for start := 0; start < len(businesses); start += bulkBatchSize {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		end := start + bulkBatchSize
+		if end > len(businesses) {
+			end = len(businesses)
+		}
+		summary.merge(ps.upsertBatch(writeContext(ctx), businesses[start:end]))
+		metrics.AddQueued("2gis", start-end)
+		queued -= end - start
+	}
+
+	ps.logger.Info("Bulk upsert completed: %d inserted, %d updated, %d unchanged, %d failed out of %d total",
+		summary.Inserted, summary.Updated, summary.Unchanged, summary.Failed, len(businesses))
+	return summary, nil
+}
+
+// upsertBatch converts, validates and upserts a single batch
+func (ps *PostgresStore) upsertBatch(ctx context.Context, businesses []domain.BusinessDetail) UpsertSummary {
+	var summary UpsertSummary
+	startTime := time.Now()
+
+	// Convert and validate, later duplicates of an ID replace earlier ones since a
+	// single INSERT ... ON CONFLICT cannot touch the same row twice
+	records := make([]AccommodationRecord, 0, len(businesses))
+	positions := make(map[string]int, len(businesses))
+	for _, business := range businesses {
+		accommodation := ps.convertBusinessDetailToAccommodation(business)
+		if err := ps.validateJSONFields(accommodation); err != nil {
+			summary.add(RowResult{ExternalID: business.ID, Outcome: OutcomeFailed, Err: fmt.Errorf("invalid JSON data: %w", err)})
+			continue
+		}
+		if i, ok := positions[accommodation.ExternalID]; ok {
+			records[i] = accommodation
+			continue
+		}
+		positions[accommodation.ExternalID] = len(records)
+		records = append(records, accommodation)
+	}
+
+	outcomes, err := ps.copyAndUpsert(ctx, records)
+	if err != nil {
+		ps.logger.Error("Bulk upsert of %d businesses failed, retrying row by row: %v", len(records), err)
+		for _, accommodation := range records {
+			outcome, err := ps.upsertAccommodation(ctx, accommodation)
+			summary.add(RowResult{ExternalID: accommodation.ExternalID, Outcome: outcome, Err: err})
+		}
+	} else {
+		for _, accommodation := range records {
+			summary.add(RowResult{ExternalID: accommodation.ExternalID, Outcome: outcomes[accommodation.ExternalID]})
+		}
+	}
+
+	ps.logBatch(ctx, "2gis", summary.Rows, startTime)
+	return summary
+}
+
+// copyAndUpsert writes records through a staging table in one transaction and returns the
+// outcome per external ID. Change detection happens in SQL by comparing the staged values
+// with the stored row, so only rows that differ are written.
+func (ps *PostgresStore) copyAndUpsert(ctx context.Context, records []AccommodationRecord) (map[string]string, error) {
+	outcomes := make(map[string]string, len(records))
+	if len(records) == 0 {
+		return outcomes, nil
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE accommodations_staging ON COMMIT DROP AS
+		SELECT `+strings.Join(stagingColumns, ", ")+`
+		FROM accommodations WITH NO DATA
+	`); err != nil {
+		return nil, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("accommodations_staging", stagingColumns...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start copy: %w", err)
+	}
+	for _, accommodation := range records {
+		if _, err := stmt.ExecContext(ctx, ps.stagingValues(accommodation)...); err != nil {
+			stmt.Close()
+			return nil, fmt.Errorf("failed to copy business %s: %w", accommodation.ExternalID, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return nil, fmt.Errorf("failed to flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish copy: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO accommodations (
+			name, latitude, longitude, address, accommodation_type,
+			phone, email, social_media_links, website_url, social_media_page,
+			service_description, room_count, capacity, price_range_min, price_range_max,
+			photos, rating, review_count, reviews, amenities,
+			verification_status, source_website, source_url, external_id, opening_hours,
+			source_categories, taxonomy_version, discovery_method, source_updated_at, full_refreshed_at,
+			last_run_id, region, city, region_kato
+		)
+		SELECT
+			s.name, s.latitude, s.longitude, s.address, s.accommodation_type,
+			s.phone, s.email, s.social_media_links, s.website_url, s.social_media_page,
+			s.service_description, s.room_count, s.capacity, s.price_range_min, s.price_range_max,
+			s.photos, s.rating, s.review_count, s.reviews, s.amenities,
+			s.verification_status, s.source_website, s.source_url, s.external_id, s.opening_hours,
+			s.source_categories, s.taxonomy_version, s.discovery_method, s.source_updated_at, CURRENT_TIMESTAMP,
+			$1, s.region, s.city, s.region_kato
+		FROM accommodations_staging s
+		LEFT JOIN accommodations a
+			ON a.source_website = s.source_website AND a.external_id = s.external_id
+		WHERE a.id IS NULL OR (
+			a.name, a.latitude, a.longitude, a.address, a.accommodation_type,
+			a.phone, a.email, a.social_media_links, a.website_url, a.social_media_page,
+			a.service_description, a.room_count, a.capacity, a.price_range_min, a.price_range_max,
+			a.photos, a.rating, a.review_count, a.reviews, a.amenities,
+			a.verification_status, a.source_url, a.opening_hours,
+			a.source_categories, a.taxonomy_version, a.source_updated_at,
+			CASE WHEN a.discovery_method = 'rubric' THEN a.discovery_method ELSE COALESCE(s.discovery_method, a.discovery_method) END
+		) IS DISTINCT FROM (
+			s.name, s.latitude, s.longitude, s.address, s.accommodation_type,
+			s.phone, s.email, s.social_media_links, s.website_url, s.social_media_page,
+			s.service_description, s.room_count, s.capacity, s.price_range_min, s.price_range_max,
+			s.photos, s.rating, s.review_count, s.reviews, s.amenities,
+			s.verification_status, s.source_url, s.opening_hours,
+			s.source_categories, s.taxonomy_version, s.source_updated_at,
+			a.discovery_method
+		)
+		ON CONFLICT (source_website, external_id)
+		DO UPDATE SET
+			name = EXCLUDED.name,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			address = EXCLUDED.address,
+			accommodation_type = EXCLUDED.accommodation_type,
+			phone = EXCLUDED.phone,
+			email = EXCLUDED.email,
+			social_media_links = EXCLUDED.social_media_links,
+			website_url = EXCLUDED.website_url,
+			social_media_page = EXCLUDED.social_media_page,
+			service_description = EXCLUDED.service_description,
+			room_count = EXCLUDED.room_count,
+			capacity = EXCLUDED.capacity,
+			price_range_min = EXCLUDED.price_range_min,
+			price_range_max = EXCLUDED.price_range_max,
+			photos = EXCLUDED.photos,
+			rating = EXCLUDED.rating,
+			review_count = EXCLUDED.review_count,
+			reviews = EXCLUDED.reviews,
+			amenities = EXCLUDED.amenities,
+			verification_status = EXCLUDED.verification_status,
+			opening_hours = EXCLUDED.opening_hours,
+			source_categories = EXCLUDED.source_categories,
+			taxonomy_version = EXCLUDED.taxonomy_version,
+			discovery_method = CASE
+				WHEN accommodations.discovery_method = 'rubric' THEN accommodations.discovery_method
+				ELSE COALESCE(EXCLUDED.discovery_method, accommodations.discovery_method)
+			END,
+			source_updated_at = EXCLUDED.source_updated_at,
+			full_refreshed_at = EXCLUDED.full_refreshed_at,
+			last_run_id = EXCLUDED.last_run_id,
+			region = EXCLUDED.region,
+			city = EXCLUDED.city,
+			region_kato = EXCLUDED.region_kato,
+			last_updated = CURRENT_TIMESTAMP
+		RETURNING external_id, (xmax = 0) AS was_insert
+	`, runIDFrom(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert staged businesses: %w", err)
+	}
+	for rows.Next() {
+		var externalID string
+		var wasInsert bool
+		if err := rows.Scan(&externalID, &wasInsert); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan upsert result: %w", err)
+		}
+		outcomes[externalID] = OutcomeUpdated
+		if wasInsert {
+			outcomes[externalID] = OutcomeInserted
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to read upsert results: %w", err)
+	}
+	rows.Close()
+
+	// Unchanged rows were fetched in full as well, which the incremental refresh has to know
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE accommodations a
+		SET full_refreshed_at = CURRENT_TIMESTAMP
+		FROM accommodations_staging s
+		WHERE a.source_website = s.source_website AND a.external_id = s.external_id
+			AND a.full_refreshed_at IS DISTINCT FROM CURRENT_TIMESTAMP
+	`); err != nil {
+		return nil, fmt.Errorf("failed to mark unchanged businesses as refreshed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk upsert: %w", err)
+	}
+
+	for _, accommodation := range records {
+		if _, ok := outcomes[accommodation.ExternalID]; !ok {
+			outcomes[accommodation.ExternalID] = OutcomeUnchanged
+		}
+	}
+	return outcomes, nil
+}
+
+// stagingValues returns the values of a record in stagingColumns order. JSON is passed as
+// text, COPY would otherwise encode byte slices as bytea.
+func (ps *PostgresStore) stagingValues(accommodation AccommodationRecord) []interface{} {
+	return []interface{}{
+		accommodation.Name,
+		accommodation.Latitude,
+		accommodation.Longitude,
+		accommodation.Address,
+		accommodation.AccommodationType,
+		accommodation.Phone,
+		accommodation.Email,
+		ps.safeJSONBytes(accommodation.SocialMediaLinks),
+		accommodation.WebsiteURL,
+		accommodation.SocialMediaPage,
+		accommodation.ServiceDescription,
+		accommodation.RoomCount,
+		accommodation.Capacity,
+		accommodation.PriceRangeMin,
+		accommodation.PriceRangeMax,
+		ps.safeJSONBytes(accommodation.Photos),
+		accommodation.Rating,
+		accommodation.ReviewCount,
+		ps.safeJSONBytes(accommodation.Reviews),
+		ps.safeJSONBytes(accommodation.Amenities),
+		accommodation.VerificationStatus,
+		accommodation.SourceWebsite,
+		accommodation.SourceURL,
+		accommodation.ExternalID,
+		ps.safeJSONBytes(accommodation.OpeningHours),
+		ps.safeJSONBytes(accommodation.SourceCategories),
+		accommodation.TaxonomyVersion,
+		accommodation.DiscoveryMethod,
+		accommodation.SourceUpdatedAt,
+		accommodation.Region,
+		accommodation.City,
+		accommodation.RegionKATO,
+	}
+}
+
+// logBatch writes the parsing_logs entries of a batch with a single statement
+func (ps *PostgresStore) logBatch(ctx context.Context, sourceWebsite string, results []RowResult, startTime time.Time) {
+	if len(results) == 0 {
+		return
+	}
+
+	for _, result := range results {
+		metrics.AddUpserts(sourceWebsite, result.Outcome, 1)
+	}
+
+	completedAt := time.Now()
+	duration := int(completedAt.Sub(startTime).Milliseconds())
+
+	externalIDs := make([]string, 0, len(results))
+	operations := make([]string, 0, len(results))
+	statuses := make([]string, 0, len(results))
+	errorMessages := make([]sql.NullString, 0, len(results))
+	for _, result := range results {
+		externalIDs = append(externalIDs, result.ExternalID)
+		if result.Err != nil {
+			operations = append(operations, "insert")
+			statuses = append(statuses, "failed")
+			errorMessages = append(errorMessages, sql.NullString{String: result.Err.Error(), Valid: true})
+			continue
+		}
+		operations = append(operations, result.Outcome)
+		statuses = append(statuses, "success")
+		errorMessages = append(errorMessages, sql.NullString{})
+	}
+
+	_, err := ps.db.ExecContext(ctx, `
+		INSERT INTO parsing_logs (
+			source_website, operation, status, error_message, started_at, completed_at, duration_ms, external_id, run_id
+		)
+		SELECT $1::source_website, operation, status, error_message, $2, $3, $4, external_id, $9
+		FROM unnest($5::text[], $6::text[], $7::text[], $8::text[]) AS l(external_id, operation, status, error_message)
+	`, sourceWebsite, startTime, completedAt, duration,
+		pq.Array(externalIDs), pq.Array(operations), pq.Array(statuses), pq.Array(errorMessages), runIDFrom(ctx))
+	if err != nil {
+		ps.logger.Error("Failed to log bulk upsert of %d businesses: %v", len(results), err)
+	}
+}
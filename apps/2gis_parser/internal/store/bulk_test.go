@@ -0,0 +1,306 @@
+package store
+
+import (
+	"2gis-parser/internal/adapter/logger"
+	"2gis-parser/internal/adapter/twogis"
+	"2gis-parser/internal/domain"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// replayFixtures holds the recorded 2GIS responses the parser tests replay
+const replayFixtures = "../usecase/testdata/fixtures"
+
+// Hotels recorded in replayFixtures
+var replayedIDs = []string{"70000001023456781", "70000001023456782"}
+
+func replayedBusinesses(t *testing.T) []domain.BusinessDetail {
+	t.Helper()
+	api, err := twogis.NewReplayAPI(replayFixtures, logger.New("test"))
+	if err != nil {
+		t.Fatalf("NewReplayAPI() = %v", err)
+	}
+	businesses, err := api.FetchBusinessDetails(context.Background(), replayedIDs)
+	if err != nil || len(businesses) != len(replayedIDs) {
+		t.Fatalf("FetchBusinessDetails() = %d businesses, %v, want %d", len(businesses), err, len(replayedIDs))
+	}
+	return businesses
+}
+
+// bulkDB records what a bulk upsert sends through the bulkfake driver and answers the upsert
+// statement with the rows of returned
+type bulkDB struct {
+	mu         sync.Mutex
+	statements []string
+	copied     [][]driver.Value // rows sent with COPY
+	flushed    bool
+	committed  bool
+	returned   [][]driver.Value // external_id, was_insert
+}
+
+var (
+	bulkDBsMu sync.Mutex
+	bulkDBs   = make(map[string]*bulkDB)
+)
+
+func init() {
+	sql.Register("bulkfake", bulkDriver{})
+}
+
+// newBulkStore returns a store writing to a new bulkDB
+func newBulkStore(t *testing.T) (*PostgresStore, *bulkDB) {
+	t.Helper()
+	fake := &bulkDB{}
+	bulkDBsMu.Lock()
+	bulkDBs[t.Name()] = fake
+	bulkDBsMu.Unlock()
+
+	db, err := sql.Open("bulkfake", t.Name())
+	if err != nil {
+		t.Fatalf("open fake database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ps, err := newPostgresStore(db, logger.New("test"))
+	if err != nil {
+		t.Fatalf("newPostgresStore() = %v", err)
+	}
+	return ps, fake
+}
+
+type bulkDriver struct{}
+
+func (bulkDriver) Open(name string) (driver.Conn, error) {
+	bulkDBsMu.Lock()
+	defer bulkDBsMu.Unlock()
+	fake, ok := bulkDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &bulkConn{db: fake}, nil
+}
+
+type bulkConn struct{ db *bulkDB }
+
+func (c *bulkConn) Prepare(query string) (driver.Stmt, error) {
+	return &bulkStmt{db: c.db, query: query}, nil
+}
+
+func (c *bulkConn) Close() error              { return nil }
+func (c *bulkConn) Begin() (driver.Tx, error) { return bulkTx{db: c.db}, nil }
+
+type bulkTx struct{ db *bulkDB }
+
+func (tx bulkTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.committed = true
+	return nil
+}
+
+func (tx bulkTx) Rollback() error { return nil }
+
+type bulkStmt struct {
+	db    *bulkDB
+	query string
+}
+
+func (s *bulkStmt) Close() error  { return nil }
+func (s *bulkStmt) NumInput() int { return -1 }
+
+func (s *bulkStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	switch {
+	case !strings.HasPrefix(s.query, "COPY"):
+		s.db.statements = append(s.db.statements, s.query)
+	case len(args) == 0:
+		s.db.flushed = true
+	default:
+		s.db.copied = append(s.db.copied, args)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *bulkStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.statements = append(s.db.statements, s.query)
+	if !strings.Contains(s.query, "INSERT INTO accommodations") {
+		return &bulkRows{}, nil
+	}
+	return &bulkRows{rows: s.db.returned}, nil
+}
+
+type bulkRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *bulkRows) Columns() []string { return []string{"external_id", "was_insert"} }
+func (r *bulkRows) Close() error      { return nil }
+
+func (r *bulkRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// stagingColumn returns the position of a column in stagingColumns
+func stagingColumn(t *testing.T, name string) int {
+	t.Helper()
+	for i, column := range stagingColumns {
+		if column == name {
+			return i
+		}
+	}
+	t.Fatalf("no staging column %s", name)
+	return -1
+}
+
+func TestCopyAndUpsertOutcomes(t *testing.T) {
+	ps, fake := newBulkStore(t)
+	// The upsert returns inserted and updated rows only, rows with identical values are skipped
+	fake.returned = [][]driver.Value{{"a", true}, {"b", false}}
+	records := []AccommodationRecord{
+		{Name: "A", SourceWebsite: "2gis", ExternalID: "a"},
+		{Name: "B", SourceWebsite: "2gis", ExternalID: "b"},
+		{Name: "C", SourceWebsite: "2gis", ExternalID: "c"},
+	}
+
+	outcomes, err := ps.copyAndUpsert(context.Background(), records)
+	if err != nil {
+		t.Fatalf("copyAndUpsert() = %v", err)
+	}
+	want := map[string]string{"a": OutcomeInserted, "b": OutcomeUpdated, "c": OutcomeUnchanged}
+	if fmt.Sprint(outcomes) != fmt.Sprint(want) {
+		t.Fatalf("copyAndUpsert() = %v, want %v", outcomes, want)
+	}
+
+	if len(fake.copied) != len(records) || !fake.flushed || !fake.committed {
+		t.Fatalf("copied %d rows, flushed %v, committed %v, want %d rows flushed and committed",
+			len(fake.copied), fake.flushed, fake.committed, len(records))
+	}
+	if !strings.Contains(fake.statements[0], "CREATE TEMP TABLE accommodations_staging ON COMMIT DROP") {
+		t.Fatalf("first statement %q, want the staging table", fake.statements[0])
+	}
+}
+
+func TestUpsertBusinessDetailsStagesReplayedBusinesses(t *testing.T) {
+	ps, fake := newBulkStore(t)
+	businesses := replayedBusinesses(t)
+	// A later duplicate in the same batch replaces the earlier one
+	renamed := businesses[0]
+	renamed.Name = "Renamed"
+	businesses = append(businesses, renamed)
+	fake.returned = [][]driver.Value{{replayedIDs[0], true}}
+
+	summary, err := ps.UpsertBusinessDetails(context.Background(), businesses)
+	if err != nil {
+		t.Fatalf("UpsertBusinessDetails() = %v", err)
+	}
+	if summary.Inserted != 1 || summary.Unchanged != 1 || summary.Updated != 0 || summary.Failed != 0 {
+		t.Fatalf("UpsertBusinessDetails() = %+v, want 1 inserted and 1 unchanged", summary)
+	}
+	if got := fmt.Sprint(summary.Rows); got != fmt.Sprintf("[{%s insert <nil>} {%s skip <nil>}]", replayedIDs[0], replayedIDs[1]) {
+		t.Fatalf("rows = %s, want one result per business in listing order", got)
+	}
+
+	if len(fake.copied) != len(replayedIDs) {
+		t.Fatalf("copied %d rows, want %d, duplicates are staged once", len(fake.copied), len(replayedIDs))
+	}
+	name, externalID := stagingColumn(t, "name"), stagingColumn(t, "external_id")
+	for i, row := range fake.copied {
+		if len(row) != len(stagingColumns) {
+			t.Fatalf("row %d has %d values, want one per staging column (%d)", i, len(row), len(stagingColumns))
+		}
+		if row[externalID] != replayedIDs[i] {
+			t.Fatalf("row %d external_id = %v, want %s", i, row[externalID], replayedIDs[i])
+		}
+		for j, value := range row {
+			// COPY sends byte slices as bytea, which JSON columns do not accept
+			if _, ok := value.([]byte); ok {
+				t.Fatalf("row %d %s is a byte slice, want JSON as text", i, stagingColumns[j])
+			}
+		}
+	}
+	if fake.copied[0][name] != "Renamed" {
+		t.Fatalf("staged name %v, want the later duplicate", fake.copied[0][name])
+	}
+}
+
+func TestUpsertSummary(t *testing.T) {
+	var batch UpsertSummary
+	batch.add(RowResult{ExternalID: "a", Outcome: OutcomeInserted})
+	batch.add(RowResult{ExternalID: "b", Outcome: OutcomeUpdated})
+	batch.add(RowResult{ExternalID: "c", Outcome: OutcomeUnchanged})
+	if err := batch.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil without failed rows", err)
+	}
+
+	failure := errors.New("value too long")
+	var next UpsertSummary
+	next.add(RowResult{ExternalID: "d", Outcome: OutcomeFailed, Err: failure})
+	next.add(RowResult{ExternalID: "e", Outcome: OutcomeFailed, Err: errors.New("other")})
+
+	var summary UpsertSummary
+	summary.merge(batch)
+	summary.merge(next)
+	if summary.Inserted != 1 || summary.Updated != 1 || summary.Unchanged != 1 || summary.Failed != 2 || len(summary.Rows) != 5 {
+		t.Fatalf("merged summary = %+v, want 1 inserted, updated and unchanged, 2 failed, 5 rows", summary)
+	}
+	err := summary.Err()
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "2 of 5 rows failed, first failure d") {
+		t.Fatalf("Err() = %v, want the count and the first failure", err)
+	}
+}
+
+// TestUpsertBusinessDetailsSQL runs the bulk upsert against the database of TEST_DATABASE_URL
+func TestUpsertBusinessDetailsSQL(t *testing.T) {
+	ps := newTestStore(t, replayedIDs...)
+	ctx := context.Background()
+	businesses := replayedBusinesses(t)
+
+	changed := append([]domain.BusinessDetail(nil), businesses...)
+	changed[1].Name += " (renovated)"
+
+	steps := []struct {
+		name       string
+		businesses []domain.BusinessDetail
+		want       []string // outcome per business
+	}{
+		{name: "new", businesses: businesses, want: []string{OutcomeInserted, OutcomeInserted}},
+		{name: "identical values", businesses: businesses, want: []string{OutcomeUnchanged, OutcomeUnchanged}},
+		{name: "one renamed", businesses: changed, want: []string{OutcomeUnchanged, OutcomeUpdated}},
+	}
+	for _, step := range steps {
+		summary, err := ps.UpsertBusinessDetails(ctx, step.businesses)
+		if err != nil {
+			t.Fatalf("%s: UpsertBusinessDetails() = %v", step.name, err)
+		}
+		if len(summary.Rows) != len(step.want) {
+			t.Fatalf("%s: rows = %+v, want %v", step.name, summary.Rows, step.want)
+		}
+		for i, row := range summary.Rows {
+			if row.ExternalID != replayedIDs[i] || row.Outcome != step.want[i] || row.Err != nil {
+				t.Fatalf("%s: rows = %+v, want %v", step.name, summary.Rows, step.want)
+			}
+		}
+	}
+
+	var name string
+	err := ps.db.QueryRow(`SELECT name FROM accommodations WHERE source_website = '2gis' AND external_id = $1`,
+		replayedIDs[1]).Scan(&name)
+	if err != nil || name != changed[1].Name {
+		t.Fatalf("stored name = %q, %v, want %q", name, err, changed[1].Name)
+	}
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DefaultResumeAge is how long an unfinished crawl run is resumed before the next run starts
+// over, so rubrics that keep failing do not pin the crawl to an old run forever
+const DefaultResumeAge = 24 * time.Hour
+
+// CrawlCheckpoint records how far a crawl got for a single region and rubric
+type CrawlCheckpoint struct {
+	RunID        string
+	RegionID     string
+	RubricID     string
+	LastPage     int
+	FetchedCount int
+	Completed    bool
+	UpdatedAt    time.Time // Last save, set when loaded
+}
+
+// Key returns the lookup key of the checkpoint within a run
+func (c CrawlCheckpoint) Key() string {
+	return CheckpointKey(c.RegionID, c.RubricID)
+}
+
+// CheckpointKey builds the lookup key for a region and rubric pair
+func CheckpointKey(regionID, rubricID string) string {
+	return regionID + ":" + rubricID
+}
+
+// LoadCrawlCheckpoints returns the unfinished run for a source together with its checkpoints.
+// An empty run ID means there is nothing to resume.
+func (ps *PostgresStore) LoadCrawlCheckpoints(ctx context.Context, sourceWebsite string) (string, map[string]CrawlCheckpoint, error) {
+	var runID string
+	err := ps.db.QueryRowContext(ctx, `
+		SELECT run_id
+		FROM crawl_checkpoints
+		WHERE source_website = $1
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`, sourceWebsite).Scan(&runID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", map[string]CrawlCheckpoint{}, nil
+		}
+		return "", nil, fmt.Errorf("failed to find crawl run to resume: %w", err)
+	}
+
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT region_id, rubric_id, last_page, fetched_count, completed, coalesce(updated_at, CURRENT_TIMESTAMP)
+		FROM crawl_checkpoints
+		WHERE source_website = $1 AND run_id = $2
+	`, sourceWebsite, runID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load crawl checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	checkpoints := make(map[string]CrawlCheckpoint)
+	for rows.Next() {
+		checkpoint := CrawlCheckpoint{RunID: runID}
+		if err := rows.Scan(&checkpoint.RegionID, &checkpoint.RubricID, &checkpoint.LastPage, &checkpoint.FetchedCount,
+			&checkpoint.Completed, &checkpoint.UpdatedAt); err != nil {
+			return "", nil, fmt.Errorf("failed to scan crawl checkpoint: %w", err)
+		}
+		checkpoints[checkpoint.Key()] = checkpoint
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to read crawl checkpoints: %w", err)
+	}
+
+	return runID, checkpoints, nil
+}
+
+// SaveCrawlCheckpoint upserts the progress of a region and rubric within a run.
+// Like inserts it completes even if ctx is cancelled, so stored pages are never re-fetched.
+func (ps *PostgresStore) SaveCrawlCheckpoint(ctx context.Context, sourceWebsite string, checkpoint CrawlCheckpoint) error {
+	_, err := ps.db.ExecContext(writeContext(ctx), `
+		INSERT INTO crawl_checkpoints (
+			run_id, source_website, region_id, rubric_id, last_page, fetched_count, completed, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+		ON CONFLICT (source_website, run_id, region_id, rubric_id)
+		DO UPDATE SET
+			last_page = EXCLUDED.last_page,
+			fetched_count = EXCLUDED.fetched_count,
+			completed = EXCLUDED.completed,
+			updated_at = CURRENT_TIMESTAMP
+	`,
+		checkpoint.RunID,
+		sourceWebsite,
+		checkpoint.RegionID,
+		checkpoint.RubricID,
+		checkpoint.LastPage,
+		checkpoint.FetchedCount,
+		checkpoint.Completed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save crawl checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ClearCrawlCheckpoints removes all checkpoints of a source so the next run starts from scratch
+func (ps *PostgresStore) ClearCrawlCheckpoints(ctx context.Context, sourceWebsite string) error {
+	if _, err := ps.db.ExecContext(ctx, `DELETE FROM crawl_checkpoints WHERE source_website = $1`, sourceWebsite); err != nil {
+		return fmt.Errorf("failed to clear crawl checkpoints: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// DefaultClosureMisses is how many failed re-checks in a row soft-delete an accommodation
+const DefaultClosureMisses = 3
+
+// Closure audit actions
+const (
+	ClosureMissed   = "missed"   // not listed and not found by ID, below the threshold
+	ClosureDeleted  = "deleted"  // soft-deleted after reaching the threshold
+	ClosureRestored = "restored" // seen again after being soft-deleted
+)
+
+// RecordSightings stores that the businesses were listed in a region and rubric during a crawl run.
+// Listed businesses are found again by definition, so their misses are reset and soft-deleted
+// ones are restored.
+func (ps *PostgresStore) RecordSightings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string, externalIDs []string) error {
+	if len(externalIDs) == 0 {
+		return nil
+	}
+	ctx = writeContext(ctx)
+
+	_, err := ps.db.ExecContext(ctx, `
+		INSERT INTO listing_sightings (source_website, region_id, rubric_id, external_id, last_seen_run, last_seen_at)
+		SELECT $1::source_website, $2, $3, external_id, $4, CURRENT_TIMESTAMP
+		FROM unnest($5::text[]) AS external_id
+		ON CONFLICT (source_website, region_id, rubric_id, external_id)
+		DO UPDATE SET
+			last_seen_run = EXCLUDED.last_seen_run,
+			last_seen_at = EXCLUDED.last_seen_at
+	`, sourceWebsite, regionID, rubricID, runID, pq.Array(externalIDs))
+	if err != nil {
+		return fmt.Errorf("failed to record sightings: %w", err)
+	}
+
+	reason := fmt.Sprintf("listed again in rubric %s of region %s (run %s)", rubricID, regionID, runID)
+	return ps.MarkFound(ctx, sourceWebsite, externalIDs, reason)
+}
+
+// UnseenListings returns businesses previously listed in a region and rubric that the given
+// run did not list. Soft-deleted accommodations are not returned.
+func (ps *PostgresStore) UnseenListings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string) ([]string, error) {
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT s.external_id
+		FROM listing_sightings s
+		JOIN accommodations a
+			ON a.source_website = s.source_website AND a.external_id = s.external_id
+		WHERE s.source_website = $1 AND s.region_id = $2 AND s.rubric_id = $3
+			AND s.last_seen_run <> $4 AND a.deleted_at IS NULL
+		ORDER BY s.external_id
+	`, sourceWebsite, regionID, rubricID, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unseen listings: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan unseen listing: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ForgetSighting removes a business from the listing of a region and rubric, used when a
+// re-check finds it still open but it is no longer listed there
+func (ps *PostgresStore) ForgetSighting(ctx context.Context, sourceWebsite, regionID, rubricID, externalID string) error {
+	_, err := ps.db.ExecContext(writeContext(ctx), `
+		DELETE FROM listing_sightings
+		WHERE source_website = $1 AND region_id = $2 AND rubric_id = $3 AND external_id = $4
+	`, sourceWebsite, regionID, rubricID, externalID)
+	if err != nil {
+		return fmt.Errorf("failed to forget sighting: %w", err)
+	}
+	return nil
+}
+
+// MarkFound resets the misses of accommodations that were found again and restores
+// soft-deleted ones, recording every restore in accommodation_closures
+func (ps *PostgresStore) MarkFound(ctx context.Context, sourceWebsite string, externalIDs []string, reason string) error {
+	if len(externalIDs) == 0 {
+		return nil
+	}
+
+	var restored int
+	err := ps.db.QueryRowContext(writeContext(ctx), `
+		WITH previous AS (
+			SELECT id, deleted_at
+			FROM accommodations
+			WHERE source_website = $1 AND external_id = ANY($2)
+				AND (deleted_at IS NOT NULL OR consecutive_misses > 0)
+			FOR UPDATE
+		), found AS (
+			UPDATE accommodations a
+			SET deleted_at = NULL, consecutive_misses = 0, last_run_id = COALESCE($5, a.last_run_id)
+			FROM previous
+			WHERE a.id = previous.id
+			RETURNING a.id, a.external_id, previous.deleted_at AS previous_deleted_at
+		), audit AS (
+			INSERT INTO accommodation_closures (accommodation_id, source_website, external_id, action, reason)
+			SELECT id, $1, external_id, $3, $4
+			FROM found
+			WHERE previous_deleted_at IS NOT NULL
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM audit
+	`, sourceWebsite, pq.Array(externalIDs), ClosureRestored, reason, runIDFrom(ctx)).Scan(&restored)
+	if err != nil {
+		return fmt.Errorf("failed to mark accommodations as found: %w", err)
+	}
+
+	if restored > 0 {
+		ps.logger.Info("Restored %d soft-deleted %s accommodations: %s", restored, sourceWebsite, reason)
+	}
+	return nil
+}
+
+// RecordMiss counts a failed re-check of an accommodation and soft-deletes it once it was
+// missed threshold times in a row. It returns the audit action that was recorded.
+func (ps *PostgresStore) RecordMiss(ctx context.Context, sourceWebsite, externalID string, threshold int, reason string) (string, error) {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	var action string
+	err := ps.db.QueryRowContext(writeContext(ctx), `
+		WITH missed AS (
+			UPDATE accommodations
+			SET consecutive_misses = consecutive_misses + 1,
+				deleted_at = CASE
+					WHEN consecutive_misses + 1 >= $3 THEN CURRENT_TIMESTAMP
+					ELSE deleted_at
+				END,
+				last_run_id = COALESCE($7, last_run_id)
+			WHERE source_website = $1 AND external_id = $2 AND deleted_at IS NULL
+			RETURNING id, consecutive_misses, deleted_at
+		)
+		INSERT INTO accommodation_closures (accommodation_id, source_website, external_id, action, reason)
+		SELECT id, $1, $2,
+			CASE WHEN deleted_at IS NULL THEN $4 ELSE $5 END,
+			$6 || ' (miss ' || consecutive_misses || ' of ' || $3 || ')'
+		FROM missed
+		RETURNING action
+	`, sourceWebsite, externalID, threshold, ClosureMissed, ClosureDeleted, reason, runIDFrom(ctx)).Scan(&action)
+	if err == sql.ErrNoRows {
+		return "", nil // already soft-deleted or no longer stored
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to record miss of %s: %w", externalID, err)
+	}
+	return action, nil
+}
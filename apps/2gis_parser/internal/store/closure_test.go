@@ -0,0 +1,135 @@
+package store
+
+import (
+	"2gis-parser/internal/adapter/logger"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// newTestStore connects to the migrated database of TEST_DATABASE_URL, e.g. the docker-compose
+// Postgres, and skips the test without one. Accommodations of the given external IDs are
+// removed before and after the test, together with their audit rows.
+func newTestStore(t *testing.T, externalIDs ...string) *PostgresStore {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	ps, err := newPostgresStore(db, logger.New("test"))
+	if err != nil {
+		t.Fatalf("newPostgresStore() = %v", err)
+	}
+
+	cleanup := func() {
+		if _, err := db.Exec(`DELETE FROM accommodations WHERE source_website = '2gis' AND external_id = ANY($1)`,
+			pq.Array(externalIDs)); err != nil {
+			t.Errorf("remove test accommodations: %v", err)
+		}
+	}
+	cleanup()
+	t.Cleanup(func() {
+		cleanup()
+		db.Close()
+	})
+	return ps
+}
+
+// closureState returns the misses, whether the accommodation is soft-deleted and its audit actions
+func closureState(t *testing.T, ps *PostgresStore, externalID string) (int, bool, []string) {
+	t.Helper()
+	var misses int
+	var deleted bool
+	err := ps.db.QueryRow(`SELECT consecutive_misses, deleted_at IS NOT NULL FROM accommodations
+		WHERE source_website = '2gis' AND external_id = $1`, externalID).Scan(&misses, &deleted)
+	if err != nil {
+		t.Fatalf("load accommodation %s: %v", externalID, err)
+	}
+
+	rows, err := ps.db.Query(`SELECT action FROM accommodation_closures
+		WHERE source_website = '2gis' AND external_id = $1 ORDER BY id`, externalID)
+	if err != nil {
+		t.Fatalf("load closures of %s: %v", externalID, err)
+	}
+	defer rows.Close()
+	var actions []string
+	for rows.Next() {
+		var action string
+		if err := rows.Scan(&action); err != nil {
+			t.Fatalf("scan closure: %v", err)
+		}
+		actions = append(actions, action)
+	}
+	return misses, deleted, actions
+}
+
+func TestRecordMiss(t *testing.T) {
+	const id = "test-closure-1"
+	ps := newTestStore(t, id)
+	ctx := context.Background()
+	if _, err := ps.db.Exec(`INSERT INTO accommodations (name, source_website, external_id) VALUES ('Test', '2gis', $1)`, id); err != nil {
+		t.Fatalf("insert accommodation: %v", err)
+	}
+
+	steps := []struct {
+		name        string
+		found       bool // MarkFound instead of RecordMiss
+		wantAction  string
+		wantMisses  int
+		wantDeleted bool
+	}{
+		{name: "first miss", wantAction: ClosureMissed, wantMisses: 1},
+		{name: "found again", found: true, wantMisses: 0},
+		{name: "miss after being found", wantAction: ClosureMissed, wantMisses: 1},
+		{name: "second miss", wantAction: ClosureMissed, wantMisses: 2},
+		{name: "threshold", wantAction: ClosureDeleted, wantMisses: 3, wantDeleted: true},
+		{name: "already deleted", wantAction: "", wantMisses: 3, wantDeleted: true},
+		{name: "restored", found: true, wantMisses: 0},
+	}
+	for _, step := range steps {
+		if step.found {
+			if err := ps.MarkFound(ctx, "2gis", []string{id}, step.name); err != nil {
+				t.Fatalf("%s: MarkFound() = %v", step.name, err)
+			}
+		} else {
+			action, err := ps.RecordMiss(ctx, "2gis", id, 3, step.name)
+			if err != nil {
+				t.Fatalf("%s: RecordMiss() = %v", step.name, err)
+			}
+			if action != step.wantAction {
+				t.Fatalf("%s: RecordMiss() = %q, want %q", step.name, action, step.wantAction)
+			}
+		}
+		misses, deleted, _ := closureState(t, ps, id)
+		if misses != step.wantMisses || deleted != step.wantDeleted {
+			t.Fatalf("%s: misses %d, deleted %v, want %d, %v", step.name, misses, deleted, step.wantMisses, step.wantDeleted)
+		}
+	}
+
+	// Resetting misses is not audited, only the soft-delete and the restore are
+	_, _, actions := closureState(t, ps, id)
+	want := []string{ClosureMissed, ClosureMissed, ClosureMissed, ClosureDeleted, ClosureRestored}
+	if len(actions) != len(want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("audit actions = %v, want %v", actions, want)
+		}
+	}
+}
+
+func TestRecordMissUnknownAccommodation(t *testing.T) {
+	ps := newTestStore(t)
+	action, err := ps.RecordMiss(context.Background(), "2gis", "test-closure-unknown", 1, "not stored")
+	if err != nil || action != "" {
+		t.Fatalf("RecordMiss() = %q, %v, want no action for an accommodation that is not stored", action, err)
+	}
+}
@@ -0,0 +1,23 @@
+package store
+
+import "context"
+
+// runIDKey carries the parser run ID through a context
+type runIDKey struct{}
+
+// WithRunID returns a context whose writes are attributed to the given parser run.
+// Accommodations written with it get last_run_id set, which the accommodation_history
+// trigger records next to every changed field, and parsing_logs entries link to the run.
+// The run has to be opened first, see StartRun.
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// runIDFrom returns the parser run of ctx, nil when writes are not attributed to a run
+func runIDFrom(ctx context.Context) *string {
+	runID, ok := ctx.Value(runIDKey{}).(string)
+	if !ok || runID == "" {
+		return nil
+	}
+	return &runID
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"2gis-parser/internal/domain"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultFullRefreshAge is how long a record may go without a full-detail fetch
+const DefaultFullRefreshAge = 7 * 24 * time.Hour
+
+// sourceUpdatedAtLayouts are the formats 2GIS uses for dates.updated_at
+var sourceUpdatedAtLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseSourceUpdatedAt parses a 2GIS dates.updated_at value, nil when it is missing or unknown.
+// Values without an offset are taken as Almaty time.
+func ParseSourceUpdatedAt(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	location, err := time.LoadLocation(domain.AlmatyTimezone)
+	if err != nil {
+		location = time.FixedZone(domain.AlmatyTimezone, 5*60*60)
+	}
+
+	for _, layout := range sourceUpdatedAtLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// StaleBusinesses returns the IDs of businesses from a lightweight listing that need a full-detail
+// fetch: businesses not stored yet, whose dates.updated_at differs from the stored value or is
+// unknown, and businesses whose last full fetch is older than maxAge.
+func (ps *PostgresStore) StaleBusinesses(ctx context.Context, businesses []domain.BusinessDetail, maxAge time.Duration) ([]string, error) {
+	if len(businesses) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(businesses))
+	for _, business := range businesses {
+		ids = append(ids, business.ID)
+	}
+
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT external_id, source_updated_at, full_refreshed_at
+		FROM accommodations
+		WHERE source_website = '2gis' AND external_id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored update times: %w", err)
+	}
+	defer rows.Close()
+
+	stored := make(map[string]storedVersion, len(ids))
+	for rows.Next() {
+		var id string
+		var version storedVersion
+		if err := rows.Scan(&id, &version.updatedAt, &version.fullRefreshedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stored update time: %w", err)
+		}
+		stored[id] = version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load stored update times: %w", err)
+	}
+
+	return staleBusinesses(businesses, stored, time.Now().Add(-maxAge)), nil
+}
+
+// storedVersion is what StaleBusinesses compares a listed business with
+type storedVersion struct {
+	updatedAt       *time.Time // source_updated_at
+	fullRefreshedAt *time.Time
+}
+
+// staleBusinesses selects the listed businesses that are not stored, changed in 2GIS, cannot be
+// compared or were last fetched in full before refreshBefore
+func staleBusinesses(businesses []domain.BusinessDetail, stored map[string]storedVersion, refreshBefore time.Time) []string {
+	var stale []string
+	for _, business := range businesses {
+		version, ok := stored[business.ID]
+		updatedAt := ParseSourceUpdatedAt(business.Dates.UpdatedAt)
+		switch {
+		case !ok: // new business
+		case updatedAt == nil || version.updatedAt == nil: // change cannot be detected
+		case !updatedAt.Equal(*version.updatedAt): // changed in 2GIS
+		case version.fullRefreshedAt == nil || version.fullRefreshedAt.Before(refreshBefore): // periodic full refresh
+		default:
+			continue
+		}
+		stale = append(stale, business.ID)
+	}
+	return stale
+}
+
+// markFullRefreshed records that a record was fetched with full details even though nothing changed.
+// The last_updated trigger ignores this column, so last_updated keeps pointing at the last real change.
+func (ps *PostgresStore) markFullRefreshed(ctx context.Context, sourceWebsite, externalID string) {
+	_, err := ps.db.ExecContext(ctx, `
+		UPDATE accommodations SET full_refreshed_at = CURRENT_TIMESTAMP
+		WHERE source_website = $1 AND external_id = $2
+	`, sourceWebsite, externalID)
+	if err != nil {
+		ps.logger.Error("Failed to mark %s business %s as fully refreshed: %v", sourceWebsite, externalID, err)
+	}
+}
+
+// timePtrsEqual compares nullable timestamps by instant
+func timePtrsEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"2gis-parser/internal/domain"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseSourceUpdatedAt(t *testing.T) {
+	almaty, err := time.LoadLocation(domain.AlmatyTimezone)
+	if err != nil {
+		t.Skipf("no tz data for %s: %v", domain.AlmatyTimezone, err)
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time // zero for nil
+	}{
+		{value: "2025-03-14T09:30:00+06:00", want: time.Date(2025, 3, 14, 3, 30, 0, 0, time.UTC)},
+		{value: "2025-03-14T09:30:00", want: time.Date(2025, 3, 14, 9, 30, 0, 0, almaty)},
+		{value: "2025-03-14 09:30:00", want: time.Date(2025, 3, 14, 9, 30, 0, 0, almaty)},
+		{value: " 2025-03-14 ", want: time.Date(2025, 3, 14, 0, 0, 0, 0, almaty)},
+		{value: ""},
+		{value: "14.03.2025"},
+	}
+	for _, tt := range tests {
+		got := ParseSourceUpdatedAt(tt.value)
+		switch {
+		case tt.want.IsZero() && got != nil:
+			t.Errorf("ParseSourceUpdatedAt(%q) = %v, want nil", tt.value, got)
+		case !tt.want.IsZero() && (got == nil || !got.Equal(tt.want)):
+			t.Errorf("ParseSourceUpdatedAt(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStaleBusinesses(t *testing.T) {
+	updated := "2025-03-14T09:30:00+06:00"
+	updatedAt := ParseSourceUpdatedAt(updated)
+	earlier := updatedAt.Add(-24 * time.Hour)
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	old := now.Add(-30 * 24 * time.Hour)
+	refreshBefore := now.Add(-DefaultFullRefreshAge)
+
+	tests := []struct {
+		name      string
+		listed    string // dates.updated_at of the listing
+		stored    *storedVersion
+		wantStale bool
+	}{
+		{name: "not stored yet", listed: updated, wantStale: true},
+		{name: "unchanged and recently refreshed", listed: updated, stored: &storedVersion{updatedAt: updatedAt, fullRefreshedAt: &recent}},
+		{name: "changed in 2GIS", listed: updated, stored: &storedVersion{updatedAt: &earlier, fullRefreshedAt: &recent}, wantStale: true},
+		{name: "listing without updated_at", listed: "", stored: &storedVersion{updatedAt: updatedAt, fullRefreshedAt: &recent}, wantStale: true},
+		{name: "unparsable updated_at", listed: "yesterday", stored: &storedVersion{updatedAt: updatedAt, fullRefreshedAt: &recent}, wantStale: true},
+		{name: "stored without updated_at", listed: updated, stored: &storedVersion{fullRefreshedAt: &recent}, wantStale: true},
+		{name: "full refresh due", listed: updated, stored: &storedVersion{updatedAt: updatedAt, fullRefreshedAt: &old}, wantStale: true},
+		{name: "never fetched in full", listed: updated, stored: &storedVersion{updatedAt: updatedAt}, wantStale: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			business := domain.BusinessDetail{ID: "70000001000000001", Dates: domain.Dates{UpdatedAt: tt.listed}}
+			stored := map[string]storedVersion{}
+			if tt.stored != nil {
+				stored[business.ID] = *tt.stored
+			}
+
+			stale := staleBusinesses([]domain.BusinessDetail{business}, stored, refreshBefore)
+			if got := len(stale) == 1; got != tt.wantStale {
+				t.Fatalf("staleBusinesses() = %v, want stale %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestStaleBusinessesKeepsListingOrder(t *testing.T) {
+	updated := "2025-03-14T09:30:00+06:00"
+	updatedAt := ParseSourceUpdatedAt(updated)
+	recent := time.Now().Add(-time.Hour)
+
+	var businesses []domain.BusinessDetail
+	for i := 1; i <= 4; i++ {
+		businesses = append(businesses, domain.BusinessDetail{ID: fmt.Sprint(i), Dates: domain.Dates{UpdatedAt: updated}})
+	}
+	// 2 and 4 are stored and unchanged, 1 and 3 are new
+	stored := map[string]storedVersion{
+		"2": {updatedAt: updatedAt, fullRefreshedAt: &recent},
+		"4": {updatedAt: updatedAt, fullRefreshedAt: &recent},
+	}
+
+	stale := staleBusinesses(businesses, stored, time.Now().Add(-DefaultFullRefreshAge))
+	if fmt.Sprint(stale) != "[1 3]" {
+		t.Fatalf("staleBusinesses() = %v, want [1 3]", stale)
+	}
+}
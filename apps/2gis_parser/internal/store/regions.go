@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// regionBackfillBatch is how many rows BackfillRegions writes per statement
+const regionBackfillBatch = 1000
+
+// RegionSummary reports the outcome of filling in regions and cities of existing rows
+type RegionSummary struct {
+	Checked int // rows with coordinates, only those without a region unless forced
+	Located int // rows inside a region of the boundaries
+	Updated int // rows whose region, city or KATO code changed and were written
+	Outside int // rows outside every region, their region is cleared
+}
+
+// locate fills the region, city and region KATO code of a record from its coordinates
+func (ps *PostgresStore) locate(accommodation *AccommodationRecord) {
+	accommodation.Region, accommodation.City, accommodation.RegionKATO = nil, nil, nil
+	if accommodation.Latitude == nil || accommodation.Longitude == nil ||
+		(*accommodation.Latitude == 0 && *accommodation.Longitude == 0) {
+		return
+	}
+
+	location := ps.boundaries.Locate(*accommodation.Latitude, *accommodation.Longitude)
+	accommodation.Region = ps.safeStringPointer(location.Region)
+	accommodation.City = ps.safeStringPointer(location.City)
+	accommodation.RegionKATO = ps.safeStringPointer(location.RegionKATO)
+}
+
+// BackfillRegions resolves the region and city of existing rows of every source from their
+// coordinates. Rows that already have a region are skipped unless force is set, which is how
+// rows are re-resolved after the boundaries changed.
+func (ps *PostgresStore) BackfillRegions(ctx context.Context, force bool) (RegionSummary, error) {
+	var summary RegionSummary
+
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT id, latitude, longitude, region, city, region_kato
+		FROM accommodations
+		WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+		  AND ($1 OR region IS NULL)
+	`, force)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query accommodations to locate: %w", err)
+	}
+
+	var ids []int64
+	var regions, cities, katos []sql.NullString
+	for rows.Next() {
+		var id int64
+		var latitude, longitude float64
+		var region, city, kato *string
+		if err := rows.Scan(&id, &latitude, &longitude, &region, &city, &kato); err != nil {
+			rows.Close()
+			return summary, fmt.Errorf("failed to scan accommodation to locate: %w", err)
+		}
+		summary.Checked++
+
+		record := AccommodationRecord{Latitude: &latitude, Longitude: &longitude}
+		ps.locate(&record)
+		if record.Region != nil {
+			summary.Located++
+		} else {
+			summary.Outside++
+		}
+
+		if ps.stringPtrsEqual(record.Region, region) && ps.stringPtrsEqual(record.City, city) &&
+			ps.stringPtrsEqual(record.RegionKATO, kato) {
+			continue
+		}
+		ids = append(ids, id)
+		regions = append(regions, toNullString(record.Region))
+		cities = append(cities, toNullString(record.City))
+		katos = append(katos, toNullString(record.RegionKATO))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return summary, fmt.Errorf("failed to read accommodations to locate: %w", err)
+	}
+
+	for start := 0; start < len(ids); start += regionBackfillBatch {
+		end := min(start+regionBackfillBatch, len(ids))
+		result, err := ps.db.ExecContext(ctx, `
+			UPDATE accommodations a
+			SET region = l.region, city = l.city, region_kato = l.region_kato
+			FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[]) AS l(id, region, city, region_kato)
+			WHERE a.id = l.id
+		`, pq.Array(ids[start:end]), pq.Array(regions[start:end]), pq.Array(cities[start:end]), pq.Array(katos[start:end]))
+		if err != nil {
+			return summary, fmt.Errorf("failed to store regions: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			summary.Updated += int(affected)
+		}
+	}
+	return summary, nil
+}
+
+func toNullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
@@ -2,11 +2,22 @@ package store
 
 import (
 	"2gis-parser/internal/adapter/logger"
+	"2gis-parser/internal/amenity"
 	"2gis-parser/internal/domain"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mytravel/pkg/geo"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/normalize"
+	"mytravel/pkg/runs"
+	"mytravel/pkg/schema"
+	"mytravel/pkg/taxonomy"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +26,14 @@ import (
 )
 
 type PostgresStore struct {
-	db     *sql.DB
-	logger *logger.Logger
+	db         *sql.DB
+	logger     *logger.Logger
+	types      map[string]*taxonomy.Mapping // accommodation type mapping per source website
+	amenities  *amenity.Mapping             // 2GIS attribute tag to amenity key mapping
+	boundaries *geo.Boundaries              // regions and cities records are located in
+
+	unmappedMu sync.Mutex
+	unmapped   map[string]int // attribute tags without a reviewed amenity mapping, with occurrences
 }
 
 type DatabaseConfig struct {
@@ -48,11 +65,49 @@ func NewPostgresStore(config DatabaseConfig, logger *logger.Logger) (*PostgresSt
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if err := schema.Check(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	logger.Info("Successfully connected to PostgreSQL database with connection pooling")
 
+	ps, err := newPostgresStore(db, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return ps, nil
+}
+
+// newPostgresStore wraps an open database with the mappings records are converted with
+func newPostgresStore(db *sql.DB, logger *logger.Logger) (*PostgresStore, error) {
+	types := make(map[string]*taxonomy.Mapping)
+	for _, source := range []string{"2gis", "booking"} {
+		mapping, err := taxonomy.ForSource(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load accommodation types: %w", err)
+		}
+		types[source] = mapping
+	}
+
+	amenities, err := amenity.ForSource("2gis")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load amenity mapping: %w", err)
+	}
+
+	boundaries, err := geo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load region boundaries: %w", err)
+	}
+
 	return &PostgresStore{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		types:      types,
+		amenities:  amenities,
+		boundaries: boundaries,
+		unmapped:   make(map[string]int),
 	}, nil
 }
 
@@ -68,13 +123,25 @@ func LoadConfigFromEnv() DatabaseConfig {
 	}
 }
 
+// writeContext detaches a write from cancellation so a started insert and its log entry
+// complete even when shutdown is requested, while keeping the values of ctx.
+func writeContext(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
 // Close closes the database connection
 func (ps *PostgresStore) Close() error {
 	return ps.db.Close()
 }
 
 // InsertBusinessDetail inserts a BusinessDetail from 2GIS API into accommodations table
-func (ps *PostgresStore) InsertBusinessDetail(business domain.BusinessDetail) error {
+// Writes that have already started run with a detached context (see writeContext), so a
+// cancelled ctx stops new inserts but lets in-flight ones finish.
+func (ps *PostgresStore) InsertBusinessDetail(ctx context.Context, business domain.BusinessDetail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx = writeContext(ctx)
 	startTime := time.Now()
 
 	ps.logger.Debug("Processing business ID: %s, Name: %s", business.ID, business.Name)
@@ -88,26 +155,43 @@ func (ps *PostgresStore) InsertBusinessDetail(business domain.BusinessDetail) er
 	// Validate JSON data before insertion
 	if err := ps.validateJSONFields(accommodation); err != nil {
 		ps.logger.Error("Invalid JSON data for business %s: %v", business.ID, err)
-		ps.logBusinessInsertion("2gis", business.ID, "insert", "failed", fmt.Sprintf("Invalid JSON data: %v", err), startTime)
+		ps.logBusinessInsertion(ctx, "2gis", business.ID, "insert", "failed", fmt.Sprintf("Invalid JSON data: %v", err), startTime)
 		return fmt.Errorf("invalid JSON data: %w", err)
 	}
 
+	outcome, err := ps.upsertAccommodation(ctx, accommodation)
+	if err != nil {
+		ps.logger.Error("Failed to insert/update business detail %s: %v", business.ID, err)
+		ps.logBusinessInsertion(ctx, "2gis", business.ID, "insert", "failed", err.Error(), startTime)
+		return err
+	}
+
+	switch outcome {
+	case OutcomeUnchanged:
+		ps.logger.Debug("No changes detected for accommodation: %s (ID: %s), skipping update", business.Name, business.ID)
+		ps.logBusinessInsertion(ctx, "2gis", business.ID, "skip", "success", "No changes detected", startTime)
+	default:
+		ps.logger.Info("Successfully %sed accommodation: %s (ID: %s)", outcome, business.Name, business.ID)
+		ps.logBusinessInsertion(ctx, "2gis", business.ID, outcome, "success", "", startTime)
+	}
+	return nil
+}
+
+// upsertAccommodation writes a single 2GIS record unless it is unchanged and returns the outcome
+func (ps *PostgresStore) upsertAccommodation(ctx context.Context, accommodation AccommodationRecord) (string, error) {
 	// Check if accommodation already exists
-	existingAccommodation, err := ps.getExistingAccommodation(accommodation.SourceWebsite, accommodation.ExternalID)
+	existingAccommodation, err := ps.getExistingAccommodation(ctx, accommodation.SourceWebsite, accommodation.ExternalID)
 	if err != nil {
-		ps.logger.Error("Failed to check existing accommodation for business %s: %v", business.ID, err)
-		ps.logBusinessInsertion("2gis", business.ID, "check", "failed", fmt.Sprintf("Failed to check existing record: %v", err), startTime)
-		return fmt.Errorf("failed to check existing accommodation: %w", err)
+		return OutcomeFailed, fmt.Errorf("failed to check existing accommodation: %w", err)
 	}
 
 	// If record exists, compare and skip update if no changes
 	if existingAccommodation != nil {
 		if ps.accommodationsEqual(existingAccommodation, &accommodation) {
-			ps.logger.Debug("No changes detected for accommodation: %s (ID: %s), skipping update", business.Name, business.ID)
-			ps.logBusinessInsertion("2gis", business.ID, "skip", "success", "No changes detected", startTime)
-			return nil
+			ps.markFullRefreshed(ctx, "2gis", accommodation.ExternalID)
+			return OutcomeUnchanged, nil
 		}
-		ps.logger.Debug("Changes detected for accommodation: %s (ID: %s), proceeding with update", business.Name, business.ID)
+		ps.logger.Debug("Changes detected for accommodation: %s (ID: %s), proceeding with update", accommodation.Name, accommodation.ExternalID)
 	}
 
 	// Perform insert or update
@@ -117,13 +201,17 @@ func (ps *PostgresStore) InsertBusinessDetail(business domain.BusinessDetail) er
 			phone, email, social_media_links, website_url, social_media_page,
 			service_description, room_count, capacity, price_range_min, price_range_max,
 			photos, rating, review_count, reviews, amenities,
-			verification_status, source_website, source_url, external_id
+			verification_status, source_website, source_url, external_id, opening_hours,
+			source_categories, taxonomy_version, discovery_method, source_updated_at, full_refreshed_at,
+			last_run_id, region, city, region_kato
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15,
 			$16, $17, $18, $19, $20,
-			$21, $22, $23, $24
+			$21, $22, $23, $24, $25,
+			$26, $27, $28, $29, CURRENT_TIMESTAMP,
+			$30, $31, $32, $33
 		)
 		ON CONFLICT (source_website, external_id) 
 		DO UPDATE SET
@@ -148,12 +236,25 @@ func (ps *PostgresStore) InsertBusinessDetail(business domain.BusinessDetail) er
 			reviews = EXCLUDED.reviews,
 			amenities = EXCLUDED.amenities,
 			verification_status = EXCLUDED.verification_status,
+			opening_hours = EXCLUDED.opening_hours,
+			source_categories = EXCLUDED.source_categories,
+			taxonomy_version = EXCLUDED.taxonomy_version,
+			discovery_method = CASE
+				WHEN accommodations.discovery_method = 'rubric' THEN accommodations.discovery_method
+				ELSE COALESCE(EXCLUDED.discovery_method, accommodations.discovery_method)
+			END,
+			source_updated_at = EXCLUDED.source_updated_at,
+			full_refreshed_at = EXCLUDED.full_refreshed_at,
+			last_run_id = EXCLUDED.last_run_id,
+			region = EXCLUDED.region,
+			city = EXCLUDED.city,
+			region_kato = EXCLUDED.region_kato,
 			last_updated = CURRENT_TIMESTAMP
 		RETURNING (xmax = 0) AS was_insert
 	`
 
 	var wasInsert bool
-	err = ps.db.QueryRow(query,
+	err = ps.db.QueryRowContext(ctx, query,
 		accommodation.Name,
 		accommodation.Latitude,
 		accommodation.Longitude,
@@ -178,158 +279,45 @@ func (ps *PostgresStore) InsertBusinessDetail(business domain.BusinessDetail) er
 		accommodation.SourceWebsite,
 		accommodation.SourceURL,
 		accommodation.ExternalID,
+		ps.safeJSONBytes(accommodation.OpeningHours),
+		ps.safeJSONBytes(accommodation.SourceCategories),
+		accommodation.TaxonomyVersion,
+		accommodation.DiscoveryMethod,
+		accommodation.SourceUpdatedAt,
+		runIDFrom(ctx),
+		accommodation.Region,
+		accommodation.City,
+		accommodation.RegionKATO,
 	).Scan(&wasInsert)
 
 	if err != nil {
-		ps.logger.Error("Failed to insert/update business detail %s: %v", business.ID, err)
-		operation := "insert" // Default to insert for error logging
-		ps.logBusinessInsertion("2gis", business.ID, operation, "failed", fmt.Sprintf("Database error: %v", err), startTime)
-		return fmt.Errorf("failed to insert/update business detail: %w", err)
+		return OutcomeFailed, fmt.Errorf("failed to insert/update business detail: %w", err)
 	}
 
-	operation := "update"
 	if wasInsert {
-		operation = "insert"
+		return OutcomeInserted, nil
 	}
-
-	ps.logger.Info("Successfully %sed accommodation: %s (ID: %s)", operation, business.Name, business.ID)
-	ps.logBusinessInsertion("2gis", business.ID, operation, "success", "", startTime)
-	return nil
+	return OutcomeUpdated, nil
 }
 
-// InsertBusinessDetails inserts multiple BusinessDetail entities in parallel
-func (ps *PostgresStore) InsertBusinessDetails(businesses []domain.BusinessDetail) error {
-	if len(businesses) == 0 {
-		return nil
-	}
-
-	// Configuration for parallel processing
-	const maxWorkers = 5 // Limit concurrent connections to avoid overwhelming the database
-	const batchSize = 10 // Process businesses in small batches for better error handling
-
-	successCount := 0
-	errorCount := 0
-
-	// Create channels for work distribution
-	businessChan := make(chan domain.BusinessDetail, len(businesses))
-	resultChan := make(chan insertResult, len(businesses))
-
-	// Start worker goroutines
-	for i := 0; i < maxWorkers; i++ {
-		go ps.insertWorker(businessChan, resultChan)
-	}
-
-	// Send businesses to workers
-	for _, business := range businesses {
-		businessChan <- business
-	}
-	close(businessChan)
-
-	// Collect results
-	for i := 0; i < len(businesses); i++ {
-		result := <-resultChan
-		if result.err != nil {
-			errorCount++
-		} else {
-			successCount++
-		}
-	}
-
-	ps.logger.Info("Parallel processing completed: %d successful, %d failed out of %d total",
-		successCount, errorCount, len(businesses))
-
-	return nil
-}
-
-// insertResult represents the result of a single business insertion
-type insertResult struct {
-	businessID string
-	err        error
-}
-
-// insertWorker processes businesses from the channel
-func (ps *PostgresStore) insertWorker(businessChan <-chan domain.BusinessDetail, resultChan chan<- insertResult) {
-	for business := range businessChan {
-		err := ps.InsertBusinessDetail(business)
-		resultChan <- insertResult{
-			businessID: business.ID,
-			err:        err,
-		}
+// InsertBusinessDetails stores businesses through the bulk upsert path (see UpsertBusinessDetails)
+// and returns an error when any of them could not be stored
+func (ps *PostgresStore) InsertBusinessDetails(ctx context.Context, businesses []domain.BusinessDetail) error {
+	summary, err := ps.UpsertBusinessDetails(ctx, businesses)
+	if err != nil {
+		return err
 	}
+	return summary.Err()
 }
 
-// InsertBusinessDetailsWithBatching inserts businesses in parallel batches for better performance
-func (ps *PostgresStore) InsertBusinessDetailsWithBatching(businesses []domain.BusinessDetail) error {
-	if len(businesses) == 0 {
-		return nil
-	}
-
-	const batchSize = 20
-	const maxConcurrentBatches = 3
-
-	var batches [][]domain.BusinessDetail
-	for i := 0; i < len(businesses); i += batchSize {
-		end := i + batchSize
-		if end > len(businesses) {
-			end = len(businesses)
-		}
-		batches = append(batches, businesses[i:end])
-	}
-
-	ps.logger.Info("Processing %d businesses in %d batches with %d concurrent workers",
-		len(businesses), len(batches), maxConcurrentBatches)
-
-	// Use semaphore to limit concurrent batches
-	semaphore := make(chan struct{}, maxConcurrentBatches)
-	var wg sync.WaitGroup
-
-	totalSuccess := 0
-	totalErrors := 0
-	var mu sync.Mutex
-
-	for i, batch := range batches {
-		wg.Add(1)
-		go func(batchIndex int, businessBatch []domain.BusinessDetail) {
-			defer wg.Done()
-
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			ps.logger.Info("Starting batch %d with %d businesses", batchIndex+1, len(businessBatch))
-
-			batchSuccess := 0
-			batchErrors := 0
-
-			for _, business := range businessBatch {
-				if err := ps.InsertBusinessDetail(business); err != nil {
-					batchErrors++
-				} else {
-					batchSuccess++
-				}
-			}
-
-			// Update totals safely
-			mu.Lock()
-			totalSuccess += batchSuccess
-			totalErrors += batchErrors
-			mu.Unlock()
-
-			ps.logger.Info("Completed batch %d: %d successful, %d failed",
-				batchIndex+1, batchSuccess, batchErrors)
-		}(i, batch)
+// logBusinessInsertion logs individual business insertion operations
+func (ps *PostgresStore) logBusinessInsertion(ctx context.Context, sourceWebsite, externalID, operation, status, errorMessage string, startTime time.Time) {
+	outcome := operation
+	if status != "success" {
+		outcome = OutcomeFailed
 	}
+	metrics.AddUpserts(sourceWebsite, outcome, 1)
 
-	wg.Wait()
-
-	ps.logger.Info("All batches completed: %d successful, %d failed out of %d total",
-		totalSuccess, totalErrors, len(businesses))
-
-	return nil
-}
-
-// logBusinessInsertion logs individual business insertion operations
-func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operation, status, errorMessage string, startTime time.Time) {
 	completedAt := time.Now()
 	duration := int(completedAt.Sub(startTime).Milliseconds()) // Changed to milliseconds to match schema
 
@@ -340,11 +328,11 @@ func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operati
 
 	query := `
 		INSERT INTO parsing_logs (
-			source_website, operation, status, error_message, started_at, completed_at, duration_ms, external_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			source_website, operation, status, error_message, started_at, completed_at, duration_ms, external_id, run_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err := ps.db.Exec(query,
+	_, err := ps.db.ExecContext(ctx, query,
 		sourceWebsite,
 		operation,
 		status,
@@ -353,6 +341,7 @@ func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operati
 		completedAt,
 		duration,
 		externalID,
+		runIDFrom(ctx),
 	)
 
 	if err != nil {
@@ -360,11 +349,26 @@ func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operati
 	}
 }
 
-// LogParsingActivity is deprecated - use logBusinessInsertion instead
+// StartRun opens a parsing run of a source in parsing_runs, or resumes it when the ID exists.
+// Writes with a context from WithRunID(ctx, run.ID) are linked to the run.
+func (ps *PostgresStore) StartRun(ctx context.Context, sourceWebsite, runID string) (*runs.Run, error) {
+	return runs.Start(ctx, ps.db, sourceWebsite, runID)
+}
+
+// LogParsingActivity records an already finished run with its totals in parsing_runs,
+// for callers that do not open a run while parsing (see StartRun)
 func (ps *PostgresStore) LogParsingActivity(sourceWebsite string, status string, recordsProcessed, recordsInserted, recordsUpdated int, errorMessage *string) error {
-	// This method is kept for compatibility but should not be used for new logging
-	ps.logger.Info("LogParsingActivity is deprecated, individual business logs are used instead")
-	return nil
+	ctx := context.Background()
+	run, err := runs.Start(ctx, ps.db, sourceWebsite, "activity-"+runs.NewID())
+	if err != nil {
+		return err
+	}
+
+	run.Add(runs.Counts{Fetched: recordsProcessed, Inserted: recordsInserted, Updated: recordsUpdated})
+	if errorMessage != nil {
+		run.AddError(errors.New(*errorMessage))
+	}
+	return run.Finish(ctx, status)
 }
 
 // validateJSONFields validates JSON fields before database insertion
@@ -406,8 +410,8 @@ func (ps *PostgresStore) validateJSONFields(accommodation AccommodationRecord) e
 
 // convertBusinessDetailToAccommodation converts 2GIS BusinessDetail to accommodation record
 func (ps *PostgresStore) convertBusinessDetailToAccommodation(business domain.BusinessDetail) AccommodationRecord {
-	// Extract phone from attributes if available
-	phone := ps.extractPhoneFromAttributes(business.AttributeGroups)
+	// Extract phones, emails, websites and messengers from contact groups
+	contacts := ps.extractContacts(business.ContactGroups)
 
 	// Extract price range from attributes
 	priceMin, priceMax := ps.extractPriceRange(business.AttributeGroups)
@@ -422,22 +426,26 @@ func (ps *PostgresStore) convertBusinessDetailToAccommodation(business domain.Bu
 	reviewsJSON := ps.convertReviewsToJSON(business.Reviews)
 
 	// Determine accommodation type from rubrics
-	accommodationType := ps.determineAccommodationType(business.Rubrics)
+	categories := rubricAliases(business.Rubrics)
+	accommodationType := ps.mapAccommodationType("2gis", categories)
 
-	// Generate photos JSON (placeholder - would need actual photo URLs from API)
-	photosJSON := ps.generatePhotosJSON(business.Flags.Photos)
+	// Collect photo URLs from photo albums in external content
+	photosJSON := ps.generatePhotosJSON(business.ExternalContent)
 
-	return AccommodationRecord{
+	// Normalize weekly and special schedules into opening hours
+	openingHoursJSON := ps.convertScheduleToOpeningHours(business.Schedule, business.ScheduleSpecial)
+
+	accommodation := AccommodationRecord{
 		Name:               business.Name,
 		Latitude:           &business.Point.Lat,
 		Longitude:          &business.Point.Lon,
 		Address:            &business.FullAddressName,
 		AccommodationType:  &accommodationType,
-		Phone:              phone,
-		Email:              nil, // Not available in 2GIS API
-		SocialMediaLinks:   nil, // Not available in current API response
-		WebsiteURL:         nil, // Not available in current API response
-		SocialMediaPage:    nil, // Not available in current API response
+		Phone:              contacts.Phone,
+		Email:              contacts.Email,
+		SocialMediaLinks:   contacts.SocialMediaLinks,
+		WebsiteURL:         contacts.WebsiteURL,
+		SocialMediaPage:    contacts.SocialMediaPage,
 		ServiceDescription: ps.generateServiceDescription(business),
 		RoomCount:          nil, // Not available in 2GIS API
 		Capacity:           capacity,
@@ -452,15 +460,104 @@ func (ps *PostgresStore) convertBusinessDetailToAccommodation(business domain.Bu
 		SourceWebsite:      "2gis",
 		SourceURL:          nil, // Could be constructed from business ID
 		ExternalID:         business.ID,
+		OpeningHours:       openingHoursJSON,
+		SourceCategories:   ps.sourceCategoriesJSON(categories),
+		TaxonomyVersion:    ps.taxonomyVersion("2gis"),
+		DiscoveryMethod:    discoveryMethod(business.DiscoveryMethod),
+		SourceUpdatedAt:    ParseSourceUpdatedAt(business.Dates.UpdatedAt),
+	}
+	ps.locate(&accommodation)
+	return accommodation
+}
+
+// discoveryMethod returns how a 2GIS business was found, businesses without one come from the rubric crawl
+func discoveryMethod(method string) *string {
+	if method == "" {
+		method = domain.DiscoveryRubric
 	}
+	return &method
 }
 
 // Helper methods for conversion
 
-func (ps *PostgresStore) extractPhoneFromAttributes(attributeGroups []domain.AttributeGroup) *string {
-	// Phone numbers are typically not exposed in public 2GIS API
-	// This is a placeholder for when contact_groups become available
-	return nil
+// businessContacts holds contact data extracted from 2GIS contact groups
+type businessContacts struct {
+	Phone            *string
+	Email            *string
+	WebsiteURL       *string
+	SocialMediaPage  *string
+	SocialMediaLinks []byte
+}
+
+// extractContacts collects and normalizes phones, emails, websites and social network profiles
+// from contact groups. The first phone, email and website fill the scalar columns, all values are
+// kept in social_media_links together with the social network profiles.
+func (ps *PostgresStore) extractContacts(contactGroups []domain.ContactGroup) businessContacts {
+	var result businessContacts
+	var contacts normalize.Contacts
+
+	for _, group := range contactGroups {
+		for _, contact := range group.Contacts {
+			value := ps.sanitizeString(contact.Value)
+			if value == "" {
+				continue
+			}
+
+			switch contact.Type {
+			case "phone":
+				contacts.AddPhones(value)
+			case "email":
+				contacts.AddEmail(value)
+			case "website":
+				contacts.AddWebsite(ps.contactURL(contact))
+			default:
+				// Messengers and social networks, other contact types are skipped. The value,
+				// e.g. the phone number of a messenger, is used when the link is unusable.
+				contacts.AddSocial(contact.Type, ps.contactURL(contact))
+				contacts.AddSocial(contact.Type, value)
+			}
+		}
+	}
+
+	// Column sizes come from the accommodations table
+	result.Phone = ps.safeStringPointer(contacts.Phone())
+	result.Email = ps.safeStringPointer(contacts.Email(100))
+	result.WebsiteURL = ps.safeStringPointer(contacts.Website())
+	result.SocialMediaPage = ps.safeStringPointer(contacts.SocialPage())
+	result.SocialMediaLinks = ps.contactLinksJSON(&contacts)
+	return result
+}
+
+// contactLinksJSON returns the social_media_links JSON of normalized contacts, nil without any
+func (ps *PostgresStore) contactLinksJSON(contacts *normalize.Contacts) []byte {
+	links := contacts.Links()
+	if links == nil {
+		return nil
+	}
+	jsonData, err := json.Marshal(links)
+	if err != nil {
+		ps.logger.Error("Failed to marshal social media links JSON: %v", err)
+		return nil
+	}
+	return jsonData
+}
+
+// contactURL returns the best link for a contact. 2GIS wraps websites in link.2gis.ru
+// redirects, in which case the displayed text holds the real address.
+func (ps *PostgresStore) contactURL(contact domain.Contact) string {
+	link := ps.sanitizeString(contact.URL)
+	if link == "" {
+		link = ps.sanitizeString(contact.Value)
+	}
+
+	text := ps.sanitizeString(contact.Text)
+	if strings.Contains(link, "link.2gis.") && text != "" {
+		if strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://") {
+			return text
+		}
+		return "http://" + text
+	}
+	return link
 }
 
 func (ps *PostgresStore) extractPriceRange(attributeGroups []domain.AttributeGroup) (*float64, *float64) {
@@ -502,26 +599,20 @@ func (ps *PostgresStore) extractCapacity(attributeGroups []domain.AttributeGroup
 	return nil
 }
 
-// convertAttributesToAmenities converts attribute groups to amenities JSON
+// convertAttributesToAmenities converts attribute groups to amenities JSON using the reviewed tag mapping
 func (ps *PostgresStore) convertAttributesToAmenities(attributeGroups []domain.AttributeGroup) []byte {
 	amenities := make(map[string]bool)
 
 	for _, group := range attributeGroups {
 		for _, attr := range group.Attributes {
-			cleanName := ps.sanitizeString(attr.Name)
-			switch {
-			case strings.Contains(strings.ToLower(cleanName), "завтрак"):
-				amenities["breakfast"] = true
-			case strings.Contains(strings.ToLower(cleanName), "кафе"):
-				amenities["restaurant"] = true
-			case strings.Contains(strings.ToLower(cleanName), "беседки"):
-				amenities["gazebo"] = true
-			case strings.Contains(strings.ToLower(cleanName), "детская площадка"):
-				amenities["playground"] = true
-			case strings.Contains(strings.ToLower(cleanName), "наличный"):
-				amenities["cash_payment"] = true
-			case strings.Contains(strings.ToLower(cleanName), "qr"):
-				amenities["qr_payment"] = true
+			key, reviewed := ps.amenities.Map(attr.Tag, ps.sanitizeString(attr.Name))
+			if !reviewed && attr.Tag != "" {
+				ps.unmappedMu.Lock()
+				ps.unmapped[attr.Tag]++
+				ps.unmappedMu.Unlock()
+			}
+			if key != "" {
+				amenities[key] = true
 			}
 		}
 	}
@@ -538,6 +629,23 @@ func (ps *PostgresStore) convertAttributesToAmenities(attributeGroups []domain.A
 	return jsonData
 }
 
+// AmenityMapping returns the attribute tag to amenity key mapping used for 2GIS records
+func (ps *PostgresStore) AmenityMapping() *amenity.Mapping {
+	return ps.amenities
+}
+
+// UnmappedAttributeTags returns the attribute tags stored since startup that have no reviewed mapping
+func (ps *PostgresStore) UnmappedAttributeTags() map[string]int {
+	ps.unmappedMu.Lock()
+	defer ps.unmappedMu.Unlock()
+
+	tags := make(map[string]int, len(ps.unmapped))
+	for tag, count := range ps.unmapped {
+		tags[tag] = count
+	}
+	return tags
+}
+
 // convertReviewsToJSON converts review info to JSON with proper error handling
 func (ps *PostgresStore) convertReviewsToJSON(reviewInfo domain.ReviewInfo) []byte {
 	// Create a safe map avoiding potential NaN or Inf values
@@ -574,14 +682,45 @@ func (ps *PostgresStore) sanitizeFloat(f float64) float64 {
 	return f
 }
 
-// generatePhotosJSON creates photos JSON with proper error handling
-func (ps *PostgresStore) generatePhotosJSON(hasPhotos bool) []byte {
-	if !hasPhotos {
+// photoSizePattern matches the size suffix of 2GIS photo URLs, e.g. "_656x340.jpg"
+var photoSizePattern = regexp.MustCompile(`_(\d+)x(\d+)\.[a-zA-Z]+$`)
+
+// generatePhotosJSON creates photos JSON from photo albums with proper error handling
+func (ps *PostgresStore) generatePhotosJSON(externalContent []domain.ExternalContent) []byte {
+	var photos []domain.Photo
+	seen := make(map[string]bool)
+
+	addPhoto := func(url string, width, height int) {
+		url = ps.sanitizeString(url)
+		if url == "" || seen[url] {
+			return
+		}
+		seen[url] = true
+
+		// Fall back to the size encoded in the URL when the API does not report it
+		if width == 0 || height == 0 {
+			if match := photoSizePattern.FindStringSubmatch(url); match != nil {
+				width, _ = strconv.Atoi(match[1])
+				height, _ = strconv.Atoi(match[2])
+			}
+		}
+		photos = append(photos, domain.Photo{URL: url, Width: width, Height: height, Source: "2gis"})
+	}
+
+	for _, content := range externalContent {
+		if content.Type != "photo_album" {
+			continue
+		}
+		addPhoto(content.MainPhotoURL, 0, 0)
+		for _, item := range content.Items {
+			addPhoto(item.URL, item.Width, item.Height)
+		}
+	}
+
+	if len(photos) == 0 {
 		return nil
 	}
 
-	// Create empty array instead of placeholder to avoid issues
-	photos := []string{}
 	jsonData, err := json.Marshal(photos)
 	if err != nil {
 		ps.logger.Error("Failed to marshal photos JSON: %v", err)
@@ -590,6 +729,21 @@ func (ps *PostgresStore) generatePhotosJSON(hasPhotos bool) []byte {
 	return jsonData
 }
 
+// convertScheduleToOpeningHours creates opening hours JSON from 2GIS schedules
+func (ps *PostgresStore) convertScheduleToOpeningHours(schedule domain.Schedule, scheduleSpecial json.RawMessage) []byte {
+	openingHours := domain.NewOpeningHours(schedule, scheduleSpecial)
+	if openingHours == nil {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(openingHours)
+	if err != nil {
+		ps.logger.Error("Failed to marshal opening hours JSON: %v", err)
+		return nil
+	}
+	return jsonData
+}
+
 // generateServiceDescription creates service description with safe string handling
 func (ps *PostgresStore) generateServiceDescription(business domain.BusinessDetail) *string {
 	var descriptions []string
@@ -706,25 +860,20 @@ func (ps *PostgresStore) debugLogJSONFields(businessID string, accommodation Acc
 	}
 }
 
-// determineAccommodationType determines the accommodation type from rubrics
-func (ps *PostgresStore) determineAccommodationType(rubrics []domain.Rubric) string {
+// AccommodationType returns the canonical accommodation type of a 2GIS business
+func (ps *PostgresStore) AccommodationType(rubrics []domain.Rubric) string {
+	return ps.mapAccommodationType("2gis", rubricAliases(rubrics))
+}
+
+// rubricAliases returns the rubric aliases in API order, the primary rubric comes first
+func rubricAliases(rubrics []domain.Rubric) []string {
+	aliases := make([]string, 0, len(rubrics))
 	for _, rubric := range rubrics {
-		switch rubric.Alias {
-		case "gostinicy":
-			return "hotel"
-		case "khostely":
-			return "hostel"
-		case "bazy_otdykha":
-			return "resort"
-		case "arenda_kottedzhejj":
-			return "villa"
-		case "kempingi":
-			return "camping"
-		case "sanatorii":
-			return "resort"
+		if rubric.Alias != "" {
+			aliases = append(aliases, rubric.Alias)
 		}
 	}
-	return "other"
+	return aliases
 }
 
 // convertRating converts and sanitizes rating values
@@ -762,25 +911,34 @@ type AccommodationRecord struct {
 	SourceWebsite      string
 	SourceURL          *string
 	ExternalID         string
+	OpeningHours       []byte
+	SourceCategories   []byte // raw source categories the accommodation type was mapped from
+	TaxonomyVersion    *int
+	DiscoveryMethod    *string    // how a 2GIS business was found: rubric or keyword
+	SourceUpdatedAt    *time.Time // when the source last changed the record, 2GIS dates.updated_at
+	Region             *string    // located from the coordinates, see locate
+	City               *string
+	RegionKATO         *string
 }
 
 // getExistingAccommodation retrieves existing accommodation data from database
-func (ps *PostgresStore) getExistingAccommodation(sourceWebsite, externalID string) (*AccommodationRecord, error) {
+func (ps *PostgresStore) getExistingAccommodation(ctx context.Context, sourceWebsite, externalID string) (*AccommodationRecord, error) {
 	query := `
 		SELECT 
 			name, latitude, longitude, address, accommodation_type,
 			phone, email, social_media_links, website_url, social_media_page,
 			service_description, room_count, capacity, price_range_min, price_range_max,
 			photos, rating, review_count, reviews, amenities,
-			verification_status, source_website, source_url, external_id
+			verification_status, source_website, source_url, external_id, opening_hours,
+			source_categories, taxonomy_version, discovery_method, source_updated_at
 		FROM accommodations 
 		WHERE source_website = $1 AND external_id = $2
 	`
 
 	var record AccommodationRecord
-	var socialMediaLinks, photos, reviews, amenities sql.NullString
+	var socialMediaLinks, photos, reviews, amenities, openingHours, sourceCategories sql.NullString
 
-	err := ps.db.QueryRow(query, sourceWebsite, externalID).Scan(
+	err := ps.db.QueryRowContext(ctx, query, sourceWebsite, externalID).Scan(
 		&record.Name,
 		&record.Latitude,
 		&record.Longitude,
@@ -805,6 +963,11 @@ func (ps *PostgresStore) getExistingAccommodation(sourceWebsite, externalID stri
 		&record.SourceWebsite,
 		&record.SourceURL,
 		&record.ExternalID,
+		&openingHours,
+		&sourceCategories,
+		&record.TaxonomyVersion,
+		&record.DiscoveryMethod,
+		&record.SourceUpdatedAt,
 	)
 
 	if err != nil {
@@ -827,10 +990,38 @@ func (ps *PostgresStore) getExistingAccommodation(sourceWebsite, externalID stri
 	if amenities.Valid {
 		record.Amenities = []byte(amenities.String)
 	}
+	if openingHours.Valid {
+		record.OpeningHours = []byte(openingHours.String)
+	}
+	if sourceCategories.Valid {
+		record.SourceCategories = []byte(sourceCategories.String)
+	}
 
 	return &record, nil
 }
 
+// ExternalIDs returns the external IDs of every accommodation stored for a source
+func (ps *PostgresStore) ExternalIDs(ctx context.Context, sourceWebsite string) (map[string]bool, error) {
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT external_id FROM accommodations
+		WHERE source_website = $1 AND external_id IS NOT NULL
+	`, sourceWebsite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load external IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan external ID: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
 // accommodationsEqual compares two accommodation records to check if they're different
 func (ps *PostgresStore) accommodationsEqual(existing, new *AccommodationRecord) bool {
 	// Compare basic fields
@@ -859,10 +1050,17 @@ func (ps *PostgresStore) accommodationsEqual(existing, new *AccommodationRecord)
 		return false
 	}
 
+	// A rubric discovery is never downgraded, see the discovery_method upsert
+	if !ps.stringPtrsEqual(existing.DiscoveryMethod, new.DiscoveryMethod) &&
+		!(existing.DiscoveryMethod != nil && *existing.DiscoveryMethod == domain.DiscoveryRubric) {
+		return false
+	}
+
 	// Compare nullable int fields
 	if !ps.intPtrsEqual(existing.RoomCount, new.RoomCount) ||
 		!ps.intPtrsEqual(existing.Capacity, new.Capacity) ||
-		!ps.intPtrsEqual(existing.ReviewCount, new.ReviewCount) {
+		!ps.intPtrsEqual(existing.ReviewCount, new.ReviewCount) ||
+		!ps.intPtrsEqual(existing.TaxonomyVersion, new.TaxonomyVersion) {
 		return false
 	}
 
@@ -870,7 +1068,13 @@ func (ps *PostgresStore) accommodationsEqual(existing, new *AccommodationRecord)
 	if !ps.jsonBytesEqual(existing.SocialMediaLinks, new.SocialMediaLinks) ||
 		!ps.jsonBytesEqual(existing.Photos, new.Photos) ||
 		!ps.jsonBytesEqual(existing.Reviews, new.Reviews) ||
-		!ps.jsonBytesEqual(existing.Amenities, new.Amenities) {
+		!ps.jsonBytesEqual(existing.Amenities, new.Amenities) ||
+		!ps.jsonBytesEqual(existing.OpeningHours, new.OpeningHours) ||
+		!ps.jsonBytesEqual(existing.SourceCategories, new.SourceCategories) {
+		return false
+	}
+
+	if !timePtrsEqual(existing.SourceUpdatedAt, new.SourceUpdatedAt) {
 		return false
 	}
 
@@ -963,7 +1167,11 @@ func getEnv(key, defaultValue string) string {
 }
 
 // InsertBookingProperty inserts a DetailedProperty from Booking.com into accommodations table
-func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
+func (ps *PostgresStore) InsertBookingProperty(ctx context.Context, property BookingProperty) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx = writeContext(ctx)
 	startTime := time.Now()
 
 	ps.logger.Debug("Processing booking property: %s, Address: %s", property.PropertyName, property.Address)
@@ -977,15 +1185,15 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 	// Validate JSON data before insertion
 	if err := ps.validateJSONFields(accommodation); err != nil {
 		ps.logger.Error("Invalid JSON data for booking property %s: %v", property.PageName, err)
-		ps.logBusinessInsertion("booking", property.PageName, "insert", "failed", fmt.Sprintf("Invalid JSON data: %v", err), startTime)
+		ps.logBusinessInsertion(ctx, "booking", property.PageName, "insert", "failed", fmt.Sprintf("Invalid JSON data: %v", err), startTime)
 		return fmt.Errorf("invalid JSON data: %w", err)
 	}
 
 	// Check if accommodation already exists
-	existingAccommodation, err := ps.getExistingAccommodation(accommodation.SourceWebsite, accommodation.ExternalID)
+	existingAccommodation, err := ps.getExistingAccommodation(ctx, accommodation.SourceWebsite, accommodation.ExternalID)
 	if err != nil {
 		ps.logger.Error("Failed to check existing accommodation for booking property %s: %v", property.PageName, err)
-		ps.logBusinessInsertion("booking", property.PageName, "check", "failed", fmt.Sprintf("Failed to check existing record: %v", err), startTime)
+		ps.logBusinessInsertion(ctx, "booking", property.PageName, "check", "failed", fmt.Sprintf("Failed to check existing record: %v", err), startTime)
 		return fmt.Errorf("failed to check existing accommodation: %w", err)
 	}
 
@@ -993,7 +1201,7 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 	if existingAccommodation != nil {
 		if ps.accommodationsEqual(existingAccommodation, &accommodation) {
 			ps.logger.Debug("No changes detected for accommodation: %s (PageName: %s), skipping update", property.PropertyName, property.PageName)
-			ps.logBusinessInsertion("booking", property.PageName, "skip", "success", "No changes detected", startTime)
+			ps.logBusinessInsertion(ctx, "booking", property.PageName, "skip", "success", "No changes detected", startTime)
 			return nil
 		}
 		ps.logger.Debug("Changes detected for accommodation: %s (PageName: %s), proceeding with update", property.PropertyName, property.PageName)
@@ -1006,13 +1214,17 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 			phone, email, social_media_links, website_url, social_media_page,
 			service_description, room_count, capacity, price_range_min, price_range_max,
 			photos, rating, review_count, reviews, amenities,
-			verification_status, source_website, source_url, external_id
+			verification_status, source_website, source_url, external_id,
+			source_categories, taxonomy_version, last_run_id, region, city,
+			region_kato
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15,
 			$16, $17, $18, $19, $20,
-			$21, $22, $23, $24
+			$21, $22, $23, $24,
+			$25, $26, $27, $28, $29,
+			$30
 		)
 		ON CONFLICT (source_website, external_id) 
 		DO UPDATE SET
@@ -1037,12 +1249,18 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 			reviews = EXCLUDED.reviews,
 			amenities = EXCLUDED.amenities,
 			verification_status = EXCLUDED.verification_status,
+			source_categories = EXCLUDED.source_categories,
+			taxonomy_version = EXCLUDED.taxonomy_version,
+			last_run_id = EXCLUDED.last_run_id,
+			region = EXCLUDED.region,
+			city = EXCLUDED.city,
+			region_kato = EXCLUDED.region_kato,
 			last_updated = CURRENT_TIMESTAMP
 		RETURNING (xmax = 0) AS was_insert
 	`
 
 	var wasInsert bool
-	err = ps.db.QueryRow(query,
+	err = ps.db.QueryRowContext(ctx, query,
 		accommodation.Name,
 		accommodation.Latitude,
 		accommodation.Longitude,
@@ -1067,12 +1285,18 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 		accommodation.SourceWebsite,
 		accommodation.SourceURL,
 		accommodation.ExternalID,
+		ps.safeJSONBytes(accommodation.SourceCategories),
+		accommodation.TaxonomyVersion,
+		runIDFrom(ctx),
+		accommodation.Region,
+		accommodation.City,
+		accommodation.RegionKATO,
 	).Scan(&wasInsert)
 
 	if err != nil {
 		ps.logger.Error("Failed to insert/update booking property %s: %v", property.PageName, err)
 		operation := "insert" // Default to insert for error logging
-		ps.logBusinessInsertion("booking", property.PageName, operation, "failed", fmt.Sprintf("Database error: %v", err), startTime)
+		ps.logBusinessInsertion(ctx, "booking", property.PageName, operation, "failed", fmt.Sprintf("Database error: %v", err), startTime)
 		return fmt.Errorf("failed to insert/update booking property: %w", err)
 	}
 
@@ -1082,12 +1306,12 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 	}
 
 	ps.logger.Info("Successfully %sed accommodation: %s (PageName: %s)", operation, property.PropertyName, property.PageName)
-	ps.logBusinessInsertion("booking", property.PageName, operation, "success", "", startTime)
+	ps.logBusinessInsertion(ctx, "booking", property.PageName, operation, "success", "", startTime)
 	return nil
 }
 
 // InsertBookingProperties inserts multiple BookingProperty entities in parallel
-func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) error {
+func (ps *PostgresStore) InsertBookingProperties(ctx context.Context, properties []BookingProperty) error {
 	if len(properties) == 0 {
 		return nil
 	}
@@ -1096,13 +1320,15 @@ func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) e
 	successCount := 0
 	errorCount := 0
 
+	metrics.AddQueued("booking", len(properties))
+
 	// Create channels for work distribution
 	propertyChan := make(chan BookingProperty, len(properties))
 	resultChan := make(chan bookingInsertResult, len(properties))
 
 	// Start worker goroutines
 	for i := 0; i < maxWorkers; i++ {
-		go ps.insertBookingWorker(propertyChan, resultChan)
+		go ps.insertBookingWorker(ctx, propertyChan, resultChan)
 	}
 
 	// Send properties to workers
@@ -1114,6 +1340,7 @@ func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) e
 	// Collect results
 	for i := 0; i < len(properties); i++ {
 		result := <-resultChan
+		metrics.AddQueued("booking", -1)
 		if result.err != nil {
 			errorCount++
 		} else {
@@ -1124,6 +1351,12 @@ func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) e
 	ps.logger.Info("Booking parallel processing completed: %d successful, %d failed out of %d total",
 		successCount, errorCount, len(properties))
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d booking properties failed to store", errorCount, len(properties))
+	}
 	return nil
 }
 
@@ -1134,9 +1367,9 @@ type bookingInsertResult struct {
 }
 
 // insertBookingWorker processes booking properties from the channel
-func (ps *PostgresStore) insertBookingWorker(propertyChan <-chan BookingProperty, resultChan chan<- bookingInsertResult) {
+func (ps *PostgresStore) insertBookingWorker(ctx context.Context, propertyChan <-chan BookingProperty, resultChan chan<- bookingInsertResult) {
 	for property := range propertyChan {
-		err := ps.InsertBookingProperty(property)
+		err := ps.InsertBookingProperty(ctx, property)
 		resultChan <- bookingInsertResult{
 			propertyPageName: property.PageName,
 			err:              err,
@@ -1155,6 +1388,10 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 	// Convert facilities to amenities JSON
 	amenitiesJSON := ps.convertBookingFacilitiesToAmenities(property.Facilities)
 
+	// Map the Booking.com property type to a canonical accommodation type
+	categories := []string{property.AccommodationType}
+	accommodationType := ps.mapAccommodationType("booking", categories)
+
 	// Extract rating from reviews_ratings string
 	rating := ps.parseBookingRating(property.ReviewsRatings)
 
@@ -1165,15 +1402,19 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 		websiteURL = &url
 	}
 
-	return AccommodationRecord{
+	// Booking.com has no contact fields, hosts sometimes leave a phone or email in the description
+	var contacts normalize.Contacts
+	contacts.AddText(property.Description)
+
+	accommodation := AccommodationRecord{
 		Name:               property.PropertyName,
 		Latitude:           ps.safeFloat64Pointer(property.Latitude),
 		Longitude:          ps.safeFloat64Pointer(property.Longitude),
 		Address:            ps.safeStringPointer(property.Address),
-		AccommodationType:  ps.safeStringPointer(property.AccommodationType),
-		Phone:              nil, // Not available in booking.com data
-		Email:              nil, // Not available in booking.com data
-		SocialMediaLinks:   nil, // Not available in booking.com data
+		AccommodationType:  &accommodationType,
+		Phone:              ps.safeStringPointer(contacts.Phone()),
+		Email:              ps.safeStringPointer(contacts.Email(100)),
+		SocialMediaLinks:   ps.contactLinksJSON(&contacts),
 		WebsiteURL:         websiteURL,
 		SocialMediaPage:    nil, // Not available in booking.com data
 		ServiceDescription: ps.safeStringPointer(property.Description),
@@ -1190,7 +1431,11 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 		SourceWebsite:      "booking",
 		SourceURL:          websiteURL,
 		ExternalID:         property.PageName, // Use PageName as unique identifier
+		SourceCategories:   ps.sourceCategoriesJSON(categories),
+		TaxonomyVersion:    ps.taxonomyVersion("booking"),
 	}
+	ps.locate(&accommodation)
+	return accommodation
 }
 
 // Helper methods for booking.com conversion
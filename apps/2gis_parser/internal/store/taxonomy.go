@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RemapSummary reports the outcome of re-mapping accommodation types of a source
+type RemapSummary struct {
+	Version int
+	Checked int // rows mapped with an older version, or all rows when forced
+	Changed int // rows whose accommodation type changed
+	Updated int // rows written, including version-only updates
+}
+
+// mapAccommodationType maps raw source categories to a canonical accommodation type
+func (ps *PostgresStore) mapAccommodationType(sourceWebsite string, categories []string) string {
+	mapping, ok := ps.types[sourceWebsite]
+	if !ok {
+		ps.logger.Error("No accommodation type mapping loaded for source %s", sourceWebsite)
+		return "other"
+	}
+	return mapping.Map(categories...)
+}
+
+// taxonomyVersion returns the version of the mapping used for a source
+func (ps *PostgresStore) taxonomyVersion(sourceWebsite string) *int {
+	mapping, ok := ps.types[sourceWebsite]
+	if !ok {
+		return nil
+	}
+	version := mapping.Version
+	return &version
+}
+
+// sourceCategoriesJSON stores the raw categories so rows can be re-mapped later
+func (ps *PostgresStore) sourceCategoriesJSON(categories []string) []byte {
+	var cleaned []string
+	for _, category := range categories {
+		if category = strings.TrimSpace(category); category != "" {
+			cleaned = append(cleaned, category)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(cleaned)
+	if err != nil {
+		ps.logger.Error("Failed to marshal source categories JSON: %v", err)
+		return nil
+	}
+	return jsonData
+}
+
+// RemapAccommodationTypes re-maps accommodation types of a source with the currently loaded mapping.
+// Rows already mapped with the current version are skipped unless force is set. Rows written
+// before source categories were stored are re-mapped from their current accommodation type.
+func (ps *PostgresStore) RemapAccommodationTypes(ctx context.Context, sourceWebsite string, force bool) (RemapSummary, error) {
+	mapping, ok := ps.types[sourceWebsite]
+	if !ok {
+		return RemapSummary{}, fmt.Errorf("no accommodation type mapping loaded for source %s", sourceWebsite)
+	}
+	summary := RemapSummary{Version: mapping.Version}
+
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT id, accommodation_type, source_categories
+		FROM accommodations
+		WHERE source_website = $1
+		  AND ($2 OR taxonomy_version IS DISTINCT FROM $3)
+	`, sourceWebsite, force, mapping.Version)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query accommodations to re-map: %w", err)
+	}
+
+	type remap struct {
+		id                int
+		accommodationType string
+	}
+	var updates []remap
+
+	for rows.Next() {
+		var id int
+		var currentType, sourceCategories sql.NullString
+		if err := rows.Scan(&id, &currentType, &sourceCategories); err != nil {
+			rows.Close()
+			return summary, fmt.Errorf("failed to scan accommodation to re-map: %w", err)
+		}
+		summary.Checked++
+
+		var categories []string
+		if sourceCategories.Valid {
+			if err := json.Unmarshal([]byte(sourceCategories.String), &categories); err != nil {
+				ps.logger.Error("Invalid source categories for accommodation %d: %v", id, err)
+			}
+		}
+		if len(categories) == 0 && currentType.Valid {
+			categories = []string{currentType.String}
+		}
+
+		accommodationType := mapping.Map(categories...)
+		if !currentType.Valid || currentType.String != accommodationType {
+			summary.Changed++
+		}
+		updates = append(updates, remap{id: id, accommodationType: accommodationType})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return summary, fmt.Errorf("failed to read accommodations to re-map: %w", err)
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, fmt.Errorf("failed to begin re-map transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, update := range updates {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE accommodations
+			SET accommodation_type = $1, taxonomy_version = $2
+			WHERE id = $3 AND (accommodation_type IS DISTINCT FROM $1 OR taxonomy_version IS DISTINCT FROM $2)
+		`, update.accommodationType, mapping.Version, update.id)
+		if err != nil {
+			return summary, fmt.Errorf("failed to re-map accommodation %d: %w", update.id, err)
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			summary.Updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, fmt.Errorf("failed to commit re-map transaction: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"2gis-parser/internal/amenity"
+	"2gis-parser/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// AttributeOptions controls the attribute catalog collection
+type AttributeOptions struct {
+	RunOptions
+	CatalogFile string // JSON catalog of every attribute tag, name and group
+	MappingFile string // draft amenity mapping to review and copy into internal/amenity/mappings
+}
+
+// CollectAttributes walks businesses of the selected regions and rubrics without storing them,
+// builds a catalog of their attributes and writes a draft amenity mapping extended with every
+// tag that has no reviewed mapping yet. Unmapped tags are reported at the end of the run.
+func (p *Parser) CollectAttributes(ctx context.Context, opts AttributeOptions, mapping *amenity.Mapping) (*domain.AttributeCatalog, error) {
+	regions, err := p.resolveRegions(ctx, opts.RegionIDs)
+	if err != nil {
+		return nil, err
+	}
+	rubrics, err := p.resolveRubrics(opts.RunOptions)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.Info("Collecting attributes from %d rubrics in %d regions", len(rubrics), len(regions))
+
+	catalog := domain.NewAttributeCatalog()
+	onPage := func(page int, businesses []domain.BusinessDetail) error {
+		for _, business := range businesses {
+			catalog.Add(business)
+		}
+		return nil
+	}
+
+	for _, region := range regions {
+		for _, rubricID := range rubrics {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			if opts.Tiling {
+				_, err = p.provider.FetchBusinessesTiled(ctx, rubricID, region.ID, true, onPage)
+			} else {
+				_, err = p.provider.FetchBusinesses(ctx, rubricID, region.ID, true, 1, onPage)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Attributes of the pages fetched so far are kept
+				p.logger.Error("Failed to fetch rubric %s in region %s: %v", rubricID, region.ID, err)
+			}
+		}
+		p.logger.Info("Collected attributes of %d businesses after region %s", catalog.Businesses, region.Name)
+	}
+
+	for _, entry := range catalog.Entries {
+		entry.Amenity, entry.Reviewed = mapping.Map(entry.Tag, entry.Name)
+	}
+	catalog.Sort()
+	catalog.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if err := saveAttributeCatalog(catalog, opts.CatalogFile); err != nil {
+		return nil, err
+	}
+	p.logger.Info("Saved attribute catalog with %d entries to %s", len(catalog.Entries), opts.CatalogFile)
+
+	draft := mapping.Draft(catalog.Tags())
+	if err := draft.Save(opts.MappingFile); err != nil {
+		return nil, err
+	}
+	p.logger.Info("Saved draft amenity mapping version %d to %s, %d tags need review",
+		draft.Version, opts.MappingFile, len(draft.Review))
+
+	p.reportUnmappedAttributes(catalog)
+	return catalog, nil
+}
+
+// reportUnmappedAttributes logs catalog tags without a reviewed mapping, most frequent first
+func (p *Parser) reportUnmappedAttributes(catalog *domain.AttributeCatalog) {
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for _, entry := range catalog.Entries {
+		if entry.Reviewed || entry.Tag == "" {
+			continue
+		}
+		counts[entry.Tag] += entry.Count
+		if names[entry.Tag] == "" {
+			names[entry.Tag] = entry.Name
+		}
+	}
+
+	if len(counts) == 0 {
+		p.logger.Info("All attribute tags have a reviewed amenity mapping")
+		return
+	}
+
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+
+	p.logger.Info("=== UNMAPPED ATTRIBUTE TAGS (%d) ===", len(tags))
+	for _, tag := range tags {
+		p.logger.Info("  - %s (%s): %d businesses", tag, names[tag], counts[tag])
+	}
+}
+
+func saveAttributeCatalog(catalog *domain.AttributeCatalog, filename string) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode attribute catalog: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write attribute catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"2gis-parser/internal/domain"
+	"2gis-parser/internal/store"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// sweepRubric re-checks businesses that a complete crawl of a region and rubric no longer lists.
+// Businesses still found by ID are kept and dropped from the rubric listing, businesses the API
+// no longer knows get a miss and are soft-deleted after threshold misses in a row. Each business
+// is re-checked at most once per run, even when it vanished from several rubrics.
+func (p *Parser) sweepRubric(ctx context.Context, region domain.Region, checkpoint store.CrawlCheckpoint, threshold int) error {
+	if checkpoint.FetchedCount == 0 {
+		// An empty listing is more likely an API problem than every business closing at once
+		p.logger.Info("Rubric %s in %s listed no businesses, skipping closure sweep", checkpoint.RubricID, region.Name)
+		return nil
+	}
+
+	unseen, err := p.store.UnseenListings(ctx, sourceWebsite, checkpoint.RunID, region.ID, checkpoint.RubricID)
+	if err != nil {
+		return err
+	}
+	if len(unseen) == 0 {
+		return nil
+	}
+	p.logger.Info("%d businesses of rubric %s in %s were not listed in run %s, re-checking them",
+		len(unseen), checkpoint.RubricID, region.Name, checkpoint.RunID)
+
+	var found, missed, deleted int
+	for _, id := range unseen {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		stillOpen, checked := p.rechecked[id]
+		if !checked {
+			_, err := p.provider.FetchBusinessDetail(ctx, id)
+			switch {
+			case err == nil:
+				stillOpen = true
+				reason := fmt.Sprintf("found by ID after not being listed in rubric %s of region %s (run %s)",
+					checkpoint.RubricID, region.ID, checkpoint.RunID)
+				if err := p.store.MarkFound(ctx, sourceWebsite, []string{id}, reason); err != nil {
+					return err
+				}
+			case errors.Is(err, domain.ErrBusinessNotFound):
+				stillOpen = false
+				reason := fmt.Sprintf("not listed in rubric %s of region %s and not found by ID (run %s)",
+					checkpoint.RubricID, region.ID, checkpoint.RunID)
+				action, err := p.store.RecordMiss(ctx, sourceWebsite, id, threshold, reason)
+				if err != nil {
+					return err
+				}
+				missed++
+				if action == store.ClosureDeleted {
+					deleted++
+					p.logger.Info("Soft-deleted business %s after %d consecutive misses", id, threshold)
+				}
+			default:
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Transient errors say nothing about the business, it is re-checked by the next sweep
+				p.logger.Error("Failed to re-check business %s: %v", id, err)
+				continue
+			}
+			p.rechecked[id] = stillOpen
+		}
+
+		if stillOpen {
+			found++
+			if err := p.store.ForgetSighting(ctx, sourceWebsite, region.ID, checkpoint.RubricID, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.logger.Info("Closure sweep of rubric %s in %s: %d still open, %d missed, %d soft-deleted",
+		checkpoint.RubricID, region.Name, found, missed, deleted)
+	return nil
+}
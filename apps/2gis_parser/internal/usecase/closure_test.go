@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"2gis-parser/internal/domain"
+	"2gis-parser/internal/store"
+	"context"
+	"fmt"
+	"testing"
+)
+
+const (
+	transientID = "70000001023456789" // not recorded, the lookup fails without an answer
+	openID      = "70000001023456790" // still found by ID
+	closedID    = "70000001023456791" // unknown to the API
+)
+
+func newSweepParser(t *testing.T, dbStore *fakeStore) *Parser {
+	t.Helper()
+	parser := newReplayParser(t, dbStore)
+	parser.rechecked = make(map[string]bool)
+	return parser
+}
+
+func TestSweepRubric(t *testing.T) {
+	tests := []struct {
+		name          string
+		unseen        []string
+		priorMisses   int // consecutive misses closedID already has
+		fetched       int
+		wantFound     []string
+		wantForgotten []string
+		wantMisses    []string
+		wantDeleted   []string
+	}{
+		{name: "still found by ID", unseen: []string{openID}, fetched: 2, wantFound: []string{openID}, wantForgotten: []string{openID}},
+		{name: "miss below the threshold", unseen: []string{closedID}, fetched: 2, wantMisses: []string{closedID}},
+		{name: "soft-deleted at the threshold", unseen: []string{closedID}, priorMisses: 2, fetched: 2, wantMisses: []string{closedID}, wantDeleted: []string{closedID}},
+		{name: "transient errors are skipped", unseen: []string{transientID}, fetched: 2},
+		{name: "empty listing is not swept", unseen: []string{openID, closedID}, fetched: 0},
+		{
+			name:   "mixed",
+			unseen: []string{transientID, openID, closedID}, priorMisses: 2, fetched: 2,
+			wantFound: []string{openID}, wantForgotten: []string{openID}, wantMisses: []string{closedID}, wantDeleted: []string{closedID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbStore := newFakeStore()
+			dbStore.unseen = tt.unseen
+			dbStore.missCount[closedID] = tt.priorMisses
+			parser := newSweepParser(t, dbStore)
+
+			checkpoint := store.CrawlCheckpoint{RunID: "run", RegionID: "67", RubricID: "269", Completed: true, FetchedCount: tt.fetched}
+			if err := parser.sweepRubric(context.Background(), domain.Region{ID: "67", Name: "Алматы"}, checkpoint, 3); err != nil {
+				t.Fatalf("sweepRubric() = %v", err)
+			}
+
+			for _, got := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"found", dbStore.found, tt.wantFound},
+				{"forgotten", dbStore.forgotten, tt.wantForgotten},
+				{"misses", dbStore.misses, tt.wantMisses},
+				{"deleted", dbStore.deleted, tt.wantDeleted},
+			} {
+				if fmt.Sprint(got.got) != fmt.Sprint(got.want) {
+					t.Errorf("%s = %v, want %v", got.name, got.got, got.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSweepRubricRechecksOncePerRun(t *testing.T) {
+	dbStore := newFakeStore()
+	dbStore.unseen = []string{transientID, openID, closedID}
+	parser := newSweepParser(t, dbStore)
+	region := domain.Region{ID: "67", Name: "Алматы"}
+
+	// The same businesses vanished from two rubrics of the region
+	for _, rubricID := range []string{"269", "547"} {
+		checkpoint := store.CrawlCheckpoint{RunID: "run", RegionID: "67", RubricID: rubricID, Completed: true, FetchedCount: 2}
+		if err := parser.sweepRubric(context.Background(), region, checkpoint, 3); err != nil {
+			t.Fatalf("sweepRubric(%s) = %v", rubricID, err)
+		}
+	}
+
+	if fmt.Sprint(dbStore.found) != fmt.Sprint([]string{openID}) || fmt.Sprint(dbStore.misses) != fmt.Sprint([]string{closedID}) {
+		t.Fatalf("found %v and misses %v, want each business re-checked once", dbStore.found, dbStore.misses)
+	}
+	// A business still open is dropped from both rubric listings
+	if fmt.Sprint(dbStore.forgotten) != fmt.Sprint([]string{openID, openID}) {
+		t.Fatalf("forgotten = %v, want %s forgotten in both rubrics", dbStore.forgotten, openID)
+	}
+	// Transient failures are not remembered, the next rubric tries again
+	if _, ok := parser.rechecked[transientID]; ok {
+		t.Fatalf("rechecked[%s] is set after a transient error", transientID)
+	}
+}
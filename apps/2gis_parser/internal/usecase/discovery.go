@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"2gis-parser/internal/domain"
+	"2gis-parser/internal/helper"
+	"context"
+	"fmt"
+	"mytravel/pkg/runs"
+	"time"
+)
+
+// DefaultKeywordsFile lists the tourism keywords searched by the discovery mode
+const DefaultKeywordsFile = "docks/tourism_keywords.csv"
+
+// discoveryBatchSize is the number of discovered businesses stored at once
+const discoveryBatchSize = 20
+
+// DiscoveryOptions controls the keyword discovery run
+type DiscoveryOptions struct {
+	RunOptions
+	KeywordsFile string
+}
+
+// DiscoverySummary holds the totals of a keyword discovery run
+type DiscoverySummary struct {
+	Hits          int // search results over all keywords and regions
+	InRubrics     int // results in a crawled rubric, left to the rubric crawl
+	AlreadyStored int // results already stored from an earlier run
+	Discovered    int // new businesses whose details were fetched
+	Stored        int
+	Failed        int
+}
+
+// RunDiscovery searches every tourism keyword in every selected region and ingests businesses
+// the rubric crawl does not reach. Results in a crawled rubric or already stored are skipped,
+// new ones are fetched with full details and stored with discovery method "keyword".
+func (p *Parser) RunDiscovery(ctx context.Context, opts DiscoveryOptions) (summary DiscoverySummary, err error) {
+	startTime := time.Now()
+
+	keywordsFile := opts.KeywordsFile
+	if keywordsFile == "" {
+		keywordsFile = DefaultKeywordsFile
+	}
+	keywords, err := helper.LoadKeywordsFromCSV(keywordsFile)
+	if err != nil {
+		return summary, fmt.Errorf("failed to load keywords: %w", err)
+	}
+
+	regions, err := p.resolveRegions(ctx, opts.RegionIDs)
+	if err != nil {
+		return summary, err
+	}
+
+	rubrics, err := p.resolveRubrics(opts.RunOptions)
+	if err != nil {
+		return summary, err
+	}
+	crawled := make(map[string]bool, len(rubrics))
+	for _, rubricID := range rubrics {
+		crawled[rubricID] = true
+	}
+
+	known, err := p.store.ExternalIDs(ctx, sourceWebsite)
+	if err != nil {
+		return summary, err
+	}
+
+	runID := "discovery-" + runs.NewID()
+	if ctx, err = p.startRun(ctx, runID); err != nil {
+		return summary, err
+	}
+	defer func() { p.finishRun(ctx, err) }()
+
+	p.logger.Info("Starting keyword discovery run %s: %d keywords in %d regions, %d businesses already stored",
+		runID, len(keywords), len(regions), len(known))
+
+	for _, region := range regions {
+		candidates := make(map[string]bool)
+		var discovered []string
+
+		for _, keyword := range keywords {
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+
+			onPage := func(page int, businesses []domain.BusinessDetail) error {
+				p.run.Add(runs.Counts{Fetched: len(businesses)})
+				for _, business := range businesses {
+					summary.Hits++
+					if business.ID == "" || candidates[business.ID] {
+						continue
+					}
+					candidates[business.ID] = true
+
+					switch {
+					case known[business.ID]:
+						summary.AlreadyStored++
+					case inRubrics(business.Rubrics, crawled):
+						summary.InRubrics++
+					default:
+						discovered = append(discovered, business.ID)
+					}
+				}
+				return nil
+			}
+
+			if _, err := p.provider.FetchBusinessesByKeyword(ctx, keyword, region.ID, onPage); err != nil {
+				if ctx.Err() != nil {
+					return summary, ctx.Err()
+				}
+				p.logger.Error("Failed to search keyword '%s' in region %s: %v", keyword, region.ID, err)
+				p.run.AddError(fmt.Errorf("keyword '%s' in region %s: %w", keyword, region.ID, err))
+			}
+		}
+
+		p.logger.Info("Region %s: %d new businesses found by keywords", region.Name, len(discovered))
+		if err := p.storeDiscovered(ctx, discovered, known, &summary); err != nil {
+			return summary, err
+		}
+	}
+
+	p.logger.Info("=== DISCOVERY COMPLETED ===")
+	p.logger.Info("Search results: %d, in crawled rubrics: %d, already stored: %d", summary.Hits, summary.InRubrics, summary.AlreadyStored)
+	p.logger.Info("Discovered: %d, stored: %d, failed: %d", summary.Discovered, summary.Stored, summary.Failed)
+	p.logger.Info("Duration: %v", time.Since(startTime))
+	return summary, nil
+}
+
+// storeDiscovered fetches full details of discovered businesses and stores them in batches
+func (p *Parser) storeDiscovered(ctx context.Context, ids []string, known map[string]bool, summary *DiscoverySummary) error {
+	batch := make([]domain.BusinessDetail, 0, discoveryBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := p.storeBusinesses(ctx, batch); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			p.logger.Error("Failed to store %d discovered businesses: %v", len(batch), err)
+			summary.Failed += len(batch)
+		} else {
+			summary.Stored += len(batch)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		business, err := p.provider.FetchBusinessDetail(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			p.logger.Error("Failed to fetch details of discovered business %s: %v", id, err)
+			p.run.Add(runs.Counts{Failed: 1})
+			p.run.AddError(fmt.Errorf("business %s: %w", id, err))
+			summary.Failed++
+			continue
+		}
+
+		business.DiscoveryMethod = domain.DiscoveryKeyword
+		known[id] = true
+		summary.Discovered++
+		batch = append(batch, business)
+
+		if len(batch) >= discoveryBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// inRubrics reports whether any rubric of a business is covered by the rubric crawl
+func inRubrics(rubrics []domain.Rubric, crawled map[string]bool) bool {
+	for _, rubric := range rubrics {
+		if crawled[rubric.ID] {
+			return true
+		}
+	}
+	return false
+}
@@ -3,25 +3,56 @@ package usecase
 import (
 	"2gis-parser/internal/adapter/logger"
 	"2gis-parser/internal/domain"
+	"2gis-parser/internal/helper"
 	"2gis-parser/internal/store"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"mytravel/pkg/runs"
 	"time"
 )
 
 type BusinessProvider interface {
-	FetchBusinesses(categoryID string, regionID string, fullInfo bool) ([]domain.BusinessDetail, error)
-	FetchRegions() ([]domain.Region, error)
-	FetchBusinessDetail(id string) (domain.BusinessDetail, error)
+	FetchBusinesses(ctx context.Context, categoryID string, regionID string, fullInfo bool, startPage int, onPage domain.PageHandler) ([]domain.BusinessDetail, error)
+	FetchBusinessesTiled(ctx context.Context, categoryID string, regionID string, fullInfo bool, onPage domain.PageHandler) ([]domain.BusinessDetail, error)
+	FetchBusinessesByKeyword(ctx context.Context, keyword string, regionID string, onPage domain.PageHandler) ([]domain.BusinessDetail, error)
+	FetchRegions(ctx context.Context) ([]domain.Region, error)
+	FetchBusinessDetail(ctx context.Context, id string) (domain.BusinessDetail, error)
+	FetchBusinessDetails(ctx context.Context, ids []string) ([]domain.BusinessDetail, error)
+	RequestCount() int64
+}
+
+// Store keeps businesses, crawl checkpoints, runs and closure sightings, *store.PostgresStore in production
+type Store interface {
+	StartRun(ctx context.Context, sourceWebsite, runID string) (*runs.Run, error)
+	LoadCrawlCheckpoints(ctx context.Context, sourceWebsite string) (string, map[string]store.CrawlCheckpoint, error)
+	SaveCrawlCheckpoint(ctx context.Context, sourceWebsite string, checkpoint store.CrawlCheckpoint) error
+	ClearCrawlCheckpoints(ctx context.Context, sourceWebsite string) error
+	InsertBusinessDetail(ctx context.Context, business domain.BusinessDetail) error
+	AccommodationType(rubrics []domain.Rubric) string
+	UnmappedAttributeTags() map[string]int
+	ExternalIDs(ctx context.Context, sourceWebsite string) (map[string]bool, error)
+	StaleBusinesses(ctx context.Context, businesses []domain.BusinessDetail, maxAge time.Duration) ([]string, error)
+	RecordSightings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string, externalIDs []string) error
+	UnseenListings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string) ([]string, error)
+	ForgetSighting(ctx context.Context, sourceWebsite, regionID, rubricID, externalID string) error
+	MarkFound(ctx context.Context, sourceWebsite string, externalIDs []string, reason string) error
+	RecordMiss(ctx context.Context, sourceWebsite, externalID string, threshold int, reason string) (string, error)
+	UpsertBusinessDetails(ctx context.Context, businesses []domain.BusinessDetail) (store.UpsertSummary, error)
 }
 
 type Parser struct {
-	provider BusinessProvider
-	logger   *logger.Logger
-	store    *store.PostgresStore
+	provider  BusinessProvider
+	logger    *logger.Logger
+	store     Store
+	rechecked map[string]bool // businesses re-checked by closure sweeps of the current run, true if still open
+
+	run         *runs.Run // parsing run in progress, nil outside Run and RunDiscovery
+	runRequests int64     // provider request count when the run was opened
 }
 
-func NewParserWithStore(provider BusinessProvider, logger *logger.Logger, dbStore *store.PostgresStore) *Parser {
+func NewParserWithStore(provider BusinessProvider, logger *logger.Logger, dbStore Store) *Parser {
 	return &Parser{
 		provider: provider,
 		logger:   logger,
@@ -29,7 +60,41 @@ func NewParserWithStore(provider BusinessProvider, logger *logger.Logger, dbStor
 	}
 }
 
-func (p *Parser) Run() error {
+// DefaultRubricsFile is the curated list of tourism rubrics crawled by default
+const DefaultRubricsFile = "docks/filtered_rubricks.csv"
+
+// RunOptions controls which regions and rubrics a crawl covers
+type RunOptions struct {
+	RegionIDs   []string // Region allow-list, empty means every region returned by FetchRegions
+	RubricIDs   []string // Rubric IDs to crawl, empty means load them from RubricsFile
+	RubricsFile string
+	Fresh       bool // Discard checkpoints of an unfinished run instead of resuming it
+	// ResumeAge is how long after its oldest checkpoint an unfinished run is resumed, 0 means store.DefaultResumeAge
+	ResumeAge   time.Duration
+	Tiling      bool // Query regions cell by cell to get past per-query result caps
+	FullRefresh bool // Fetch full details of every business instead of only changed ones
+	// FullRefreshAge is how long a business may go without a full-detail fetch, 0 means store.DefaultFullRefreshAge
+	FullRefreshAge time.Duration
+	Sweep          bool // Re-check businesses no longer listed after a complete rubric crawl
+	ClosureMisses  int  // Consecutive failed re-checks before a business is soft-deleted
+}
+
+// RegionSummary holds the totals collected for a single region during a run
+type RegionSummary struct {
+	Region        domain.Region
+	Businesses    int
+	FailedRubrics int
+	Resumed       int // Rubrics skipped because a previous run already completed them
+}
+
+// sourceWebsite is the source name 2GIS checkpoints and records are stored under
+const sourceWebsite = "2gis"
+
+// Run crawls the selected regions and rubrics. When ctx is cancelled it stops fetching,
+// keeps the checkpoints for the next run, logs the summary and returns the context error.
+// Checkpoints are also kept when a rubric ended incomplete, so the next run resumes it,
+// unless the run is older than opts.ResumeAge and the next run starts over.
+func (p *Parser) Run(ctx context.Context, opts RunOptions) error {
 	p.logger.Info("Starting 2GIS KZ regions and businesses parsing with database integration")
 
 	// Initialize database store if not provided
@@ -48,85 +113,365 @@ func (p *Parser) Run() error {
 	startTime := time.Now()
 	totalProcessed := 0
 
-	// First, fetch regions from 2GIS API
-	regions := []domain.Region{domain.Region{ID: "67", Name: "Almaty"}}
+	regions, err := p.resolveRegions(ctx, opts.RegionIDs)
+	if err != nil {
+		return err
+	}
 	p.logger.Info("Processing %d regions", len(regions))
 
-	// Load rubrics from filtered CSV file
-	// rubrics, err := helper.LoadFieldsFromCSV("docks/filtered_rubricks.csv")
-	// if err != nil {
-	// 	p.logger.Error("Failed to load rubrics: %v", err)
-	// 	return fmt.Errorf("failed to load rubrics: %w", err)
-	// }
-	rubrics := []string{"70348", "547", "23469", "214", "110305", "110329"}
+	rubrics, err := p.resolveRubrics(opts)
+	if err != nil {
+		return err
+	}
+	p.logger.Info("Loaded %d tourism rubrics", len(rubrics))
 
-	p.logger.Info("Loaded %d tourism rubrics from CSV", len(rubrics))
+	runID, checkpoints, err := p.prepareCheckpoints(ctx, opts.Fresh, opts.ResumeAge)
+	if err != nil {
+		return err
+	}
+	if ctx, err = p.startRun(ctx, runID); err != nil {
+		return err
+	}
+	p.rechecked = make(map[string]bool)
+
+	summaries := make([]RegionSummary, 0, len(regions))
 
 	// Process each region
 	for _, region := range regions {
+		if ctx.Err() != nil {
+			break
+		}
 		p.logger.Info("Processing region: %s (%s)", region.Name, region.ID)
 
-		regionProcessed := 0
+		summary := RegionSummary{Region: region}
 
 		// For each region, fetch businesses for each tourism rubric
 		for _, rubricID := range rubrics {
-			p.logger.Info("Fetching businesses for rubric %s in %s", rubricID, region.Name)
-
-			businesses, err := p.provider.FetchBusinesses(rubricID, region.ID, true)
-			if err != nil {
-				p.logger.Error("Failed to fetch businesses for rubric %s in region %s: %v", rubricID, region.ID, err)
-				continue
+			if ctx.Err() != nil {
+				p.logger.Info("Shutdown requested, stopping crawl of region %s", region.Name)
+				break
 			}
 
-			p.logger.Info("Found %d businesses for rubric %s in region %s", len(businesses), rubricID, region.Name)
+			checkpoint, ok := checkpoints[store.CheckpointKey(region.ID, rubricID)]
+			if !ok {
+				checkpoint = store.CrawlCheckpoint{RunID: runID, RegionID: region.ID, RubricID: rubricID}
+			}
 
-			if len(businesses) == 0 {
+			if checkpoint.Completed {
+				p.logger.Info("Rubric %s in %s already completed in run %s, skipping", rubricID, region.Name, runID)
+				summary.Businesses += checkpoint.FetchedCount
+				summary.Resumed++
 				continue
 			}
 
-			// Insert businesses into database - choose between parallel and sequential processing
-			if len(businesses) >= 20 { // Use parallel processing for larger batches
-				if err := p.store.InsertBusinessDetailsWithBatching(businesses); err != nil {
-					p.logger.Error("Failed to process businesses for rubric %s: %v", rubricID, err)
-					continue
-				}
-			} else {
-				// Use simple parallel processing for smaller batches
-				if err := p.store.InsertBusinessDetails(businesses); err != nil {
-					p.logger.Error("Failed to process businesses for rubric %s: %v", rubricID, err)
-					continue
-				}
+			fetched, err := p.crawlRubric(ctx, region, checkpoint, opts)
+			if err != nil && ctx.Err() == nil {
+				p.logger.Error("Failed to crawl rubric %s in region %s: %v", rubricID, region.ID, err)
+				p.run.AddError(fmt.Errorf("rubric %s in region %s: %w", rubricID, region.ID, err))
+				summary.FailedRubrics++
 			}
+			summary.Businesses += fetched
+		}
 
-			regionProcessed += len(businesses)
+		totalProcessed += summary.Businesses
+		summaries = append(summaries, summary)
+		p.logger.Info("Completed region %s: %d businesses processed", region.Name, summary.Businesses)
+	}
 
-			// Log individual business details
-			for _, business := range businesses {
-				p.logger.Debug("Processed: ID=%s, Name=%s, Address=%s, Rating=%.1f, Type=%s",
-					business.ID, business.Name, business.FullAddressName,
-					business.Reviews.GeneralRating, p.getAccommodationType(business.Rubrics))
-			}
+	incomplete := 0
+	for _, summary := range summaries {
+		incomplete += summary.FailedRubrics
+	}
 
-			// Add small delay to respect API rate limits
-			time.Sleep(100 * time.Millisecond)
+	interrupted := ctx.Err() != nil
+	switch {
+	case interrupted:
+		p.logger.Info("Crawl run %s interrupted, checkpoints kept for the next run", runID)
+	case incomplete > 0:
+		// Completed rubrics are skipped by the next run, the incomplete ones resume from their last page
+		p.logger.Info("Crawl run %s left %d rubrics incomplete, checkpoints kept to resume them in the next run", runID, incomplete)
+	default:
+		// The run reached the end, so the next one starts from the first region again
+		if err := p.store.ClearCrawlCheckpoints(ctx, sourceWebsite); err != nil {
+			p.logger.Error("Failed to clear crawl checkpoints of run %s: %v", runID, err)
 		}
-
-		totalProcessed += regionProcessed
-		p.logger.Info("Completed region %s: %d businesses processed", region.Name, regionProcessed)
 	}
 
+	p.finishRun(ctx, nil)
+
 	// Final summary
 	duration := time.Since(startTime)
 	p.logger.Info("=== PARSING COMPLETED ===")
-	p.logger.Info("Total processed: %d businesses", totalProcessed)
+	for _, summary := range summaries {
+		p.logger.Info("  - %s (%s): %d businesses, %d failed rubrics, %d rubrics resumed",
+			summary.Region.Name, summary.Region.ID, summary.Businesses, summary.FailedRubrics, summary.Resumed)
+	}
+	p.logger.Info("Total processed: %d businesses in %d regions", totalProcessed, len(summaries))
 	p.logger.Info("Duration: %v", duration)
-	p.logger.Info("Individual business logs stored in parsing_logs table")
+	p.logger.Info("Run totals stored in parsing_runs, individual business logs in parsing_logs")
+	if unmapped := p.store.UnmappedAttributeTags(); len(unmapped) > 0 {
+		p.logger.Info("%d attribute tags have no reviewed amenity mapping, run with -collect-attributes to review them", len(unmapped))
+	}
 
+	if interrupted {
+		return ctx.Err()
+	}
 	return nil
 }
 
+// prepareCheckpoints returns the run to resume with its checkpoints, or starts a new run when
+// there is none or its oldest checkpoint was saved more than resumeAge ago
+func (p *Parser) prepareCheckpoints(ctx context.Context, fresh bool, resumeAge time.Duration) (string, map[string]store.CrawlCheckpoint, error) {
+	if fresh {
+		p.logger.Info("Fresh crawl requested, discarding existing checkpoints")
+		if err := p.store.ClearCrawlCheckpoints(ctx, sourceWebsite); err != nil {
+			return "", nil, err
+		}
+	}
+
+	runID, checkpoints, err := p.store.LoadCrawlCheckpoints(ctx, sourceWebsite)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if resumeAge <= 0 {
+		resumeAge = store.DefaultResumeAge
+	}
+	if runID != "" && time.Since(oldestCheckpoint(checkpoints)) > resumeAge {
+		p.logger.Info("Crawl run %s is older than %s, discarding its checkpoints instead of resuming it", runID, resumeAge)
+		if err := p.store.ClearCrawlCheckpoints(ctx, sourceWebsite); err != nil {
+			return "", nil, err
+		}
+		runID, checkpoints = "", map[string]store.CrawlCheckpoint{}
+	}
+
+	if runID == "" {
+		runID = runs.NewID()
+		p.logger.Info("Starting new crawl run %s", runID)
+	} else {
+		p.logger.Info("Resuming crawl run %s from %d checkpoints", runID, len(checkpoints))
+	}
+
+	return runID, checkpoints, nil
+}
+
+// oldestCheckpoint returns when the least recently saved checkpoint was saved. Completed rubrics
+// are not saved again, so it is close to when the run started.
+func oldestCheckpoint(checkpoints map[string]store.CrawlCheckpoint) time.Time {
+	var oldest time.Time
+	for _, checkpoint := range checkpoints {
+		if oldest.IsZero() || checkpoint.UpdatedAt.Before(oldest) {
+			oldest = checkpoint.UpdatedAt
+		}
+	}
+	return oldest
+}
+
+// crawlRubric fetches and stores businesses of a rubric in a region page by page,
+// checkpointing after every stored page. It returns the number of businesses listed
+// for the rubric in this run, including pages stored before a restart.
+// Unless a full refresh is requested, pages are listed with the lightweight field set and
+// only businesses whose dates.updated_at changed are fetched in full and stored.
+// Tiled crawls have no stable page order, so an interrupted tiled rubric starts over.
+func (p *Parser) crawlRubric(ctx context.Context, region domain.Region, checkpoint store.CrawlCheckpoint, opts RunOptions) (int, error) {
+	tiling := opts.Tiling
+	startPage := checkpoint.LastPage + 1
+	refreshed := 0
+	switch {
+	case tiling:
+		p.logger.Info("Fetching businesses for rubric %s in %s using tiled search", checkpoint.RubricID, region.Name)
+		checkpoint.FetchedCount = 0
+	case checkpoint.LastPage > 0:
+		p.logger.Info("Resuming rubric %s in %s from page %d", checkpoint.RubricID, region.Name, startPage)
+	default:
+		p.logger.Info("Fetching businesses for rubric %s in %s", checkpoint.RubricID, region.Name)
+	}
+
+	onPage := func(page int, businesses []domain.BusinessDetail) error {
+		p.run.Add(runs.Counts{Fetched: len(businesses)})
+		if err := p.recordSightings(ctx, region, checkpoint, businesses); err != nil {
+			return err
+		}
+
+		changed := businesses
+		if !opts.FullRefresh {
+			var err error
+			if changed, err = p.fetchChanged(ctx, businesses, opts.FullRefreshAge); err != nil {
+				return err
+			}
+		}
+		if err := p.storeBusinesses(ctx, changed); err != nil {
+			return err
+		}
+		refreshed += len(changed)
+
+		if !tiling {
+			checkpoint.LastPage = page
+		}
+		checkpoint.FetchedCount += len(businesses)
+		return p.store.SaveCrawlCheckpoint(ctx, sourceWebsite, checkpoint)
+	}
+
+	var err error
+	if tiling {
+		_, err = p.provider.FetchBusinessesTiled(ctx, checkpoint.RubricID, region.ID, opts.FullRefresh, onPage)
+	} else {
+		_, err = p.provider.FetchBusinesses(ctx, checkpoint.RubricID, region.ID, opts.FullRefresh, startPage, onPage)
+	}
+	if err != nil {
+		var partial *domain.PartialResultError
+		if errors.As(err, &partial) && ctx.Err() == nil {
+			p.logger.Error("Rubric %s in %s is incomplete, stopped at page %d; it will be resumed from the checkpoint",
+				checkpoint.RubricID, region.Name, partial.Page)
+		}
+		return checkpoint.FetchedCount, err
+	}
+
+	p.logger.Info("Found %d businesses for rubric %s in region %s, %d fetched in full this run",
+		checkpoint.FetchedCount, checkpoint.RubricID, region.Name, refreshed)
+
+	checkpoint.Completed = true
+	if err := p.store.SaveCrawlCheckpoint(ctx, sourceWebsite, checkpoint); err != nil {
+		return checkpoint.FetchedCount, err
+	}
+
+	if opts.Sweep {
+		if err := p.sweepRubric(ctx, region, checkpoint, opts.ClosureMisses); err != nil && ctx.Err() == nil {
+			p.logger.Error("Closure sweep of rubric %s in %s failed: %v", checkpoint.RubricID, region.Name, err)
+		}
+	}
+
+	return checkpoint.FetchedCount, nil
+}
+
+// recordSightings stores which businesses a listing page contained, used by the closure sweep
+func (p *Parser) recordSightings(ctx context.Context, region domain.Region, checkpoint store.CrawlCheckpoint, businesses []domain.BusinessDetail) error {
+	ids := make([]string, 0, len(businesses))
+	for _, business := range businesses {
+		if business.ID != "" {
+			ids = append(ids, business.ID)
+		}
+	}
+	return p.store.RecordSightings(ctx, sourceWebsite, checkpoint.RunID, region.ID, checkpoint.RubricID, ids)
+}
+
+// fetchChanged returns full details of the businesses of a lightweight listing that changed
+// since they were stored or are due for a periodic full refresh
+func (p *Parser) fetchChanged(ctx context.Context, businesses []domain.BusinessDetail, maxAge time.Duration) ([]domain.BusinessDetail, error) {
+	if maxAge <= 0 {
+		maxAge = store.DefaultFullRefreshAge
+	}
+
+	stale, err := p.store.StaleBusinesses(ctx, businesses, maxAge)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.Debug("%d of %d listed businesses changed or are due for a full refresh", len(stale), len(businesses))
+	if len(stale) == 0 {
+		return nil, nil
+	}
+
+	details, err := p.provider.FetchBusinessDetails(ctx, stale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch details of changed businesses: %w", err)
+	}
+	return details, nil
+}
+
+// storeBusinesses inserts a page of businesses into the database
+func (p *Parser) storeBusinesses(ctx context.Context, businesses []domain.BusinessDetail) error {
+	if len(businesses) == 0 {
+		return nil
+	}
+
+	summary, err := p.store.UpsertBusinessDetails(ctx, businesses)
+	if err != nil {
+		return fmt.Errorf("failed to process businesses: %w", err)
+	}
+	p.run.Add(runs.Counts{
+		Inserted: summary.Inserted,
+		Updated:  summary.Updated,
+		Skipped:  summary.Unchanged,
+		Failed:   summary.Failed,
+	})
+	for _, row := range summary.Rows {
+		if row.Err != nil {
+			p.logger.Error("Failed to store business %s: %v", row.ExternalID, row.Err)
+			p.run.AddError(fmt.Errorf("business %s: %w", row.ExternalID, row.Err))
+		}
+	}
+	// A page where nothing could be stored points at the database, keep its checkpoint unsaved
+	if summary.Failed == len(summary.Rows) {
+		return fmt.Errorf("failed to process businesses: %w", summary.Err())
+	}
+
+	// Log individual business details
+	for _, business := range businesses {
+		p.logger.Debug("Processed: ID=%s, Name=%s, Address=%s, Rating=%.1f, Type=%s",
+			business.ID, business.Name, business.FullAddressName,
+			business.Reviews.GeneralRating, p.store.AccommodationType(business.Rubrics))
+	}
+
+	return nil
+}
+
+// resolveRegions fetches Kazakhstan regions and narrows them down to the allow-list if one is given
+func (p *Parser) resolveRegions(ctx context.Context, allowList []string) ([]domain.Region, error) {
+	regions, err := p.provider.FetchRegions(ctx)
+	if err != nil {
+		if len(allowList) == 0 {
+			return nil, fmt.Errorf("failed to fetch regions: %w", err)
+		}
+		// Region names are only used for logging, so the allow-list alone is enough to crawl
+		p.logger.Error("Failed to fetch regions, falling back to region IDs from allow-list: %v", err)
+		regions = nil
+	}
+
+	if len(allowList) == 0 {
+		return regions, nil
+	}
+
+	byID := make(map[string]domain.Region, len(regions))
+	for _, region := range regions {
+		byID[region.ID] = region
+	}
+
+	selected := make([]domain.Region, 0, len(allowList))
+	for _, id := range allowList {
+		region, ok := byID[id]
+		if !ok {
+			p.logger.Info("Region %s not found in 2GIS region list, crawling it by ID", id)
+			region = domain.Region{ID: id, Name: id}
+		}
+		selected = append(selected, region)
+	}
+	return selected, nil
+}
+
+// resolveRubrics returns the rubric IDs to crawl, loading them from the rubrics CSV unless given explicitly
+func (p *Parser) resolveRubrics(opts RunOptions) ([]string, error) {
+	if len(opts.RubricIDs) > 0 {
+		return opts.RubricIDs, nil
+	}
+
+	rubricsFile := opts.RubricsFile
+	if rubricsFile == "" {
+		rubricsFile = DefaultRubricsFile
+	}
+
+	rubrics, err := helper.LoadRubricIDsFromCSV(rubricsFile)
+	if err != nil {
+		p.logger.Error("Failed to load rubrics: %v", err)
+		return nil, fmt.Errorf("failed to load rubrics: %w", err)
+	}
+	if len(rubrics) == 0 {
+		return nil, fmt.Errorf("no rubrics found in %s", rubricsFile)
+	}
+	return rubrics, nil
+}
+
 // RunSingleBusiness fetches and stores a single business by ID
-func (p *Parser) RunSingleBusiness(businessID string) error {
+func (p *Parser) RunSingleBusiness(ctx context.Context, businessID string) error {
 	if p.store == nil {
 		config := store.LoadConfigFromEnv()
 		dbStore, err := store.NewPostgresStore(config, p.logger)
@@ -139,38 +484,17 @@ func (p *Parser) RunSingleBusiness(businessID string) error {
 
 	p.logger.Info("Fetching single business with ID: %s", businessID)
 
-	business, err := p.provider.FetchBusinessDetail(businessID)
+	business, err := p.provider.FetchBusinessDetail(ctx, businessID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch business detail: %w", err)
 	}
 
 	p.logger.Info("Successfully fetched business: %s", business.Name)
 	log.Printf("Business Details: %+v\n", business)
-	if err := p.store.InsertBusinessDetail(business); err != nil {
+	if err := p.store.InsertBusinessDetail(ctx, business); err != nil {
 		return fmt.Errorf("failed to insert business detail: %w", err)
 	}
 
 	p.logger.Info("Successfully stored business in database")
 	return nil
 }
-
-// getAccommodationType helper function to determine accommodation type for logging
-func (p *Parser) getAccommodationType(rubrics []domain.Rubric) string {
-	for _, rubric := range rubrics {
-		switch rubric.Alias {
-		case "gostinicy":
-			return "Hotel"
-		case "khostely":
-			return "Hostel"
-		case "bazy_otdykha":
-			return "Resort"
-		case "arenda_kottedzhejj":
-			return "Villa"
-		case "kempingi":
-			return "Camping"
-		case "sanatorii":
-			return "Sanatorium"
-		}
-	}
-	return "Other"
-}
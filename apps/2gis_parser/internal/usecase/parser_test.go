@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"2gis-parser/internal/adapter/logger"
+	"2gis-parser/internal/adapter/twogis"
+	"2gis-parser/internal/domain"
+	"2gis-parser/internal/store"
+	"context"
+	"mytravel/pkg/runs"
+	"testing"
+	"time"
+)
+
+// fixturesDir holds 2GIS responses for region 67 (Almaty) and rubric 269 (hotels) with two
+// businesses. Single business lookups are recorded for 70000001023456790, which still exists,
+// and 70000001023456791, which the API no longer knows. Nothing is recorded for rubric 547.
+const fixturesDir = "testdata/fixtures"
+
+// fakeStore keeps everything the parser writes in memory
+type fakeStore struct {
+	unseen      []string        // returned by UnseenListings
+	resumeRun   string          // unfinished run LoadCrawlCheckpoints returns with checkpoints
+	unchanged   map[string]bool // listed businesses StaleBusinesses does not report
+	checkpoints map[string]store.CrawlCheckpoint
+	cleared     int
+	stored      []domain.BusinessDetail
+	misses      []string
+	missCount   map[string]int // consecutive misses RecordMiss compares with the threshold
+	deleted     []string
+	found       []string
+	forgotten   []string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{checkpoints: make(map[string]store.CrawlCheckpoint), missCount: make(map[string]int)}
+}
+
+func (s *fakeStore) StartRun(ctx context.Context, sourceWebsite, runID string) (*runs.Run, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) LoadCrawlCheckpoints(ctx context.Context, sourceWebsite string) (string, map[string]store.CrawlCheckpoint, error) {
+	if s.resumeRun == "" {
+		return "", nil, nil
+	}
+	checkpoints := make(map[string]store.CrawlCheckpoint, len(s.checkpoints))
+	for key, checkpoint := range s.checkpoints {
+		checkpoints[key] = checkpoint
+	}
+	return s.resumeRun, checkpoints, nil
+}
+
+func (s *fakeStore) SaveCrawlCheckpoint(ctx context.Context, sourceWebsite string, checkpoint store.CrawlCheckpoint) error {
+	s.checkpoints[checkpoint.Key()] = checkpoint
+	return nil
+}
+
+func (s *fakeStore) ClearCrawlCheckpoints(ctx context.Context, sourceWebsite string) error {
+	s.cleared++
+	s.resumeRun = ""
+	return nil
+}
+
+func (s *fakeStore) InsertBusinessDetail(ctx context.Context, business domain.BusinessDetail) error {
+	s.stored = append(s.stored, business)
+	return nil
+}
+
+func (s *fakeStore) AccommodationType(rubrics []domain.Rubric) string {
+	return "hotel"
+}
+
+func (s *fakeStore) UnmappedAttributeTags() map[string]int {
+	return nil
+}
+
+func (s *fakeStore) ExternalIDs(ctx context.Context, sourceWebsite string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+// StaleBusinesses treats every listed business as changed unless it is in unchanged
+func (s *fakeStore) StaleBusinesses(ctx context.Context, businesses []domain.BusinessDetail, maxAge time.Duration) ([]string, error) {
+	ids := make([]string, 0, len(businesses))
+	for _, business := range businesses {
+		if !s.unchanged[business.ID] {
+			ids = append(ids, business.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) RecordSightings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string, externalIDs []string) error {
+	return nil
+}
+
+func (s *fakeStore) UnseenListings(ctx context.Context, sourceWebsite, runID, regionID, rubricID string) ([]string, error) {
+	return s.unseen, nil
+}
+
+func (s *fakeStore) ForgetSighting(ctx context.Context, sourceWebsite, regionID, rubricID, externalID string) error {
+	s.forgotten = append(s.forgotten, externalID)
+	return nil
+}
+
+func (s *fakeStore) MarkFound(ctx context.Context, sourceWebsite string, externalIDs []string, reason string) error {
+	s.found = append(s.found, externalIDs...)
+	for _, id := range externalIDs {
+		delete(s.missCount, id)
+	}
+	return nil
+}
+
+func (s *fakeStore) RecordMiss(ctx context.Context, sourceWebsite, externalID string, threshold int, reason string) (string, error) {
+	s.misses = append(s.misses, externalID)
+	s.missCount[externalID]++
+	if s.missCount[externalID] >= threshold {
+		s.deleted = append(s.deleted, externalID)
+		return store.ClosureDeleted, nil
+	}
+	return store.ClosureMissed, nil
+}
+
+func (s *fakeStore) UpsertBusinessDetails(ctx context.Context, businesses []domain.BusinessDetail) (store.UpsertSummary, error) {
+	s.stored = append(s.stored, businesses...)
+	summary := store.UpsertSummary{Inserted: len(businesses)}
+	for _, business := range businesses {
+		summary.Rows = append(summary.Rows, store.RowResult{ExternalID: business.ID, Outcome: store.OutcomeInserted})
+	}
+	return summary, nil
+}
+
+func newReplayParser(t *testing.T, dbStore Store) *Parser {
+	t.Helper()
+	l := logger.New("test")
+	api, err := twogis.NewReplayAPI(fixturesDir, l)
+	if err != nil {
+		t.Fatalf("open fixtures: %v", err)
+	}
+	return NewParserWithStore(api, l, dbStore)
+}
+
+func TestParserRunReplay(t *testing.T) {
+	dbStore := newFakeStore()
+	// Not recorded, so the sweep cannot tell whether it still exists
+	dbStore.unseen = []string{"70000001023456789"}
+	parser := newReplayParser(t, dbStore)
+
+	opts := RunOptions{RegionIDs: []string{"67"}, RubricIDs: []string{"269"}, Sweep: true, ClosureMisses: 1}
+	if err := parser.Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if len(dbStore.stored) != 2 || dbStore.stored[0].Name != "Отель Алатау" || dbStore.stored[1].Name != "Хостел Медеу" {
+		t.Fatalf("stored %+v, want the full details of both listed hotels", dbStore.stored)
+	}
+	checkpoint := dbStore.checkpoints[store.CheckpointKey("67", "269")]
+	if !checkpoint.Completed || checkpoint.LastPage != 1 || checkpoint.FetchedCount != 2 {
+		t.Fatalf("checkpoint = %+v, want completed after page 1 with 2 businesses", checkpoint)
+	}
+	if len(dbStore.misses) != 0 || len(dbStore.found) != 0 {
+		t.Fatalf("sweep recorded misses %v and found %v, a missing fixture must not count either way", dbStore.misses, dbStore.found)
+	}
+	if dbStore.cleared != 1 {
+		t.Fatalf("checkpoints cleared %d times, want once after a complete run", dbStore.cleared)
+	}
+}
+
+func TestParserRunSkipsUnchangedBusinesses(t *testing.T) {
+	dbStore := newFakeStore()
+	dbStore.unchanged = map[string]bool{"70000001023456781": true, "70000001023456782": true}
+	parser := newReplayParser(t, dbStore)
+
+	// Only the lightweight listing is needed, no business is fetched in full
+	opts := RunOptions{RegionIDs: []string{"67"}, RubricIDs: []string{"269"}}
+	if err := parser.Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if len(dbStore.stored) != 0 {
+		t.Fatalf("stored %+v, want nothing for unchanged businesses", dbStore.stored)
+	}
+	checkpoint := dbStore.checkpoints[store.CheckpointKey("67", "269")]
+	if !checkpoint.Completed || checkpoint.FetchedCount != 2 {
+		t.Fatalf("checkpoint = %+v, want completed with both listed businesses counted", checkpoint)
+	}
+}
+
+func TestParserRunKeepsCheckpointsOfIncompleteRubrics(t *testing.T) {
+	dbStore := newFakeStore()
+	parser := newReplayParser(t, dbStore)
+
+	// Rubric 547 has no recorded listing, so it fails and the run ends incomplete
+	opts := RunOptions{RegionIDs: []string{"67"}, RubricIDs: []string{"269", "547"}}
+	if err := parser.Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if !dbStore.checkpoints[store.CheckpointKey("67", "269")].Completed {
+		t.Fatalf("checkpoint of rubric 269 = %+v, want completed", dbStore.checkpoints[store.CheckpointKey("67", "269")])
+	}
+	if _, ok := dbStore.checkpoints[store.CheckpointKey("67", "547")]; ok {
+		t.Fatalf("rubric 547 has a checkpoint without a stored page")
+	}
+	if dbStore.cleared != 0 {
+		t.Fatalf("checkpoints cleared %d times, want them kept to resume rubric 547", dbStore.cleared)
+	}
+}
+
+func TestParserRunResumeAge(t *testing.T) {
+	tests := []struct {
+		name        string
+		saved       time.Duration // age of the completed checkpoint of the unfinished run
+		wantStored  int
+		wantCleared int
+		wantResumed bool
+	}{
+		{name: "resumes a recent run", saved: time.Hour, wantStored: 0, wantCleared: 1, wantResumed: true},
+		{name: "starts over after the resume age", saved: 48 * time.Hour, wantStored: 2, wantCleared: 2, wantResumed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbStore := newFakeStore()
+			dbStore.resumeRun = "unfinished"
+			key := store.CheckpointKey("67", "269")
+			dbStore.checkpoints[key] = store.CrawlCheckpoint{
+				RunID: "unfinished", RegionID: "67", RubricID: "269", LastPage: 1, FetchedCount: 2, Completed: true,
+				UpdatedAt: time.Now().Add(-tt.saved),
+			}
+			parser := newReplayParser(t, dbStore)
+
+			opts := RunOptions{RegionIDs: []string{"67"}, RubricIDs: []string{"269"}, ResumeAge: 24 * time.Hour}
+			if err := parser.Run(context.Background(), opts); err != nil {
+				t.Fatalf("Run() = %v", err)
+			}
+
+			if len(dbStore.stored) != tt.wantStored {
+				t.Fatalf("stored %d businesses, want %d", len(dbStore.stored), tt.wantStored)
+			}
+			if dbStore.cleared != tt.wantCleared {
+				t.Fatalf("checkpoints cleared %d times, want %d", dbStore.cleared, tt.wantCleared)
+			}
+			if resumed := dbStore.checkpoints[key].RunID == "unfinished"; resumed != tt.wantResumed {
+				t.Fatalf("checkpoint run = %q, resumed %v, want %v", dbStore.checkpoints[key].RunID, resumed, tt.wantResumed)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"2gis-parser/internal/store"
+	"context"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/runs"
+)
+
+// startRun opens a parsing run, or resumes it when the ID exists, and returns a context whose
+// writes are linked to the run
+func (p *Parser) startRun(ctx context.Context, runID string) (context.Context, error) {
+	run, err := p.store.StartRun(ctx, sourceWebsite, runID)
+	if err != nil {
+		return ctx, err
+	}
+	p.run = run
+	p.runRequests = p.provider.RequestCount()
+	return store.WithRunID(ctx, runID), nil
+}
+
+// finishRun closes the run in progress. The status is interrupted when ctx was cancelled
+// and failed when the run stopped with err.
+func (p *Parser) finishRun(ctx context.Context, err error) {
+	run := p.run
+	if run == nil {
+		return
+	}
+	p.run = nil
+
+	run.Add(runs.Counts{APICalls: int(p.provider.RequestCount() - p.runRequests)})
+
+	status := runs.StatusCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = runs.StatusInterrupted
+	case err != nil:
+		status = runs.StatusFailed
+		run.AddError(err)
+	}
+	if err := run.Finish(ctx, status); err != nil {
+		p.logger.Error("Failed to finish run %s: %v", run.ID, err)
+	}
+	if status == runs.StatusCompleted {
+		metrics.SetLastSuccessfulRun(sourceWebsite)
+	}
+
+	counts := run.Counts()
+	p.logger.Info("Run %s %s: %d fetched, %d inserted, %d updated, %d unchanged, %d failed, %d API calls",
+		run.ID, status, counts.Fetched, counts.Inserted, counts.Updated, counts.Skipped, counts.Failed, counts.APICalls)
+}
@@ -3,24 +3,39 @@ package main
 import (
 	"2gis-parser/internal/adapter/logger"
 	"2gis-parser/internal/adapter/twogis"
+	"context"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 )
 
-// Simple test to verify regions parsing works
-func testRegionsParsing() {
+// Simple test to verify regions parsing works, either live or from recorded fixtures
+func testRegionsParsing(replayDir string) {
 	l := logger.New("")
 
-	// Use the provided API key from the URL
-	apiKey := "401b0774-dbe8-4f70-91c9-697adac3e650"
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	api := twogis.NewAPI(client, apiKey, l)
+	var api *twogis.API
+	if replayDir != "" {
+		replayAPI, err := twogis.NewReplayAPI(replayDir, l)
+		if err != nil {
+			l.Error("Failed to open fixtures: %v", err)
+			return
+		}
+		api = replayAPI
+	} else {
+		apiKey := os.Getenv("TWO_GIS_API_KEY")
+		if apiKey == "" {
+			l.Error("TWO_GIS_API_KEY is not set, pass -replay <dir> to run against recorded fixtures")
+			return
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		api = twogis.NewAPI(client, apiKey, l)
+	}
 
 	l.Info("Testing 2GIS regions API...")
 
-	regions, err := api.FetchRegions()
+	regions, err := api.FetchRegions(context.Background())
 	if err != nil {
 		l.Error("Failed to fetch regions: %v", err)
 		return
@@ -39,8 +54,11 @@ func testRegionsParsing() {
 }
 
 func main() {
+	replayDir := flag.String("replay", "", "Serve 2GIS responses from this fixtures directory instead of the API")
+	flag.Parse()
+
 	// Run test first
-	testRegionsParsing()
+	testRegionsParsing(*replayDir)
 
 	// Original main logic would continue here...
 }
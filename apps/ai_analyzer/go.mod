@@ -9,6 +9,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/sashabaranov/go-openai v1.41.2
+	mytravel/pkg v0.0.0
 )
 
 require (
@@ -44,3 +45,6 @@ require (
 	golang.org/x/tools v0.34.0 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
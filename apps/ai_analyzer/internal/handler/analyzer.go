@@ -2,10 +2,13 @@ package handler
 
 import (
 	"ai_analyzer/internal/models"
+	"ai_analyzer/internal/repository"
 	"ai_analyzer/internal/service"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -54,6 +57,16 @@ func (h *AnalyzerHandler) GetAccommodations(c *gin.Context) {
 	if accommodationType := c.Query("accommodation_type"); accommodationType != "" {
 		filters["accommodation_type"] = accommodationType
 	}
+	for _, key := range []string{"region", "city", "region_kato"} {
+		if value := c.Query(key); value != "" {
+			filters[key] = value
+		}
+	}
+	if mergedStr := c.Query("merged"); mergedStr != "" {
+		if merged, err := strconv.ParseBool(mergedStr); err == nil {
+			filters["merged"] = merged
+		}
+	}
 
 	// Parse limit
 	limit := 50 // default
@@ -88,6 +101,49 @@ func (h *AnalyzerHandler) GetAccommodationByID(c *gin.Context) {
 	})
 }
 
+// GetAccommodationHistory lists the field changes of an accommodation. Optional query
+// parameters: field (comma-separated, e.g. price_range_min,rating,phone) and limit.
+func (h *AnalyzerHandler) GetAccommodationHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid accommodation ID"})
+		return
+	}
+
+	var fields []string
+	if fieldStr := c.Query("field"); fieldStr != "" {
+		for _, field := range strings.Split(fieldStr, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	limit := 100 // default
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+
+	history, err := h.analyzerService.GetAccommodationHistory(c.Request.Context(), id, fields, limit)
+	if errors.Is(err, repository.ErrAccommodationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accommodation_id": id,
+		"count":            len(history),
+		"history":          history,
+	})
+}
+
 func (h *AnalyzerHandler) AnalyzeSingleAccommodation(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -37,6 +37,15 @@ type Accommodation struct {
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	DeletedAt          *time.Time `json:"deleted_at" db:"deleted_at"`
 	AccommodationType  *string    `json:"accommodation_type" db:"accommodation_type"`
+	OpeningHours       JSONB      `json:"opening_hours" db:"opening_hours"`
+	Region             *string    `json:"region" db:"region"` // located from the coordinates
+	City               *string    `json:"city" db:"city"`
+	RegionKATO         *string    `json:"region_kato" db:"region_kato"` // KATO code of the region
+	// Set on merged records: the place, the sources listing it and the source of every merged field
+	PlaceID     *int     `json:"place_id,omitempty" db:"place_id"`
+	Sources     []string `json:"sources,omitempty" db:"sources"`
+	SourceCount int      `json:"source_count,omitempty" db:"source_count"`
+	Provenance  JSONB    `json:"provenance,omitempty" db:"provenance"`
 }
 
 type JSONB []byte
@@ -63,6 +72,14 @@ func (j *JSONB) Scan(value interface{}) error {
 	return nil
 }
 
+// MarshalJSON writes the stored JSON as is instead of base64, null when empty
+func (j JSONB) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
 // Helper method to unmarshal JSONB to interface{}
 func (j JSONB) Unmarshal() (interface{}, error) {
 	if len(j) == 0 {
@@ -90,3 +107,16 @@ type AnalysisResponse struct {
 	RequestedAt    time.Time `json:"requested_at"`
 	ProcessingTime string    `json:"processing_time"`
 }
+
+// AccommodationHistory is a single changed field of an accommodation, recorded by the
+// accommodation_history trigger. Values are the JSON of the column, null when it was empty.
+type AccommodationHistory struct {
+	ID              int64     `json:"id" db:"id"`
+	AccommodationID int       `json:"accommodation_id" db:"accommodation_id"`
+	Field           string    `json:"field" db:"field"`
+	OldValue        JSONB     `json:"old_value" db:"old_value"`
+	NewValue        JSONB     `json:"new_value" db:"new_value"`
+	SourceWebsite   string    `json:"source_website" db:"source_website"`
+	RunID           *string   `json:"run_id" db:"run_id"`
+	ChangedAt       time.Time `json:"changed_at" db:"changed_at"`
+}
@@ -4,6 +4,9 @@ import (
 	"ai_analyzer/internal/models"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type AccommodationRepository struct {
@@ -14,17 +17,18 @@ func NewAccommodationRepository(db *sql.DB) *AccommodationRepository {
 	return &AccommodationRepository{db: db}
 }
 
+// GetAll returns merged records, one per place, unless the "merged" filter is false: then the
+// raw records of every source are returned
 func (r *AccommodationRepository) GetAll(filters map[string]interface{}, limit int) ([]models.Accommodation, error) {
-	query := `
-		SELECT id, name, latitude, longitude, address, phone, email, 
-		       social_media_links, website_url, social_media_page, 
-		       service_description, room_count, capacity, price_range_min, 
-		       price_range_max, price_currency, photos, rating, review_count, 
-		       reviews, amenities, verification_status, last_updated, 
-		       source_website, source_url, external_id, created_at, 
-		       deleted_at, accommodation_type
-		FROM accommodations 
-		WHERE deleted_at IS NULL`
+	merged := true
+	if value, ok := filters["merged"].(bool); ok {
+		merged = value
+	}
+
+	query := `SELECT ` + mergedColumns + ` FROM merged_accommodations WHERE deleted_at IS NULL`
+	if !merged {
+		query = `SELECT ` + rawColumns + ` FROM accommodations WHERE deleted_at IS NULL`
+	}
 
 	args := []interface{}{}
 	argIndex := 1
@@ -32,7 +36,12 @@ func (r *AccommodationRepository) GetAll(filters map[string]interface{}, limit i
 	// Apply filters
 	if filters != nil {
 		if sourceWebsite, ok := filters["source_website"]; ok {
-			query += fmt.Sprintf(" AND source_website = $%d", argIndex)
+			if merged {
+				// A place matches every source it is listed by
+				query += fmt.Sprintf(" AND $%d = ANY(sources)", argIndex)
+			} else {
+				query += fmt.Sprintf(" AND source_website = $%d", argIndex)
+			}
 			args = append(args, sourceWebsite)
 			argIndex++
 		}
@@ -51,6 +60,22 @@ func (r *AccommodationRepository) GetAll(filters map[string]interface{}, limit i
 			args = append(args, accommodationType)
 			argIndex++
 		}
+		for _, column := range []string{"region", "city", "region_kato"} {
+			if value, ok := filters[column]; ok {
+				query += fmt.Sprintf(" AND %s = $%d", column, argIndex)
+				args = append(args, value)
+				argIndex++
+			}
+		}
+		openAt, ok, err := openAtFilter(filters)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query += fmt.Sprintf(" AND accommodation_is_open_at(opening_hours, $%d)", argIndex)
+			args = append(args, openAt)
+			argIndex++
+		}
 	}
 
 	query += " ORDER BY created_at DESC"
@@ -68,40 +93,57 @@ func (r *AccommodationRepository) GetAll(filters map[string]interface{}, limit i
 
 	var accommodations []models.Accommodation
 	for rows.Next() {
-		var acc models.Accommodation
-		err := rows.Scan(
-			&acc.ID, &acc.Name, &acc.Latitude, &acc.Longitude, &acc.Address,
-			&acc.Phone, &acc.Email, &acc.SocialMediaLinks, &acc.WebsiteURL,
-			&acc.SocialMediaPage, &acc.ServiceDescription, &acc.RoomCount,
-			&acc.Capacity, &acc.PriceRangeMin, &acc.PriceRangeMax,
-			&acc.PriceCurrency, &acc.Photos, &acc.Rating, &acc.ReviewCount,
-			&acc.Reviews, &acc.Amenities, &acc.VerificationStatus,
-			&acc.LastUpdated, &acc.SourceWebsite, &acc.SourceURL,
-			&acc.ExternalID, &acc.CreatedAt, &acc.DeletedAt, &acc.AccommodationType,
-		)
+		acc, err := scanAccommodation(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan accommodation: %w", err)
 		}
-		accommodations = append(accommodations, acc)
+		accommodations = append(accommodations, *acc)
 	}
 
 	return accommodations, nil
 }
 
+// GetByID returns the merged record of the place the accommodation record belongs to
 func (r *AccommodationRepository) GetByID(id int) (*models.Accommodation, error) {
 	query := `
-		SELECT id, name, latitude, longitude, address, phone, email, 
-		       social_media_links, website_url, social_media_page, 
-		       service_description, room_count, capacity, price_range_min, 
-		       price_range_max, price_currency, photos, rating, review_count, 
-		       reviews, amenities, verification_status, last_updated, 
-		       source_website, source_url, external_id, created_at, 
-		       deleted_at, accommodation_type
-		FROM accommodations 
-		WHERE id = $1 AND deleted_at IS NULL`
+		SELECT ` + mergedColumns + `
+		FROM merged_accommodations
+		WHERE deleted_at IS NULL
+		  AND (id = $1 OR place_id = (SELECT place_id FROM place_sources WHERE accommodation_id = $1))
+		LIMIT 1`
+
+	acc, err := scanAccommodation(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accommodation by ID: %w", err)
+	}
+
+	return acc, nil
+}
+
+// accommodationColumns are the columns accommodations and merged_accommodations have in common
+const accommodationColumns = `id, name, latitude, longitude, address, phone, email,
+	social_media_links, website_url, social_media_page,
+	service_description, room_count, capacity, price_range_min,
+	price_range_max, price_currency, photos, rating, review_count,
+	reviews, amenities, verification_status, last_updated,
+	source_website, source_url, external_id, created_at,
+	deleted_at, accommodation_type, opening_hours,
+	region, city, region_kato`
+
+// mergedColumns and rawColumns select the columns scanAccommodation reads, raw records are
+// places of their own until the place matcher merges them
+const (
+	mergedColumns = accommodationColumns + `, place_id, sources, source_count, provenance`
+	rawColumns    = accommodationColumns + `, NULL::integer, ARRAY[source_website::text], 1, NULL::jsonb`
+)
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccommodation(row rowScanner) (*models.Accommodation, error) {
 	var acc models.Accommodation
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&acc.ID, &acc.Name, &acc.Latitude, &acc.Longitude, &acc.Address,
 		&acc.Phone, &acc.Email, &acc.SocialMediaLinks, &acc.WebsiteURL,
 		&acc.SocialMediaPage, &acc.ServiceDescription, &acc.RoomCount,
@@ -110,26 +152,32 @@ func (r *AccommodationRepository) GetByID(id int) (*models.Accommodation, error)
 		&acc.Reviews, &acc.Amenities, &acc.VerificationStatus,
 		&acc.LastUpdated, &acc.SourceWebsite, &acc.SourceURL,
 		&acc.ExternalID, &acc.CreatedAt, &acc.DeletedAt, &acc.AccommodationType,
+		&acc.OpeningHours, &acc.Region, &acc.City, &acc.RegionKATO, &acc.PlaceID, pq.Array(&acc.Sources), &acc.SourceCount, &acc.Provenance,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get accommodation by ID: %w", err)
+		return nil, err
 	}
-
 	return &acc, nil
 }
 
 func (r *AccommodationRepository) GetStats() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
-	// Total count
-	var totalCount int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM accommodations WHERE deleted_at IS NULL").Scan(&totalCount)
+	// Total count, records of different sources describing the same place are counted once.
+	// Records the place matcher has not linked yet count as places of their own.
+	var totalCount, recordCount int
+	err := r.db.QueryRow(`
+		SELECT COUNT(DISTINCT coalesce(ps.place_id, -a.id)), COUNT(*)
+		FROM accommodations a
+		LEFT JOIN place_sources ps ON ps.accommodation_id = a.id
+		WHERE a.deleted_at IS NULL`).Scan(&totalCount, &recordCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total count: %w", err)
 	}
 	stats["total_count"] = totalCount
+	stats["record_count"] = recordCount
 
-	// By source
+	// By source, a place listed by several sources counts for each of them
 	rows, err := r.db.Query(`
 		SELECT source_website, COUNT(*) 
 		FROM accommodations 
@@ -150,12 +198,57 @@ func (r *AccommodationRepository) GetStats() (map[string]interface{}, error) {
 	}
 	stats["by_source"] = sourceStats
 
-	// Average rating
+	// By region, places outside every known region and those without coordinates are left out
+	regionRows, err := r.db.Query(`
+		SELECT region, COUNT(*)
+		FROM merged_accommodations
+		WHERE deleted_at IS NULL AND region IS NOT NULL
+		GROUP BY region`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region stats: %w", err)
+	}
+	defer regionRows.Close()
+
+	regionStats := make(map[string]int)
+	for regionRows.Next() {
+		var region string
+		var count int
+		if err := regionRows.Scan(&region, &count); err == nil {
+			regionStats[region] = count
+		}
+	}
+	stats["by_region"] = regionStats
+
+	// Average rating over places, from the rating of their merged records
 	var avgRating sql.NullFloat64
-	err = r.db.QueryRow("SELECT AVG(rating) FROM accommodations WHERE deleted_at IS NULL AND rating IS NOT NULL").Scan(&avgRating)
+	err = r.db.QueryRow(`
+		SELECT AVG(rating) FROM merged_accommodations
+		WHERE deleted_at IS NULL AND rating IS NOT NULL`).Scan(&avgRating)
 	if err == nil && avgRating.Valid {
 		stats["average_rating"] = avgRating.Float64
 	}
 
 	return stats, nil
 }
+
+// openAtFilter returns the moment accommodations must be open at, taken from the
+// "open_at" filter (RFC 3339) or the current time when "open_now" is true
+func openAtFilter(filters map[string]interface{}) (time.Time, bool, error) {
+	if value, ok := filters["open_at"]; ok {
+		openAtStr, ok := value.(string)
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("open_at filter must be an RFC 3339 string")
+		}
+		openAt, err := time.Parse(time.RFC3339, openAtStr)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid open_at filter: %w", err)
+		}
+		return openAt, true, nil
+	}
+
+	if openNow, ok := filters["open_now"].(bool); ok && openNow {
+		return time.Now(), true, nil
+	}
+
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"ai_analyzer/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrAccommodationNotFound is returned when the requested accommodation does not exist
+var ErrAccommodationNotFound = errors.New("accommodation not found")
+
+// GetHistory returns the field changes of an accommodation, newest first. Soft-deleted
+// accommodations keep their history. An empty fields list returns changes of all fields.
+func (r *AccommodationRepository) GetHistory(id int, fields []string, limit int) ([]models.AccommodationHistory, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM accommodations WHERE id = $1)", id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check accommodation: %w", err)
+	}
+	if !exists {
+		return nil, ErrAccommodationNotFound
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, accommodation_id, field, old_value, new_value, source_website, run_id, changed_at
+		FROM accommodation_history
+		WHERE accommodation_id = $1 AND (cardinality($2::text[]) = 0 OR field = ANY($2))
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $3`, id, pq.Array(fields), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accommodation history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.AccommodationHistory{}
+	for rows.Next() {
+		var entry models.AccommodationHistory
+		if err := rows.Scan(
+			&entry.ID, &entry.AccommodationID, &entry.Field, &entry.OldValue, &entry.NewValue,
+			&entry.SourceWebsite, &entry.RunID, &entry.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan accommodation history: %w", err)
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read accommodation history: %w", err)
+	}
+
+	return history, nil
+}
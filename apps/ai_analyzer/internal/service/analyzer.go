@@ -214,6 +214,14 @@ func (s *AnalyzerService) EvaluateAccommodation(ctx context.Context, id int) (*m
 	}, nil
 }
 
+// GetAccommodationHistory returns the recorded field changes of an accommodation, newest first
+func (s *AnalyzerService) GetAccommodationHistory(ctx context.Context, id int, fields []string, limit int) ([]models.AccommodationHistory, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.repo.GetHistory(id, fields, limit)
+}
+
 func (s *AnalyzerService) prepareDataForAI(accommodations []models.Accommodation, stats map[string]interface{}) map[string]interface{} {
 	return map[string]interface{}{
 		"accommodations": accommodations,
@@ -301,7 +309,7 @@ func getFirstPhoto(photos models.JSONB) string {
 
 	// Handle case where photos is a JSON array directly
 	if photoArray, ok := data.([]interface{}); ok && len(photoArray) > 0 {
-		if photo, ok := photoArray[0].(string); ok {
+		if photo := photoURL(photoArray[0]); photo != "" {
 			return photo
 		}
 	}
@@ -309,7 +317,7 @@ func getFirstPhoto(photos models.JSONB) string {
 	// Handle case where photos is a JSON object with a "photos" key
 	if photoMap, ok := data.(map[string]interface{}); ok {
 		if photoList, ok := photoMap["photos"].([]interface{}); ok && len(photoList) > 0 {
-			if photo, ok := photoList[0].(string); ok {
+			if photo := photoURL(photoList[0]); photo != "" {
 				return photo
 			}
 		}
@@ -318,6 +326,19 @@ func getFirstPhoto(photos models.JSONB) string {
 	return "нет"
 }
 
+// Helper function to get the URL of a photo stored either as a string or as an object with a "url" key
+func photoURL(photo interface{}) string {
+	switch value := photo.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		if url, ok := value["url"].(string); ok {
+			return url
+		}
+	}
+	return ""
+}
+
 // Helper function to safely get string value from pointer
 func getStringValue(ptr *string) string {
 	if ptr == nil {
@@ -7,6 +7,7 @@ import (
 	"ai_analyzer/internal/service"
 	"database/sql"
 	"log"
+	"mytravel/pkg/schema"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,10 @@ func main() {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("Incompatible database schema: %v", err)
+	}
+
 	// Initialize dependencies
 	repo := repository.NewAccommodationRepository(db)
 	aiService := service.NewOpenAIService(cfg.OpenAIAPIKey)
@@ -54,6 +59,7 @@ func main() {
 		api.POST("/analyze", handler.AnalyzeAccommodations)
 		api.GET("/accommodations", handler.GetAccommodations)
 		api.GET("/accommodations/:id", handler.GetAccommodationByID)
+		api.GET("/accommodations/:id/history", handler.GetAccommodationHistory)
 		api.POST("/analyze/accommodation/:id", handler.AnalyzeSingleAccommodation)
 
 		// New convenient endpoints that don't require request body
@@ -8,4 +8,22 @@ require (
 	github.com/andybalholm/brotli v1.2.0
 	github.com/lib/pq v1.10.9
 	golang.org/x/text v0.30.0
+	mytravel/pkg v0.0.0
 )
+
+require github.com/robfig/cron/v3 v3.0.1 // indirect
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_golang v1.23.0 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.65.0 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
@@ -0,0 +1,14 @@
+package store
+
+// locate fills the region, city and region KATO code of a record from its coordinates
+func (ps *PostgresStore) locate(accommodation *AccommodationRecord) {
+	accommodation.Region, accommodation.City, accommodation.RegionKATO = nil, nil, nil
+	if accommodation.Latitude == nil || accommodation.Longitude == nil {
+		return
+	}
+
+	location := ps.boundaries.Locate(*accommodation.Latitude, *accommodation.Longitude)
+	accommodation.Region = ps.safeStringPointer(location.Region)
+	accommodation.City = ps.safeStringPointer(location.City)
+	accommodation.RegionKATO = ps.safeStringPointer(location.RegionKATO)
+}
@@ -1,11 +1,18 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"hacknu/internal/config"
 	"hacknu/internal/logger"
+	"mytravel/pkg/geo"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/normalize"
+	"mytravel/pkg/runs"
+	"mytravel/pkg/schema"
+	"mytravel/pkg/taxonomy"
 	"strings"
 	"time"
 
@@ -15,6 +22,11 @@ import (
 type PostgresStore struct {
 	db     *sql.DB
 	logger *logger.Logger
+	types  *taxonomy.Mapping // Booking.com property type to accommodation type mapping
+	run    *runs.Run         // parsing run writes are linked to, see StartRun
+	runID  *string           // ID of run, written to last_run_id and parsing_logs.run_id
+
+	boundaries *geo.Boundaries // regions and cities records are located in
 }
 
 // NewPostgresStore creates a new PostgreSQL store instance with connection pooling
@@ -37,14 +49,46 @@ func NewPostgresStore(cfg config.DatabaseConfig, logger *logger.Logger) (*Postgr
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if err := schema.Check(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	logger.Info("Successfully connected to PostgreSQL database")
 
+	types, err := taxonomy.ForSource("booking")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load accommodation types: %w", err)
+	}
+
+	boundaries, err := geo.Load()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load region boundaries: %w", err)
+	}
+
 	return &PostgresStore{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		types:      types,
+		boundaries: boundaries,
 	}, nil
 }
 
+// StartRun opens a parsing run in parsing_runs and links the following writes to it.
+// Stored properties are counted in the run, accommodations get last_run_id set, which the
+// accommodation_history trigger records next to every changed field.
+func (ps *PostgresStore) StartRun(runID string) (*runs.Run, error) {
+	run, err := runs.Start(context.Background(), ps.db, "booking", runID)
+	if err != nil {
+		return nil, err
+	}
+	ps.run = run
+	ps.runID = &run.ID
+	return run, nil
+}
+
 // Close closes the database connection
 func (ps *PostgresStore) Close() error {
 	return ps.db.Close()
@@ -101,6 +145,11 @@ type AccommodationRecord struct {
 	SourceWebsite      string
 	SourceURL          *string
 	ExternalID         string
+	SourceCategories   []byte // raw property types the accommodation type was mapped from
+	TaxonomyVersion    int
+	Region             *string // located from the coordinates, see locate
+	City               *string
+	RegionKATO         *string
 }
 
 // InsertBookingProperty inserts a BookingProperty into the accommodations table
@@ -116,6 +165,8 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 	if err := ps.validateJSONFields(accommodation); err != nil {
 		ps.logger.Error("Invalid JSON data for booking property %s: %v", property.PageName, err)
 		ps.logBusinessInsertion("booking", property.PageName, "insert", "failed", fmt.Sprintf("Invalid JSON data: %v", err), startTime)
+		ps.run.AddOutcome("failed")
+		ps.run.AddError(fmt.Errorf("property %s: invalid JSON data: %w", property.PageName, err))
 		return fmt.Errorf("invalid JSON data: %w", err)
 	}
 
@@ -126,13 +177,17 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 			phone, email, social_media_links, website_url, social_media_page,
 			service_description, room_count, capacity, price_range_min, price_range_max,
 			photos, rating, review_count, reviews, amenities,
-			verification_status, source_website, source_url, external_id
+			verification_status, source_website, source_url, external_id,
+			source_categories, taxonomy_version, last_run_id, region, city,
+			region_kato
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9, $10,
 			$11, $12, $13, $14, $15,
 			$16, $17, $18, $19, $20,
-			$21, $22, $23, $24
+			$21, $22, $23, $24,
+			$25, $26, $27, $28, $29,
+			$30
 		)
 		ON CONFLICT (source_website, external_id) 
 		DO UPDATE SET
@@ -157,6 +212,12 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 			reviews = EXCLUDED.reviews,
 			amenities = EXCLUDED.amenities,
 			verification_status = EXCLUDED.verification_status,
+			source_categories = EXCLUDED.source_categories,
+			taxonomy_version = EXCLUDED.taxonomy_version,
+			last_run_id = EXCLUDED.last_run_id,
+			region = EXCLUDED.region,
+			city = EXCLUDED.city,
+			region_kato = EXCLUDED.region_kato,
 			last_updated = CURRENT_TIMESTAMP
 		RETURNING (xmax = 0) AS was_insert
 	`
@@ -187,12 +248,20 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 		"booking",
 		accommodation.SourceURL,
 		accommodation.ExternalID,
+		ps.safeJSONBytes(accommodation.SourceCategories),
+		accommodation.TaxonomyVersion,
+		ps.runID,
+		accommodation.Region,
+		accommodation.City,
+		accommodation.RegionKATO,
 	).Scan(&wasInsert)
 
 	if err != nil {
 		ps.logger.Error("Failed to insert/update booking property %s: %v", property.PageName, err)
 		operation := "insert"
 		ps.logBusinessInsertion("booking", property.PageName, operation, "failed", fmt.Sprintf("Database error: %v", err), startTime)
+		ps.run.AddOutcome("failed")
+		ps.run.AddError(fmt.Errorf("property %s: %w", property.PageName, err))
 		return fmt.Errorf("failed to insert/update booking property: %w", err)
 	}
 
@@ -203,6 +272,7 @@ func (ps *PostgresStore) InsertBookingProperty(property BookingProperty) error {
 
 	ps.logger.Info("Successfully %sed accommodation: %s (PageName: %s)", operation, property.PropertyName, property.PageName)
 	ps.logBusinessInsertion("booking", property.PageName, operation, "success", "", startTime)
+	ps.run.AddOutcome(operation)
 	return nil
 }
 
@@ -215,8 +285,11 @@ func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) e
 	successCount := 0
 	errorCount := 0
 
+	metrics.AddQueued("booking", len(properties))
 	for _, property := range properties {
-		if err := ps.InsertBookingProperty(property); err != nil {
+		err := ps.InsertBookingProperty(property)
+		metrics.AddQueued("booking", -1)
+		if err != nil {
 			errorCount++
 		} else {
 			successCount++
@@ -226,6 +299,9 @@ func (ps *PostgresStore) InsertBookingProperties(properties []BookingProperty) e
 	ps.logger.Info("Booking processing completed: %d successful, %d failed out of %d total",
 		successCount, errorCount, len(properties))
 
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d properties failed to store", errorCount, len(properties))
+	}
 	return nil
 }
 
@@ -240,6 +316,9 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 	// Convert facilities to amenities JSON
 	amenitiesJSON := ps.convertBookingFacilitiesToAmenities(property.Facilities)
 
+	// Map the Booking.com property type to a canonical accommodation type
+	accommodationType := ps.types.Map(property.AccommodationType)
+
 	// Extract rating from reviews_ratings string
 	rating := ps.parseBookingRating(property.ReviewsRatings)
 
@@ -250,15 +329,19 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 		websiteURL = &url
 	}
 
-	return AccommodationRecord{
+	// Booking.com has no contact fields, hosts sometimes leave a phone or email in the description
+	var contacts normalize.Contacts
+	contacts.AddText(property.Description)
+
+	accommodation := AccommodationRecord{
 		Name:               property.PropertyName,
 		Latitude:           ps.safeFloat64Pointer(property.Latitude),
 		Longitude:          ps.safeFloat64Pointer(property.Longitude),
 		Address:            ps.safeStringPointer(property.Address),
-		AccommodationType:  ps.safeStringPointer(property.AccommodationType),
-		Phone:              nil,
-		Email:              nil,
-		SocialMediaLinks:   nil,
+		AccommodationType:  &accommodationType,
+		Phone:              ps.safeStringPointer(contacts.Phone()),
+		Email:              ps.safeStringPointer(contacts.Email(100)),
+		SocialMediaLinks:   ps.convertContactsToJSON(&contacts),
 		WebsiteURL:         websiteURL,
 		SocialMediaPage:    nil,
 		ServiceDescription: ps.safeStringPointer(property.Description),
@@ -275,11 +358,30 @@ func (ps *PostgresStore) convertBookingPropertyToAccommodation(property BookingP
 		SourceWebsite:      "booking",
 		SourceURL:          websiteURL,
 		ExternalID:         property.PageName,
+		SourceCategories:   ps.sourceCategoriesJSON(property.AccommodationType),
+		TaxonomyVersion:    ps.types.Version,
 	}
+	ps.locate(&accommodation)
+	return accommodation
 }
 
 // Helper methods
 
+// convertContactsToJSON returns the social_media_links JSON of normalized contacts, nil without any
+func (ps *PostgresStore) convertContactsToJSON(contacts *normalize.Contacts) []byte {
+	links := contacts.Links()
+	if links == nil {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(links)
+	if err != nil {
+		ps.logger.Error("Failed to marshal contacts JSON: %v", err)
+		return nil
+	}
+	return jsonData
+}
+
 func (ps *PostgresStore) convertBookingPhotosToJSON(photos []string) []byte {
 	if len(photos) == 0 {
 		return nil
@@ -480,6 +582,12 @@ func (ps *PostgresStore) validateJSONFields(accommodation AccommodationRecord) e
 }
 
 func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operation, status, errorMessage string, startTime time.Time) {
+	outcome := operation
+	if status != "success" {
+		outcome = "failed"
+	}
+	metrics.AddUpserts(sourceWebsite, outcome, 1)
+
 	completedAt := time.Now()
 	duration := int(completedAt.Sub(startTime).Milliseconds())
 
@@ -490,8 +598,8 @@ func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operati
 
 	query := `
 		INSERT INTO parsing_logs (
-			source_website, operation, status, error_message, started_at, completed_at, duration_ms, external_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			source_website, operation, status, error_message, started_at, completed_at, duration_ms, external_id, run_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := ps.db.Exec(query,
@@ -503,6 +611,7 @@ func (ps *PostgresStore) logBusinessInsertion(sourceWebsite, externalID, operati
 		completedAt,
 		duration,
 		externalID,
+		ps.runID,
 	)
 
 	if err != nil {
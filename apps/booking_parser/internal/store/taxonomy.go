@@ -0,0 +1,104 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RemapSummary reports the outcome of re-mapping accommodation types of Booking.com rows
+type RemapSummary struct {
+	Version int
+	Checked int // rows mapped with an older version, or all rows when forced
+	Changed int // rows whose accommodation type changed
+	Updated int // rows written, including version-only updates
+}
+
+// sourceCategoriesJSON stores the raw property type so rows can be re-mapped later
+func (ps *PostgresStore) sourceCategoriesJSON(propertyType string) []byte {
+	propertyType = strings.TrimSpace(propertyType)
+	if propertyType == "" {
+		return nil
+	}
+
+	jsonData, err := json.Marshal([]string{propertyType})
+	if err != nil {
+		ps.logger.Error("Failed to marshal source categories JSON: %v", err)
+		return nil
+	}
+	return jsonData
+}
+
+// RemapAccommodationTypes re-maps accommodation types of Booking.com rows with the loaded mapping.
+// Rows already mapped with the current version are skipped unless force is set. Rows written
+// before source categories were stored are re-mapped from their current accommodation type.
+func (ps *PostgresStore) RemapAccommodationTypes(force bool) (RemapSummary, error) {
+	summary := RemapSummary{Version: ps.types.Version}
+
+	rows, err := ps.db.Query(`
+		SELECT id, accommodation_type, source_categories
+		FROM accommodations
+		WHERE source_website = 'booking'
+		  AND ($1 OR taxonomy_version IS DISTINCT FROM $2)
+	`, force, ps.types.Version)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query accommodations to re-map: %w", err)
+	}
+
+	updates := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var currentType, sourceCategories sql.NullString
+		if err := rows.Scan(&id, &currentType, &sourceCategories); err != nil {
+			rows.Close()
+			return summary, fmt.Errorf("failed to scan accommodation to re-map: %w", err)
+		}
+		summary.Checked++
+
+		var categories []string
+		if sourceCategories.Valid {
+			if err := json.Unmarshal([]byte(sourceCategories.String), &categories); err != nil {
+				ps.logger.Error("Invalid source categories for accommodation %d: %v", id, err)
+			}
+		}
+		if len(categories) == 0 && currentType.Valid {
+			categories = []string{currentType.String}
+		}
+
+		accommodationType := ps.types.Map(categories...)
+		if !currentType.Valid || currentType.String != accommodationType {
+			summary.Changed++
+		}
+		updates[id] = accommodationType
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return summary, fmt.Errorf("failed to read accommodations to re-map: %w", err)
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return summary, fmt.Errorf("failed to begin re-map transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for id, accommodationType := range updates {
+		result, err := tx.Exec(`
+			UPDATE accommodations
+			SET accommodation_type = $1, taxonomy_version = $2
+			WHERE id = $3 AND (accommodation_type IS DISTINCT FROM $1 OR taxonomy_version IS DISTINCT FROM $2)
+		`, accommodationType, ps.types.Version, id)
+		if err != nil {
+			return summary, fmt.Errorf("failed to re-map accommodation %d: %w", id, err)
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			summary.Updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, fmt.Errorf("failed to commit re-map transaction: %w", err)
+	}
+	return summary, nil
+}
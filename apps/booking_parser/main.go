@@ -1,20 +1,45 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"hacknu/internal/config"
 	"hacknu/internal/logger"
 	"hacknu/internal/store"
 	"hacknu/parser"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/runs"
+	"mytravel/pkg/schedule"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
 const (
 	// Updated URL for Almaty (destination ID: -2335204)
 	summaryURL = "https://www.booking.com/searchresults.html?dest_id=-2335204&dest_type=city&nflt=ht_id%3D213%3Bht_id%3D220%3Bht_id%3D214%3Bht_id%3D216"
+
+	// requestDelay is the pause between property pages
+	requestDelay = 3 * time.Second
+
+	// defaultSchedule runs the parser daily at 03:00 (UTC in the container)
+	defaultSchedule = "0 3 * * *"
 )
 
 func main() {
+	remapTypes := flag.Bool("remap-types", false, "Re-map accommodation types of existing Booking.com rows and exit")
+	remapAll := flag.Bool("remap-all", false, "With -remap-types, re-map rows already mapped with the current taxonomy version")
+	metricsAddr := flag.String("metrics-addr", metrics.Addr(), "Address to serve Prometheus /metrics on while parsing, empty to disable")
+	scheduleSpec := flag.String("schedule", schedule.Spec(defaultSchedule), "Cron expression or descriptor (e.g. \"0 3 * * *\", \"@every 12h\") parser runs are scheduled with")
+	jitter := flag.Duration("jitter", 10*time.Minute, "Maximum random delay added to every scheduled run")
+	runOnStart := flag.Bool("run-on-start", false, "Start the first run immediately instead of at the first scheduled time")
+	controlAddr := flag.String("control-addr", schedule.ControlAddr(), "Address to serve the run control endpoint on, empty to disable")
+	once := flag.Bool("once", false, "Run the parser once and exit instead of following the schedule")
+	flag.Parse()
+
 	fmt.Println("📍 Starting Booking.com parser for Almaty with database integration")
 
 	// Initialize logger
@@ -32,18 +57,75 @@ func main() {
 
 	logger.Info("Database connection established successfully")
 
+	if *remapTypes {
+		summary, err := dbStore.RemapAccommodationTypes(*remapAll)
+		if err != nil {
+			logger.Fatal("Failed to re-map accommodation types: %v", err)
+		}
+		logger.Info("Re-mapped booking rows to taxonomy version %d: %d checked, %d changed type, %d updated",
+			summary.Version, summary.Checked, summary.Changed, summary.Updated)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := metrics.Serve(ctx, *metricsAddr); err != nil {
+			logger.Error("Failed to serve metrics on %s: %v", *metricsAddr, err)
+		}
+	}()
+
+	if *once {
+		if err := runParser(ctx, dbStore, logger); err != nil {
+			logger.Fatal("Parser run failed: %v", err)
+		}
+		return
+	}
+
+	scheduler, err := schedule.New(*scheduleSpec, *jitter, func(ctx context.Context) error {
+		return runParser(ctx, dbStore, logger)
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to create scheduler: %v", err)
+	}
+
+	go func() {
+		if err := scheduler.Serve(ctx, *controlAddr); err != nil {
+			logger.Error("Failed to serve run control on %s: %v", *controlAddr, err)
+		}
+	}()
+
+	logger.Info("Scheduling parser runs with %q, jitter up to %v", *scheduleSpec, *jitter)
+	scheduler.Run(ctx, *runOnStart)
+	logger.Info("Shutdown requested, parser stopped")
+}
+
+// runParser fetches the summary page and the details of every listed property and stores
+// them as one parsing run
+func runParser(ctx context.Context, dbStore *store.PostgresStore, logger *logger.Logger) error {
 	startTime := time.Now()
+	run, err := dbStore.StartRun(runs.NewID())
+	if err != nil {
+		return err
+	}
+	logger.Info("Starting parser run %s", run.ID)
 
 	// Step 1. Fetch summary list (main search page)
+	run.Add(runs.Counts{APICalls: 1})
 	properties, err := parser.FetchSummary(summaryURL)
 	if err != nil {
-		logger.Fatal("Failed to fetch summary: %v", err)
+		run.AddError(err)
+		finishRun(run, logger, runs.StatusFailed)
+		return fmt.Errorf("failed to fetch summary: %w", err)
 	}
 
 	logger.Info("Found %d properties from summary page", len(properties))
+	run.Add(runs.Counts{Fetched: len(properties)})
 
 	if len(properties) == 0 {
-		logger.Fatal("No properties found. Exiting.")
+		finishRun(run, logger, runs.StatusFailed)
+		return errors.New("no properties found on the summary page")
 	}
 
 	var finalData []store.BookingProperty
@@ -52,6 +134,11 @@ func main() {
 
 	// Step 2. Iterate and fetch details for each property with retries
 	for i, prop := range properties {
+		if err := ctx.Err(); err != nil {
+			finishRun(run, logger, runs.StatusInterrupted)
+			return err
+		}
+
 		logger.Info("[%d/%d] Processing property: %s", i+1, len(properties), prop.PropertyName)
 
 		url := fmt.Sprintf("https://www.booking.com/hotel/kz/%s.html", prop.PageName)
@@ -61,6 +148,7 @@ func main() {
 
 		maxRetries := 3
 		for attempt := 1; attempt <= maxRetries; attempt++ {
+			run.Add(runs.Counts{APICalls: 1})
 			detail, err = parser.ExtractPropertyDetails(url, prop.Description, prop.ReviewsRatings, prop.ReviewsCount)
 			if err == nil && detail != nil {
 				break
@@ -70,12 +158,15 @@ func main() {
 			if attempt < maxRetries {
 				backoff := time.Duration(2*attempt) * time.Second
 				logger.Info("Retrying in %v...", backoff)
+				metrics.ObserveRateLimitWait("booking", backoff)
 				time.Sleep(backoff)
 			}
 		}
 
 		if err != nil || detail == nil {
 			logger.Error("Skipping after %d attempts: %s", maxRetries, url)
+			run.Add(runs.Counts{Failed: 1})
+			run.AddError(fmt.Errorf("property %s: no details after %d attempts: %v", prop.PageName, maxRetries, err))
 			errorCount++
 			continue
 		}
@@ -86,7 +177,8 @@ func main() {
 		successCount++
 
 		// Add delay between requests to be respectful to booking.com
-		time.Sleep(3 * time.Second)
+		metrics.ObserveRateLimitWait("booking", requestDelay)
+		time.Sleep(requestDelay)
 	}
 
 	logger.Info("Parsing completed: %d successful, %d failed out of %d total", successCount, errorCount, len(properties))
@@ -113,6 +205,23 @@ func main() {
 	} else {
 		logger.Warn("No valid property details were parsed.")
 	}
+
+	finishRun(run, logger, runs.StatusCompleted)
+	return nil
+}
+
+// finishRun closes the parsing run with the given status and logs its totals
+func finishRun(run *runs.Run, logger *logger.Logger, status string) {
+	if err := run.Finish(context.Background(), status); err != nil {
+		logger.Error("Failed to finish parsing run %s: %v", run.ID, err)
+	}
+	if status == runs.StatusCompleted {
+		metrics.SetLastSuccessfulRun(run.Source)
+	}
+
+	counts := run.Counts()
+	logger.Info("Run %s %s: %d fetched, %d inserted, %d updated, %d failed, %d requests",
+		run.ID, status, counts.Fetched, counts.Inserted, counts.Updated, counts.Failed, counts.APICalls)
 }
 
 // convertToBookingProperty converts parser.DetailedProperty to store.BookingProperty
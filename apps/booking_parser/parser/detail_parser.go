@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mytravel/pkg/metrics"
 	"net/http"
 	"regexp"
 	"strings"
@@ -52,10 +53,13 @@ func ExtractPropertyDetails(pageURL, defaultDescription, defaultRating string, d
 	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
 	req.Header.Set("Connection", "keep-alive")
 
+	requestStart := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
+		metrics.ObserveRequest(metricsSource, "hotel", 0, time.Since(requestStart))
 		return nil, fmt.Errorf("fetch: %v", err)
 	}
+	metrics.ObserveRequest(metricsSource, "hotel", resp.StatusCode, time.Since(requestStart))
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
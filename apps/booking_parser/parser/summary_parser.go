@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mytravel/pkg/metrics"
 	"net/http"
 	"regexp"
 	"strings"
@@ -13,6 +14,9 @@ import (
 	"github.com/andybalholm/brotli"
 )
 
+// metricsSource is the source label of Booking.com request metrics
+const metricsSource = "booking"
+
 type SummaryProperty struct {
 	PropertyName   string `json:"property_name"`
 	PageName       string `json:"page_name"`
@@ -52,10 +56,13 @@ func FetchSummary(url string) ([]SummaryProperty, error) {
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36")
 	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
 
+	requestStart := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
+		metrics.ObserveRequest(metricsSource, "searchresults", 0, time.Since(requestStart))
 		return nil, fmt.Errorf("fetch summary: %v", err)
 	}
+	metrics.ObserveRequest(metricsSource, "searchresults", resp.StatusCode, time.Since(requestStart))
 	defer resp.Body.Close()
 
 	var reader io.Reader = resp.Body
@@ -1,8 +1,12 @@
 module google_maps_parser
 
-go 1.21
+go 1.23.0
 
 require (
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
+	mytravel/pkg v0.0.0
 )
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
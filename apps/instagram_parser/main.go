@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"mytravel/pkg/schema"
 )
 
 func main() {
@@ -39,6 +41,10 @@ func main() {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("Incompatible database schema: %v", err)
+	}
+
 	log.Println("Instagram Parser started successfully")
 
 	// Simple counter that runs every 60 seconds
@@ -47,17 +53,9 @@ func main() {
 		counter++
 		log.Printf("Instagram Parser iteration #%d", counter)
 
-		// Log parsing activity
-		_, err := db.Exec(`
-			INSERT INTO parsing_logs (source_website, status, records_processed, started_at) 
-			VALUES ($1, $2, $3, $4)`,
-			"instagram", "completed", counter, time.Now())
-
-		if err != nil {
-			log.Printf("Error inserting parsing log: %v", err)
-		} else {
-			log.Printf("Successfully logged parsing iteration #%d", counter)
-		}
+		startedAt := time.Now()
+		inserted, failed := 0, 0
+		var errorSamples []string
 
 		// Add test accommodation every 4th iteration
 		if counter%4 == 0 {
@@ -73,11 +71,31 @@ func main() {
 
 			if err != nil {
 				log.Printf("Error inserting test accommodation: %v", err)
+				failed++
+				errorSamples = append(errorSamples, err.Error())
 			} else {
 				log.Printf("Successfully created test accommodation #%d", counter)
+				inserted++
 			}
 		}
 
+		// Record the iteration as a parsing run
+		samples, _ := json.Marshal(errorSamples)
+		if errorSamples == nil {
+			samples = []byte("[]")
+		}
+		_, err := db.Exec(`
+			INSERT INTO parsing_runs (run_id, source_website, status, started_at, finished_at, fetched, inserted, failed, error_samples)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			startedAt.UTC().Format("20060102T150405Z"), "instagram", "completed", startedAt, time.Now(),
+			inserted+failed, inserted, failed, string(samples))
+
+		if err != nil {
+			log.Printf("Error recording parsing run: %v", err)
+		} else {
+			log.Printf("Successfully recorded parsing iteration #%d", counter)
+		}
+
 		time.Sleep(60 * time.Second)
 	}
 }
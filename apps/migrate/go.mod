@@ -0,0 +1,5 @@
+module migrate
+
+go 1.24.3
+
+require github.com/lib/pq v1.10.9
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: migrate [-dir path] <command> [arg]
+
+Commands:
+  up [version]   apply pending migrations, up to and including version when given
+  down [steps]   revert the last steps migrations (default: 1)
+  status         list migrations and whether they are applied
+  version        print the current schema version
+`
+
+func main() {
+	dir := flag.String("dir", getEnv("MIGRATIONS_DIR", "../../infrastructure/database/migrations"), "Directory with <version>_<name>.up.sql and .down.sql files")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	arg, err := intArg(flag.Arg(1))
+	if err != nil {
+		log.Fatalf("Invalid argument %q: %v", flag.Arg(1), err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	migrations, err := loadMigrations(*dir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnv("DB_HOST", "localhost"), getEnv("DB_PORT", "5434"), getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"), getEnv("DB_NAME", "mytravel_db"), getEnv("DB_SSLMODE", "disable"))
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(ctx, db, migrations)
+	if err != nil {
+		log.Fatalf("Failed to prepare migrations: %v", err)
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		done, err := migrator.Up(ctx, arg)
+		for _, migration := range done {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to migrate up: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+	case "down":
+		if arg == 0 {
+			arg = 1
+		}
+		done, err := migrator.Down(ctx, arg)
+		for _, migration := range done {
+			fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to migrate down: %v", err)
+		}
+	case "status":
+		if err := printStatus(ctx, migrator, migrations); err != nil {
+			log.Fatalf("Failed to read status: %v", err)
+		}
+	case "version":
+		applied, err := migrator.Applied(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read version: %v", err)
+		}
+		version := 0
+		if len(applied) > 0 {
+			version = applied[len(applied)-1].Version
+		}
+		fmt.Println(version)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printStatus lists every migration with the time it was applied
+func printStatus(ctx context.Context, migrator *Migrator, migrations []Migration) error {
+	applied, err := migrator.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	appliedAt := make(map[int]string, len(applied))
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt.Format("2006-01-02 15:04:05 MST")
+	}
+
+	for _, migration := range migrations {
+		status, ok := appliedAt[migration.Version]
+		if !ok {
+			status = "pending"
+		}
+		fmt.Printf("%04d_%-40s %s\n", migration.Version, migration.Name, status)
+	}
+	return nil
+}
+
+func intArg(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a non-negative number")
+	}
+	return n, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockID is the advisory lock held while migrating, so concurrent migrate runs wait for each other
+const lockID = 72010021
+
+// migrationFile matches <version>_<name>.up.sql and <version>_<name>.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a schema change with the SQL files applying and reverting it
+type Migration struct {
+	Version  int
+	Name     string
+	UpFile   string
+	DownFile string
+}
+
+// AppliedMigration is a row of schema_migrations
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// loadMigrations reads the migrations of dir ordered by version. Every version needs both an
+// up and a down file.
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] == "up" {
+			migration.UpFile = path
+		} else {
+			migration.DownFile = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpFile == "" || migration.DownFile == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations found in %s", dir)
+	}
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations over a single connection holding the migration lock
+type Migrator struct {
+	conn       *sql.Conn
+	migrations []Migration
+}
+
+// newMigrator takes the migration lock and makes sure schema_migrations exists. A database whose
+// schema was created from init.sql before it was versioned is baselined at the first migration.
+func newMigrator(ctx context.Context, db *sql.DB, migrations []Migration) (*Migrator, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	m := &Migrator{conn: conn, migrations: migrations}
+	if err := m.ensureVersionTable(ctx); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Close releases the migration lock
+func (m *Migrator) Close() error {
+	m.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+	return m.conn.Close()
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	_, err := m.conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    integer PRIMARY KEY,
+			name       varchar(255) NOT NULL,
+			applied_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var versioned, unversionedSchema bool
+	err = m.conn.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM schema_migrations), to_regclass('public.accommodations') IS NOT NULL
+	`).Scan(&versioned, &unversionedSchema)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	if versioned || !unversionedSchema {
+		return nil
+	}
+
+	baseline := m.migrations[0]
+	if _, err := m.conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+		baseline.Version, baseline.Name); err != nil {
+		return fmt.Errorf("failed to baseline existing schema: %w", err)
+	}
+	fmt.Printf("Existing schema without version found, baselined at %04d_%s\n", baseline.Version, baseline.Name)
+	return nil
+}
+
+// Applied returns the applied migrations ordered by version
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	rows, err := m.conn.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var migration AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, rows.Err()
+}
+
+// Up applies the pending migrations up to and including target, all of them when target is 0.
+// Every migration runs in its own transaction together with its schema_migrations row.
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if applied[migration.Version] || (target > 0 && migration.Version > target) {
+			continue
+		}
+		if err := m.run(ctx, migration.UpFile, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			migration.Version, migration.Name); err != nil {
+			return done, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if !applied[migration.Version] {
+			continue
+		}
+		if err := m.run(ctx, migration.DownFile, `DELETE FROM schema_migrations WHERE version = $1`,
+			migration.Version); err != nil {
+			return done, fmt.Errorf("reverting %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// appliedVersions returns the applied versions and fails on versions without a migration file,
+// which means the database was migrated by a newer release
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+
+	versions := make(map[int]bool, len(applied))
+	for _, migration := range applied {
+		if !known[migration.Version] {
+			return nil, fmt.Errorf("applied migration %04d_%s has no migration file, the database is newer than this release",
+				migration.Version, migration.Name)
+		}
+		versions[migration.Version] = true
+	}
+	return versions, nil
+}
+
+// run executes a migration file and the schema_migrations bookkeeping in one transaction
+func (m *Migrator) run(ctx context.Context, file, bookkeeping string, args ...interface{}) error {
+	script, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"mytravel/pkg/geo"
+)
+
+const usage = `Usage: kzboundaries [flags] <export.geojson>
+
+Converts an OpenStreetMap export of the Kazakhstan administrative boundaries and settlement
+outlines to the layout of kz_boundaries.geojson, written to stdout. The export is a GeoJSON
+feature collection with the OSM tags as properties, e.g.
+
+  osmium tags-filter kazakhstan-latest.osm.pbf r/boundary=administrative r/place=city,town,village -o kz.osm.pbf
+  osmium export kz.osm.pbf --geometry-types=polygon -o kz.geojson
+  go run ./cmd/kzboundaries kz.geojson > geo/kz_boundaries.geojson
+
+Regions are the admin_level 4 boundaries with a KATO code, cities the areas tagged with one of
+the -places. A city belongs to the region its center lies in.
+`
+
+// aliasTags are the tags other names of a city are taken from, values may list several names separated by ";"
+var aliasTags = []string{"name:kk", "old_name", "old_name:ru", "alt_name", "alt_name:ru"}
+
+func main() {
+	tolerance := flag.Float64("tolerance", 0.005, "Degrees outlines are simplified to, 0 keeps every point")
+	places := flag.String("places", "city,town", "Comma separated place tags of the settlements in the city level: city, town or village")
+	katoTag := flag.String("kato-tag", "kato", "Tag with the KATO code of a region")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to read export: %v", err)
+	}
+	options := options{tolerance: *tolerance, places: make(map[string]bool), katoTag: *katoTag}
+	for _, place := range strings.Split(*places, ",") {
+		options.places[strings.TrimSpace(place)] = true
+	}
+
+	features, err := convert(data, options, os.Stderr)
+	if err != nil {
+		log.Fatalf("Failed to convert boundaries: %v", err)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	if err := write(out, features); err != nil {
+		log.Fatalf("Failed to write boundaries: %v", err)
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatalf("Failed to write boundaries: %v", err)
+	}
+}
+
+type options struct {
+	tolerance float64
+	places    map[string]bool
+	katoTag   string
+}
+
+type osmFeature struct {
+	Properties map[string]any `json:"properties"`
+	Geometry   struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// properties of an area in kz_boundaries.geojson
+type properties struct {
+	Level   string      `json:"level"`
+	Name    string      `json:"name"`
+	KATO    string      `json:"kato,omitempty"`
+	Region  string      `json:"region,omitempty"`
+	Place   string      `json:"place,omitempty"`
+	Center  *[2]float64 `json:"center,omitempty"`
+	Aliases []string    `json:"aliases,omitempty"`
+}
+
+type feature struct {
+	Type       string          `json:"type"`
+	Properties properties      `json:"properties"`
+	Geometry   json.RawMessage `json:"geometry"`
+}
+
+// convert returns the regions followed by the cities of an OSM export. Areas that are skipped,
+// such as cities outside every region, are reported to warnings.
+func convert(data []byte, opts options, warnings io.Writer) ([]feature, error) {
+	var collection struct {
+		Features []osmFeature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to decode export: %w", err)
+	}
+
+	var regions, cities []feature
+	for _, osm := range collection.Features {
+		name := tag(osm.Properties, "name:ru")
+		if name == "" {
+			name = tag(osm.Properties, "name")
+		}
+		if name == "" {
+			continue
+		}
+		polygons, err := decodePolygons(osm.Geometry.Type, osm.Geometry.Coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		polygons = simplifyPolygons(polygons, opts.tolerance)
+		if len(polygons) == 0 {
+			fmt.Fprintf(warnings, "skipped %s: nothing left of the outline at tolerance %v\n", name, opts.tolerance)
+			continue
+		}
+		encoded, err := encodeGeometry(polygons)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag(osm.Properties, "admin_level") == "4" {
+			kato := tag(osm.Properties, opts.katoTag)
+			if kato == "" {
+				return nil, fmt.Errorf("region %s has no %s tag", name, opts.katoTag)
+			}
+			regions = append(regions, feature{Type: "Feature", Geometry: encoded,
+				Properties: properties{Level: geo.LevelRegion, Name: name, KATO: kato}})
+		}
+		if place := tag(osm.Properties, "place"); opts.places[place] {
+			center := centroid(polygons)
+			cities = append(cities, feature{Type: "Feature", Geometry: encoded,
+				Properties: properties{Level: geo.LevelCity, Name: name, Place: place, Center: &center,
+					Aliases: aliases(osm.Properties, name)}})
+		}
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("export has no admin_level 4 boundaries")
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Properties.KATO < regions[j].Properties.KATO })
+
+	// Cities take the region their center lies in, resolved against the converted regions
+	regionData, err := json.Marshal(struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: regions})
+	if err != nil {
+		return nil, err
+	}
+	boundaries, err := geo.Parse(regionData, "converted regions")
+	if err != nil {
+		return nil, err
+	}
+	features := regions
+	for _, city := range cities {
+		center := *city.Properties.Center
+		region := boundaries.Locate(center[1], center[0]).Region
+		if region == "" {
+			fmt.Fprintf(warnings, "skipped %s: center %v is outside every region\n", city.Properties.Name, center)
+			continue
+		}
+		city.Properties.Region = region
+		features = append(features, city)
+	}
+	return features, nil
+}
+
+func tag(props map[string]any, key string) string {
+	value, _ := props[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// aliases returns the other names of a city without duplicates
+func aliases(props map[string]any, name string) []string {
+	seen := map[string]bool{strings.ToLower(name): true}
+	var names []string
+	for _, key := range aliasTags {
+		for _, alias := range strings.Split(tag(props, key), ";") {
+			alias = strings.TrimSpace(alias)
+			if alias == "" || seen[strings.ToLower(alias)] {
+				continue
+			}
+			seen[strings.ToLower(alias)] = true
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+func decodePolygons(geometryType string, coordinates json.RawMessage) ([][][][2]float64, error) {
+	switch geometryType {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("invalid polygon: %w", err)
+		}
+		return [][][][2]float64{polygon}, nil
+	case "MultiPolygon":
+		var polygons [][][][2]float64
+		if err := json.Unmarshal(coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid multipolygon: %w", err)
+		}
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("geometry %q, expected Polygon or MultiPolygon", geometryType)
+	}
+}
+
+func encodeGeometry(polygons [][][][2]float64) (json.RawMessage, error) {
+	if len(polygons) == 1 {
+		return json.Marshal(struct {
+			Type        string         `json:"type"`
+			Coordinates [][][2]float64 `json:"coordinates"`
+		}{Type: "Polygon", Coordinates: polygons[0]})
+	}
+	return json.Marshal(struct {
+		Type        string           `json:"type"`
+		Coordinates [][][][2]float64 `json:"coordinates"`
+	}{Type: "MultiPolygon", Coordinates: polygons})
+}
+
+// simplifyPolygons simplifies every ring and rounds it to 4 decimals, about 10 meters. Polygons
+// whose outer ring collapses are dropped, as are collapsed holes.
+func simplifyPolygons(polygons [][][][2]float64, tolerance float64) [][][][2]float64 {
+	var kept [][][][2]float64
+	for _, polygon := range polygons {
+		var rings [][][2]float64
+		for i, ring := range polygon {
+			simplified := simplifyRing(ring, tolerance)
+			if len(simplified) < 4 {
+				if i == 0 {
+					break
+				}
+				continue
+			}
+			rings = append(rings, simplified)
+		}
+		if len(rings) > 0 {
+			kept = append(kept, rings)
+		}
+	}
+	return kept
+}
+
+// simplifyRing drops the points of a closed ring that are closer than tolerance to the line
+// between their neighbours (Douglas-Peucker), the ring stays closed
+func simplifyRing(ring [][2]float64, tolerance float64) [][2]float64 {
+	if len(ring) > 1 && ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	keep := make([]bool, len(ring))
+	if len(ring) > 0 {
+		keep[0], keep[len(ring)-1] = true, true
+	}
+	// A closed ring starts and ends at the same point, so it is split at its farthest point first
+	if len(ring) > 2 {
+		farthest, best := 0, -1.0
+		for i, p := range ring {
+			if d := math.Hypot(p[0]-ring[0][0], p[1]-ring[0][1]); d > best {
+				farthest, best = i, d
+			}
+		}
+		keep[farthest] = true
+		douglasPeucker(ring, 0, farthest, tolerance, keep)
+		douglasPeucker(ring, farthest, len(ring)-1, tolerance, keep)
+	}
+
+	var simplified [][2]float64
+	for i, p := range ring {
+		if !keep[i] {
+			continue
+		}
+		p = [2]float64{round(p[0]), round(p[1])}
+		if len(simplified) > 0 && simplified[len(simplified)-1] == p {
+			continue
+		}
+		simplified = append(simplified, p)
+	}
+	return simplified
+}
+
+func douglasPeucker(points [][2]float64, first, last int, tolerance float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+	farthest, best := -1, tolerance
+	for i := first + 1; i < last; i++ {
+		if d := segmentDistance(points[i], points[first], points[last]); d > best {
+			farthest, best = i, d
+		}
+	}
+	if farthest < 0 {
+		return
+	}
+	keep[farthest] = true
+	douglasPeucker(points, first, farthest, tolerance, keep)
+	douglasPeucker(points, farthest, last, tolerance, keep)
+}
+
+// segmentDistance is the distance of p to the segment from a to b in degrees
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
+
+// centroid returns the longitude and latitude of the center of the largest outer ring
+func centroid(polygons [][][][2]float64) [2]float64 {
+	var center [2]float64
+	largest := -1.0
+	for _, polygon := range polygons {
+		ring := polygon[0]
+		var twiceArea, cx, cy float64
+		for i := range ring {
+			p, q := ring[i], ring[(i+1)%len(ring)]
+			cross := p[0]*q[1] - q[0]*p[1]
+			twiceArea += cross
+			cx += (p[0] + q[0]) * cross
+			cy += (p[1] + q[1]) * cross
+		}
+		if math.Abs(twiceArea) > largest && twiceArea != 0 {
+			largest = math.Abs(twiceArea)
+			center = [2]float64{round(cx / (3 * twiceArea)), round(cy / (3 * twiceArea))}
+		}
+	}
+	return center
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// write encodes the features as kz_boundaries.geojson is laid out, one feature per line
+func write(w io.Writer, features []feature) error {
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`+"\n"); err != nil {
+		return err
+	}
+	for i, f := range features {
+		line, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		if i < len(features)-1 {
+			line = append(line, ',')
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
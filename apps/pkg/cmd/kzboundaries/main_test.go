@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"mytravel/pkg/geo"
+)
+
+// testExport is laid out as osmium export writes it: OSM tags as properties
+const testExport = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"boundary":"administrative","admin_level":"4","name":"Алматы облысы","name:ru":"Алматинская область","kato":"190000000"},
+ "geometry":{"type":"Polygon","coordinates":[[[75,43],[76,43.001],[78,43],[78,45],[75,45],[75,43]]]}},
+{"type":"Feature","properties":{"boundary":"administrative","admin_level":"4","place":"city","name":"Алматы","kato":"750000000","name:kk":"Алматы","old_name":"Алма-Ата;Верный"},
+ "geometry":{"type":"Polygon","coordinates":[[[76.8,43.1],[77.1,43.1],[77.1,43.4],[76.8,43.4],[76.8,43.1]]]}},
+{"type":"Feature","properties":{"boundary":"administrative","admin_level":"6","place":"town","name":"Қонаев","name:ru":"Конаев","old_name:ru":"Капчагай"},
+ "geometry":{"type":"Polygon","coordinates":[[[77,43.8],[77.2,43.8],[77.2,44],[77,44],[77,43.8]]]}},
+{"type":"Feature","properties":{"natural":"water","place":"lake","name":"Алаколь"},
+ "geometry":{"type":"Polygon","coordinates":[[[81,45.9],[82,45.9],[82,46.4],[81,46.4],[81,45.9]]]}},
+{"type":"Feature","properties":{"boundary":"administrative","admin_level":"6","place":"town","name":"Хоргос"},
+ "geometry":{"type":"Polygon","coordinates":[[[80.2,44.1],[80.3,44.1],[80.3,44.3],[80.2,44.3],[80.2,44.1]]]}}
+]}`
+
+func TestConvert(t *testing.T) {
+	var warnings bytes.Buffer
+	opts := options{tolerance: 0.01, places: map[string]bool{"city": true, "town": true}, katoTag: "kato"}
+	features, err := convert([]byte(testExport), opts, &warnings)
+	if err != nil {
+		t.Fatalf("convert() = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := write(&out, features); err != nil {
+		t.Fatalf("write() = %v", err)
+	}
+	b, err := geo.Parse(out.Bytes(), "converted")
+	if err != nil {
+		t.Fatalf("converted boundaries do not parse: %v\n%s", err, out.String())
+	}
+
+	if got := strings.Count(out.String(), "\n"); got != len(features)+2 {
+		t.Fatalf("wrote %d lines for %d features, want one per feature", got, len(features))
+	}
+	if len(features) != 4 {
+		t.Fatalf("converted %d features, want 2 regions and 2 cities", len(features))
+	}
+	// The nearly straight southern border point is simplified away
+	if strings.Contains(out.String(), "43.001") {
+		t.Fatalf("outline was not simplified: %s", out.String())
+	}
+
+	almaty, ok := b.City("Алма-Ата")
+	if !ok || almaty.Name != "Алматы" || almaty.Region != "Алматы" || almaty.Place != "city" {
+		t.Fatalf("City(\"Алма-Ата\") = %+v, %v, want Алматы in its own region", almaty, ok)
+	}
+	konaev, ok := b.City("Капчагай")
+	if !ok || konaev.Name != "Конаев" || konaev.Region != "Алматинская область" {
+		t.Fatalf("City(\"Капчагай\") = %+v, %v, want Конаев in Алматинская область", konaev, ok)
+	}
+	if konaev.Center != [2]float64{77.1, 43.9} {
+		t.Fatalf("Конаев center = %v, want the centroid 77.1, 43.9", konaev.Center)
+	}
+	if _, ok := b.City("Алаколь"); ok {
+		t.Fatalf("the lake Алаколь was converted to a city")
+	}
+	if !strings.Contains(warnings.String(), "Хоргос") {
+		t.Fatalf("warnings = %q, want Хоргос skipped outside every region", warnings.String())
+	}
+}
+
+func TestConvertRequiresKATO(t *testing.T) {
+	export := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"admin_level":"4","name":"Абай облысы"},
+		"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}}]}`
+	opts := options{places: map[string]bool{"city": true}, katoTag: "kato"}
+	if _, err := convert([]byte(export), opts, io.Discard); err == nil || !strings.Contains(err.Error(), "kato") {
+		t.Fatalf("convert() = %v, want an error about the missing kato tag", err)
+	}
+}
+
+func TestSimplifyRing(t *testing.T) {
+	ring := [][2]float64{{0, 0}, {1, 0.00001}, {2, 0}, {2, 2}, {1, 2.5}, {0, 2}, {0, 0}}
+
+	got := simplifyRing(ring, 0.01)
+	want := [][2]float64{{0, 0}, {2, 0}, {2, 2}, {1, 2.5}, {0, 2}, {0, 0}}
+	if len(got) != len(want) {
+		t.Fatalf("simplifyRing() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("simplifyRing() = %v, want %v", got, want)
+		}
+	}
+
+	if got := simplifyRing(ring, 0); len(got) != len(ring) {
+		t.Fatalf("simplifyRing() at tolerance 0 = %v, want every point", got)
+	}
+}
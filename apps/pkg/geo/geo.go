@@ -0,0 +1,267 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// Levels of the areas in a boundaries file
+const (
+	LevelRegion = "region" // oblast or city of republican significance, has a KATO code
+	LevelCity   = "city"   // settlement, names the region it belongs to
+)
+
+// Places a city level area may be, as tagged in OpenStreetMap. Lakes, districts and other areas
+// that are no settlement do not belong in the city level.
+var settlementPlaces = map[string]bool{"city": true, "town": true, "village": true}
+
+// BoundariesFileEnv replaces the embedded boundaries with a GeoJSON file of the same layout,
+// e.g. detailed official outlines
+const BoundariesFileEnv = "KZ_BOUNDARIES_FILE"
+
+// embeddedBoundaries are outlines of the Kazakhstan regions traced by hand along the oblast and
+// state borders, neighbours sharing their edges, and circles around the cities. Points within
+// about 10-20 km of a region border may land in the neighbour; apps/pkg/cmd/kzboundaries converts
+// an OpenStreetMap export to this layout.
+//
+//go:embed kz_boundaries.geojson
+var embeddedBoundaries []byte
+
+// Location is the region and city a point lies in, fields are empty when unknown
+type Location struct {
+	Region     string
+	City       string
+	RegionKATO string // KATO code of the region
+}
+
+// Area is a region or city of the boundaries
+type Area struct {
+	Level   string
+	Name    string
+	KATO    string     // regions only
+	Region  string     // cities only, name of the region
+	Place   string     // cities only, city, town or village
+	Aliases []string   // other names of a city, such as former names
+	Center  [2]float64 // longitude and latitude of a city center, zero for regions
+
+	polygons                       [][][][2]float64 // polygons of rings of longitude, latitude points
+	minLon, minLat, maxLon, maxLat float64
+	size                           float64 // area in square degrees, the smallest containing area wins
+}
+
+// Boundaries resolves points to the regions and cities they lie in
+type Boundaries struct {
+	regions  []*Area
+	cities   []*Area
+	katos    map[string]string // region name to KATO code
+	byName   map[string]*Area  // city names and aliases, lowercased
+	byRegion map[string]*Area  // region names, lowercased
+}
+
+// Load reads the boundaries from BoundariesFileEnv if set, otherwise the embedded ones
+func Load() (*Boundaries, error) {
+	if path := os.Getenv(BoundariesFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read boundaries: %w", err)
+		}
+		return Parse(data, path)
+	}
+	return Parse(embeddedBoundaries, "kz_boundaries.geojson")
+}
+
+type featureCollection struct {
+	Features []struct {
+		Properties struct {
+			Level   string     `json:"level"`
+			Name    string     `json:"name"`
+			KATO    string     `json:"kato"`
+			Region  string     `json:"region"`
+			Place   string     `json:"place"`
+			Aliases []string   `json:"aliases"`
+			Center  [2]float64 `json:"center"`
+		} `json:"properties"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+// Parse decodes and validates a GeoJSON feature collection of Polygon and MultiPolygon areas,
+// name identifies the data in errors
+func Parse(data []byte, name string) (*Boundaries, error) {
+	var collection featureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to decode boundaries %s: %w", name, err)
+	}
+
+	b := &Boundaries{katos: make(map[string]string), byName: make(map[string]*Area), byRegion: make(map[string]*Area)}
+	for i, feature := range collection.Features {
+		props := feature.Properties
+		area := &Area{Level: props.Level, Name: props.Name, KATO: props.KATO, Region: props.Region,
+			Place: props.Place, Aliases: props.Aliases, Center: props.Center}
+		if area.Name == "" {
+			return nil, fmt.Errorf("boundaries %s: feature %d has no name", name, i)
+		}
+
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var polygon [][][2]float64
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &polygon); err != nil {
+				return nil, fmt.Errorf("boundaries %s: invalid polygon of %s: %w", name, area.Name, err)
+			}
+			area.polygons = [][][][2]float64{polygon}
+		case "MultiPolygon":
+			if err := json.Unmarshal(feature.Geometry.Coordinates, &area.polygons); err != nil {
+				return nil, fmt.Errorf("boundaries %s: invalid multipolygon of %s: %w", name, area.Name, err)
+			}
+		default:
+			return nil, fmt.Errorf("boundaries %s: %s has geometry %q, expected Polygon or MultiPolygon",
+				name, area.Name, feature.Geometry.Type)
+		}
+		if err := area.measure(); err != nil {
+			return nil, fmt.Errorf("boundaries %s: %s: %w", name, area.Name, err)
+		}
+
+		switch area.Level {
+		case LevelRegion:
+			b.regions = append(b.regions, area)
+			b.katos[area.Name] = area.KATO
+			b.byRegion[strings.ToLower(area.Name)] = area
+		case LevelCity:
+			if !settlementPlaces[area.Place] {
+				return nil, fmt.Errorf("boundaries %s: city %s is a %q, expected a city, town or village",
+					name, area.Name, area.Place)
+			}
+			b.cities = append(b.cities, area)
+			for _, cityName := range append([]string{area.Name}, area.Aliases...) {
+				b.byName[strings.ToLower(cityName)] = area
+			}
+		default:
+			return nil, fmt.Errorf("boundaries %s: %s has level %q, expected %q or %q",
+				name, area.Name, area.Level, LevelRegion, LevelCity)
+		}
+	}
+
+	if len(b.regions) == 0 {
+		return nil, fmt.Errorf("boundaries %s have no regions", name)
+	}
+	for _, city := range b.cities {
+		if _, ok := b.katos[city.Region]; !ok {
+			return nil, fmt.Errorf("boundaries %s: city %s is in unknown region %q", name, city.Name, city.Region)
+		}
+	}
+	return b, nil
+}
+
+// measure computes the bounding box and size of an area
+func (a *Area) measure() error {
+	a.minLon, a.minLat, a.maxLon, a.maxLat = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, polygon := range a.polygons {
+		for i, ring := range polygon {
+			if len(ring) < 4 {
+				return fmt.Errorf("ring with %d points, at least 4 are required", len(ring))
+			}
+			var twiceArea float64
+			for j := range ring {
+				p, q := ring[j], ring[(j+1)%len(ring)]
+				twiceArea += p[0]*q[1] - q[0]*p[1]
+				a.minLon, a.maxLon = math.Min(a.minLon, p[0]), math.Max(a.maxLon, p[0])
+				a.minLat, a.maxLat = math.Min(a.minLat, p[1]), math.Max(a.maxLat, p[1])
+			}
+			// Inner rings are holes
+			if i == 0 {
+				a.size += math.Abs(twiceArea) / 2
+			} else {
+				a.size -= math.Abs(twiceArea) / 2
+			}
+		}
+	}
+	if len(a.polygons) == 0 {
+		return fmt.Errorf("no polygons")
+	}
+	return nil
+}
+
+// Contains reports whether a point lies in the area
+func (a *Area) Contains(lat, lon float64) bool {
+	if lon < a.minLon || lon > a.maxLon || lat < a.minLat || lat > a.maxLat {
+		return false
+	}
+	for _, polygon := range a.polygons {
+		// Even-odd ray casting over all rings also excludes the holes
+		inside := false
+		for _, ring := range polygon {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				p, q := ring[i], ring[j]
+				if (p[1] > lat) != (q[1] > lat) && lon < (q[0]-p[0])*(lat-p[1])/(q[1]-p[1])+p[0] {
+					inside = !inside
+				}
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the bounding box of the area
+func (a *Area) Bounds() (minLat, minLon, maxLat, maxLon float64) {
+	return a.minLat, a.minLon, a.maxLat, a.maxLon
+}
+
+// Locate returns the region and city of a point. A point in a city takes the region of the
+// city; where areas overlap, such as Almaty inside Almaty Region, the smallest one wins.
+func (b *Boundaries) Locate(lat, lon float64) Location {
+	if city := smallest(b.cities, lat, lon); city != nil {
+		return Location{Region: city.Region, City: city.Name, RegionKATO: b.katos[city.Region]}
+	}
+	if region := smallest(b.regions, lat, lon); region != nil {
+		return Location{Region: region.Name, RegionKATO: region.KATO}
+	}
+	return Location{}
+}
+
+// City returns a city by its name or one of its aliases, case-insensitively
+func (b *Boundaries) City(name string) (*Area, bool) {
+	city, ok := b.byName[strings.ToLower(strings.TrimSpace(name))]
+	return city, ok
+}
+
+// Region returns a region by its name, case-insensitively
+func (b *Boundaries) Region(name string) (*Area, bool) {
+	region, ok := b.byRegion[strings.ToLower(strings.TrimSpace(name))]
+	return region, ok
+}
+
+// RandomPoint returns a random point inside area, inside a random region when area is nil
+func (b *Boundaries) RandomPoint(area *Area) (lat, lon float64) {
+	if area == nil {
+		area = b.regions[rand.Intn(len(b.regions))]
+	}
+	minLat, minLon, maxLat, maxLon := area.Bounds()
+	for attempt := 0; attempt < 100; attempt++ {
+		lat, lon = minLat+rand.Float64()*(maxLat-minLat), minLon+rand.Float64()*(maxLon-minLon)
+		if area.Contains(lat, lon) {
+			return lat, lon
+		}
+	}
+	return (minLat + maxLat) / 2, (minLon + maxLon) / 2
+}
+
+func smallest(areas []*Area, lat, lon float64) *Area {
+	var best *Area
+	for _, area := range areas {
+		if (best == nil || area.size < best.size) && area.Contains(lat, lon) {
+			best = area
+		}
+	}
+	return best
+}
@@ -0,0 +1,197 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+)
+
+// testBoundaries are two regions sharing the border at longitude 10, the east one with a hole,
+// a city of republican significance inside the west region and a city on the border
+const testBoundaries = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"level":"region","name":"West","kato":"100000000"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}},
+{"type":"Feature","properties":{"level":"region","name":"East","kato":"200000000"},"geometry":{"type":"Polygon","coordinates":[[[10,0],[20,0],[20,10],[10,10],[10,0]],[[14,4],[16,4],[16,6],[14,6],[14,4]]]}},
+{"type":"Feature","properties":{"level":"region","name":"Capital","kato":"300000000"},"geometry":{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}},
+{"type":"Feature","properties":{"level":"region","name":"Islands","kato":"400000000"},"geometry":{"type":"MultiPolygon","coordinates":[[[[30,0],[31,0],[31,1],[30,1],[30,0]]],[[[32,0],[33,0],[33,1],[32,1],[32,0]]]]}},
+{"type":"Feature","properties":{"level":"city","name":"Capital","region":"Capital","place":"city","center":[3,3],"aliases":["Old Capital"]},"geometry":{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}},
+{"type":"Feature","properties":{"level":"city","name":"Bordertown","region":"East","place":"town","center":[10.5,5]},"geometry":{"type":"Polygon","coordinates":[[[9.8,4.8],[11,4.8],[11,5.2],[9.8,5.2],[9.8,4.8]]]}}
+]}`
+
+func TestLocateNearBorders(t *testing.T) {
+	b, err := Parse([]byte(testBoundaries), "test")
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lat, lon   float64
+		wantRegion string
+		wantCity   string
+		wantKATO   string
+	}{
+		{name: "just west of the border", lat: 1, lon: 9.999, wantRegion: "West", wantKATO: "100000000"},
+		{name: "just east of the border", lat: 1, lon: 10.001, wantRegion: "East", wantKATO: "200000000"},
+		{name: "just inside the outer border", lat: 0.001, lon: 19.999, wantRegion: "East"},
+		{name: "just outside the outer border", lat: -0.001, lon: 15},
+		{name: "inside the hole", lat: 5, lon: 15},
+		{name: "just outside the hole", lat: 5, lon: 13.999, wantRegion: "East"},
+		{name: "second polygon of a multipolygon", lat: 0.5, lon: 32.5, wantRegion: "Islands"},
+		{name: "between the polygons of a multipolygon", lat: 0.5, lon: 31.5},
+		{name: "smallest region wins", lat: 3, lon: 3.999, wantRegion: "Capital", wantCity: "Capital", wantKATO: "300000000"},
+		{name: "just outside the smallest region", lat: 3, lon: 4.001, wantRegion: "West"},
+		{name: "city across the border takes its own region", lat: 5, lon: 9.9, wantRegion: "East", wantCity: "Bordertown", wantKATO: "200000000"},
+		{name: "just outside the city", lat: 5.201, lon: 9.9, wantRegion: "West"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.Locate(tt.lat, tt.lon)
+			if got.Region != tt.wantRegion || got.City != tt.wantCity {
+				t.Fatalf("Locate(%v, %v) = %+v, want region %q and city %q", tt.lat, tt.lon, got, tt.wantRegion, tt.wantCity)
+			}
+			if tt.wantKATO != "" && got.RegionKATO != tt.wantKATO {
+				t.Fatalf("Locate(%v, %v) KATO = %q, want %q", tt.lat, tt.lon, got.RegionKATO, tt.wantKATO)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidBoundaries(t *testing.T) {
+	region := `{"type":"Feature","properties":{"level":"region","name":"West","kato":"1"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`
+	tests := []struct {
+		name    string
+		feature string
+		wantErr string
+	}{
+		{
+			name:    "lake in the city level",
+			feature: `{"type":"Feature","properties":{"level":"city","name":"Lake","region":"West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`,
+			wantErr: "expected a city, town or village",
+		},
+		{
+			name:    "district in the city level",
+			feature: `{"type":"Feature","properties":{"level":"city","name":"District","region":"West","place":"suburb"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`,
+			wantErr: "expected a city, town or village",
+		},
+		{
+			name:    "city in an unknown region",
+			feature: `{"type":"Feature","properties":{"level":"city","name":"Town","region":"North","place":"town"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`,
+			wantErr: "unknown region",
+		},
+		{
+			name:    "ring with too few points",
+			feature: `{"type":"Feature","properties":{"level":"region","name":"East","kato":"2"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}}`,
+			wantErr: "at least 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `{"type":"FeatureCollection","features":[` + region + "," + tt.feature + `]}`
+			_, err := Parse([]byte(data), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestEmbeddedBoundariesNearBorders locates settlements close to region and state borders in the
+// embedded boundaries
+func TestEmbeddedBoundariesNearBorders(t *testing.T) {
+	b, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lat, lon   float64
+		wantRegion string
+		wantCity   string
+	}{
+		{name: "Алматы, Алатауский район", lat: 43.30, lon: 76.85, wantRegion: "Алматы", wantCity: "Алматы"},
+		{name: "Медеу", lat: 43.157, lon: 77.058, wantRegion: "Алматы", wantCity: "Алматы"},
+		{name: "Шымбулак", lat: 43.128, lon: 77.08, wantRegion: "Алматы"},
+		{name: "Каскелен", lat: 43.20, lon: 76.62, wantRegion: "Алматинская область"},
+		{name: "Талгар", lat: 43.30, lon: 77.24, wantRegion: "Алматинская область"},
+		{name: "Узынагаш", lat: 43.22, lon: 76.31, wantRegion: "Алматинская область"},
+		{name: "Кордай", lat: 43.04, lon: 74.71, wantRegion: "Жамбылская область"},
+		{name: "Отар", lat: 43.53, lon: 75.21, wantRegion: "Жамбылская область"},
+		{name: "Улкен", lat: 45.2, lon: 73.97, wantRegion: "Алматинская область"},
+		{name: "Приозёрск", lat: 46.03, lon: 73.70, wantRegion: "Карагандинская область"},
+		{name: "Косшы", lat: 51.04, lon: 71.55, wantRegion: "Акмолинская область"},
+		{name: "Ленгер", lat: 42.18, lon: 69.88, wantRegion: "Туркестанская область"},
+		{name: "Шымкент, север", lat: 42.38, lon: 69.60, wantRegion: "Шымкент", wantCity: "Шымкент"},
+		{name: "Кентау", lat: 43.52, lon: 68.52, wantRegion: "Туркестанская область", wantCity: "Кентау"},
+		{name: "Жанатас", lat: 43.57, lon: 69.75, wantRegion: "Жамбылская область"},
+		{name: "Каратау", lat: 43.18, lon: 70.46, wantRegion: "Жамбылская область"},
+		{name: "Жетысай", lat: 40.77, lon: 68.33, wantRegion: "Туркестанская область"},
+		{name: "Шиели", lat: 44.17, lon: 66.74, wantRegion: "Кызылординская область"},
+		{name: "Аральск", lat: 46.8, lon: 61.67, wantRegion: "Кызылординская область", wantCity: "Аральск"},
+		{name: "Шалкар", lat: 47.83, lon: 59.6, wantRegion: "Актюбинская область"},
+		{name: "Бейнеу", lat: 45.32, lon: 55.2, wantRegion: "Мангистауская область"},
+		{name: "Форт-Шевченко", lat: 44.51, lon: 50.26, wantRegion: "Мангистауская область"},
+		{name: "Кульсары", lat: 46.95, lon: 54.02, wantRegion: "Атырауская область"},
+		{name: "Жанибек", lat: 49.42, lon: 46.85, wantRegion: "Западно-Казахстанская область"},
+		{name: "Аксай", lat: 51.17, lon: 52.99, wantRegion: "Западно-Казахстанская область"},
+		{name: "Житикара", lat: 52.19, lon: 61.2, wantRegion: "Костанайская область"},
+		{name: "Есиль", lat: 51.96, lon: 66.40, wantRegion: "Акмолинская область"},
+		{name: "Державинск", lat: 51.1, lon: 66.32, wantRegion: "Акмолинская область"},
+		{name: "Аркалык", lat: 50.25, lon: 66.91, wantRegion: "Костанайская область", wantCity: "Аркалык"},
+		{name: "Булаево", lat: 54.9, lon: 70.44, wantRegion: "Северо-Казахстанская область"},
+		{name: "Ерейментау", lat: 51.62, lon: 73.10, wantRegion: "Акмолинская область"},
+		{name: "Баянаул", lat: 50.79, lon: 75.70, wantRegion: "Павлодарская область"},
+		{name: "Экибастуз", lat: 51.72, lon: 75.32, wantRegion: "Павлодарская область", wantCity: "Экибастуз"},
+		{name: "Каркаралинск", lat: 49.41, lon: 75.47, wantRegion: "Карагандинская область", wantCity: "Каркаралинск"},
+		{name: "Балхаш", lat: 46.85, lon: 74.99, wantRegion: "Карагандинская область", wantCity: "Балхаш"},
+		{name: "Аягоз", lat: 47.97, lon: 80.43, wantRegion: "область Абай", wantCity: "Аягоз"},
+		{name: "Урджар", lat: 47.09, lon: 81.63, wantRegion: "область Абай"},
+		{name: "Ушарал", lat: 46.17, lon: 80.94, wantRegion: "область Жетісу"},
+		{name: "Сарканд", lat: 45.41, lon: 79.91, wantRegion: "область Жетісу"},
+		{name: "Курчум", lat: 48.57, lon: 83.65, wantRegion: "Восточно-Казахстанская область"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.Locate(tt.lat, tt.lon)
+			if got.Region != tt.wantRegion || got.City != tt.wantCity {
+				t.Fatalf("Locate(%v, %v) = %+v, want region %q and city %q", tt.lat, tt.lon, got, tt.wantRegion, tt.wantCity)
+			}
+		})
+	}
+}
+
+func TestEmbeddedCitiesAreSettlements(t *testing.T) {
+	b, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	for _, name := range []string{"Алаколь", "Маркакол", "Медеу", "Шымбулак", "Чимбулак"} {
+		if _, ok := b.City(name); ok {
+			t.Errorf("City(%q) found, lakes and city districts are no settlements", name)
+		}
+	}
+	if city, ok := b.City("Нур-Султан"); !ok || city.Name != "Астана" || city.Place != "city" {
+		t.Errorf("City(\"Нур-Султан\") = %+v, %v, want the city Астана", city, ok)
+	}
+	if region, ok := b.Region("алматинская область"); !ok || region.KATO != "190000000" {
+		t.Errorf("Region(\"алматинская область\") = %+v, %v, want KATO 190000000", region, ok)
+	}
+}
+
+func TestRandomPoint(t *testing.T) {
+	b, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	city, _ := b.City("Алматы")
+	for i := 0; i < 20; i++ {
+		if lat, lon := b.RandomPoint(city); !city.Contains(lat, lon) {
+			t.Fatalf("RandomPoint(Алматы) = %v, %v outside the city", lat, lon)
+		}
+		if lat, lon := b.RandomPoint(nil); b.Locate(lat, lon).Region == "" {
+			t.Fatalf("RandomPoint(nil) = %v, %v outside every region", lat, lon)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsAddrEnv sets the address /metrics is served on, see Serve
+const MetricsAddrEnv = "METRICS_ADDR"
+
+// DefaultAddr is the address /metrics is served on when MetricsAddrEnv is not set
+const DefaultAddr = ":9100"
+
+// Metrics have the same names in every parser process and are labelled by source,
+// so one set of alerts covers all parsers
+var (
+	sourceRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "parser_source_requests_total",
+		Help: "Requests sent to a source by endpoint and HTTP status code, code is \"error\" when no response was received.",
+	}, []string{"source", "endpoint", "code"})
+
+	sourceRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "parser_source_request_duration_seconds",
+		Help:    "Latency of requests sent to a source by endpoint, retries are observed separately.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"source", "endpoint"})
+
+	rateLimitWaits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "parser_rate_limit_waits_total",
+		Help: "Requests that had to wait for the rate limiter.",
+	}, []string{"source"})
+
+	rateLimitWaitSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "parser_rate_limit_wait_seconds_total",
+		Help: "Time spent waiting for the rate limiter.",
+	}, []string{"source"})
+
+	upserts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "parser_upserts_total",
+		Help: "Stored records by outcome: insert, update, skip or failed.",
+	}, []string{"source", "outcome"})
+
+	insertQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "parser_insert_queue_depth",
+		Help: "Records handed to the store and not written yet.",
+	}, []string{"source"})
+
+	lastSuccessfulRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "parser_last_successful_run_timestamp_seconds",
+		Help: "Unix time the last parsing run of a source completed successfully.",
+	}, []string{"source"})
+)
+
+// ObserveRequest records a request sent to a source endpoint. code is the HTTP status code,
+// 0 when the request failed without a response.
+func ObserveRequest(source, endpoint string, code int, duration time.Duration) {
+	codeLabel := "error"
+	if code > 0 {
+		codeLabel = strconv.Itoa(code)
+	}
+	sourceRequests.WithLabelValues(source, endpoint, codeLabel).Inc()
+	sourceRequestDuration.WithLabelValues(source, endpoint).Observe(duration.Seconds())
+}
+
+// ObserveRateLimitWait records how long a request waited for the rate limiter, zero waits are ignored
+func ObserveRateLimitWait(source string, wait time.Duration) {
+	if wait <= 0 {
+		return
+	}
+	rateLimitWaits.WithLabelValues(source).Inc()
+	rateLimitWaitSeconds.WithLabelValues(source).Add(wait.Seconds())
+}
+
+// AddUpserts counts stored records with the given outcome
+func AddUpserts(source, outcome string, n int) {
+	if n <= 0 {
+		return
+	}
+	upserts.WithLabelValues(source, outcome).Add(float64(n))
+}
+
+// AddQueued changes the insert queue depth of a source by delta
+func AddQueued(source string, delta int) {
+	insertQueueDepth.WithLabelValues(source).Add(float64(delta))
+}
+
+// SetLastSuccessfulRun records that a parsing run of a source completed now
+func SetLastSuccessfulRun(source string) {
+	lastSuccessfulRun.WithLabelValues(source).SetToCurrentTime()
+}
+
+// Addr returns the address /metrics is served on, from MetricsAddrEnv or DefaultAddr
+func Addr() string {
+	if addr, ok := os.LookupEnv(MetricsAddrEnv); ok {
+		return addr
+	}
+	return DefaultAddr
+}
+
+// Serve exposes /metrics on addr until ctx is cancelled. An empty addr disables the endpoint.
+func Serve(ctx context.Context, addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package normalize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// socialPagePriority is the order in which a social network becomes the social_media_page
+var socialPagePriority = []string{Instagram, VK, Facebook, Telegram, WhatsApp, TikTok, YouTube, Twitter, OK}
+
+var (
+	textPhonePattern = regexp.MustCompile(`(?:\+7|\b8)[\s(-]*\d{3}[\s)-]*\d(?:[\s-]?\d){6}\b`)
+	textEmailPattern = regexp.MustCompile(`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}.-]+\.\p{L}{2,}`)
+)
+
+// Contacts collects the normalized phones, emails, websites and social network profiles of a
+// record. Invalid and repeated values are skipped, the first value of each kind is the main one.
+type Contacts struct {
+	Phones   []Phone
+	Emails   []string
+	Websites []string
+	Socials  map[string]string // social network to profile link, the first profile of a network wins
+}
+
+// AddPhones adds every valid phone number of a phone field
+func (c *Contacts) AddPhones(raw string) {
+	for _, phone := range ParsePhones(raw) {
+		if !containsPhone(c.Phones, phone.Number) {
+			c.Phones = append(c.Phones, phone)
+		}
+	}
+}
+
+// AddEmail adds an email address if it is valid
+func (c *Contacts) AddEmail(raw string) {
+	if email, ok := Email(raw); ok {
+		c.Emails = appendUnique(c.Emails, email)
+	}
+}
+
+// AddWebsite adds a website if it is a valid address. Links to social network profiles are
+// added as profiles of the network instead.
+func (c *Contacts) AddWebsite(raw string) {
+	if network, ok := LinkNetwork(raw); ok {
+		c.AddSocial(network, raw)
+		return
+	}
+	if link, ok := URL(raw); ok {
+		c.Websites = appendUnique(c.Websites, link)
+	}
+}
+
+// AddSocial adds the profile of a social network given as a link or handle. Networks that are
+// not known are skipped.
+func (c *Contacts) AddSocial(network, raw string) {
+	network, ok := Network(network)
+	if !ok {
+		return
+	}
+	if _, exists := c.Socials[network]; exists {
+		return
+	}
+	link, ok := Social(network, raw)
+	if !ok {
+		return
+	}
+	if c.Socials == nil {
+		c.Socials = make(map[string]string)
+	}
+	c.Socials[network] = link
+}
+
+// AddText adds the phone numbers and email addresses found in free text such as a description
+func (c *Contacts) AddText(text string) {
+	for _, match := range textPhonePattern.FindAllString(text, -1) {
+		c.AddPhones(match)
+	}
+	for _, match := range textEmailPattern.FindAllString(text, -1) {
+		c.AddEmail(match)
+	}
+}
+
+// Phone returns the main phone number, empty without one
+func (c *Contacts) Phone() string {
+	if len(c.Phones) == 0 {
+		return ""
+	}
+	return c.Phones[0].Number
+}
+
+// Email returns the first email address that fits maxLength, empty without one
+func (c *Contacts) Email(maxLength int) string {
+	for _, email := range c.Emails {
+		if len(email) <= maxLength {
+			return email
+		}
+	}
+	return ""
+}
+
+// Website returns the main website, empty without one
+func (c *Contacts) Website() string {
+	if len(c.Websites) == 0 {
+		return ""
+	}
+	return c.Websites[0]
+}
+
+// SocialPage returns the profile of the most prominent social network, empty without one
+func (c *Contacts) SocialPage() string {
+	for _, network := range socialPagePriority {
+		if link, ok := c.Socials[network]; ok {
+			return link
+		}
+	}
+	return ""
+}
+
+// Links returns the contacts in the layout of the social_media_links column: the profile link
+// under the name of each social network, and all phones with their kinds, emails and websites.
+// It is nil without any contacts.
+func (c *Contacts) Links() map[string]interface{} {
+	if len(c.Phones) == 0 && len(c.Emails) == 0 && len(c.Websites) == 0 && len(c.Socials) == 0 {
+		return nil
+	}
+
+	links := make(map[string]interface{}, len(c.Socials)+4)
+	for network, link := range c.Socials {
+		links[network] = link
+	}
+	if len(c.Phones) > 0 {
+		phones := make([]string, len(c.Phones))
+		kinds := make(map[string]string, len(c.Phones))
+		for i, phone := range c.Phones {
+			phones[i] = phone.Number
+			kinds[phone.Number] = phone.Kind
+		}
+		links["phones"] = phones
+		links["phone_kinds"] = kinds
+	}
+	if len(c.Emails) > 0 {
+		links["emails"] = c.Emails
+	}
+	if len(c.Websites) > 0 {
+		links["websites"] = c.Websites
+	}
+	return links
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,60 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContacts(t *testing.T) {
+	var c Contacts
+	c.AddPhones("8 (727) 250-00-00, +7 701 123 45 67")
+	c.AddPhones("+7 727 250 00 00")
+	c.AddEmail("Info@Hotel.kz")
+	c.AddEmail("info@hotel.kz")
+	c.AddEmail("not an email")
+	c.AddWebsite("www.hotel.kz/?utm_source=2gis")
+	c.AddWebsite("https://www.instagram.com/rixos_almaty/")
+	c.AddSocial("vkontakte", "@rixos")
+	c.AddSocial("instagram", "@another_profile")
+	c.AddSocial("myspace", "rixos")
+	c.AddText("Бронирование: +7 (701) 765-43-21 или booking@hotel.kz")
+
+	if got := c.Phone(); got != "+77272500000" {
+		t.Fatalf("Phone() = %q, want the first number", got)
+	}
+	if got := c.Email(100); got != "info@hotel.kz" {
+		t.Fatalf("Email(100) = %q, want info@hotel.kz", got)
+	}
+	if got := c.Email(12); got != "" {
+		t.Fatalf("Email(12) = %q, want none that short", got)
+	}
+	if got := c.Website(); got != "https://www.hotel.kz" {
+		t.Fatalf("Website() = %q, want https://www.hotel.kz", got)
+	}
+	if got := c.SocialPage(); got != "https://instagram.com/rixos_almaty" {
+		t.Fatalf("SocialPage() = %q, want the first Instagram profile", got)
+	}
+
+	want := map[string]interface{}{
+		Instagram:     "https://instagram.com/rixos_almaty",
+		VK:            "https://vk.com/rixos",
+		"phones":      []string{"+77272500000", "+77011234567", "+77017654321"},
+		"phone_kinds": map[string]string{"+77272500000": PhoneLandline, "+77011234567": PhoneMobile, "+77017654321": PhoneMobile},
+		"emails":      []string{"info@hotel.kz", "booking@hotel.kz"},
+		"websites":    []string{"https://www.hotel.kz"},
+	}
+	if got := c.Links(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Links() = %v, want %v", got, want)
+	}
+}
+
+func TestEmptyContacts(t *testing.T) {
+	var c Contacts
+	c.AddPhones("n/a")
+	if links := c.Links(); links != nil {
+		t.Fatalf("Links() = %v, want nil without contacts", links)
+	}
+	if c.Phone() != "" || c.Email(100) != "" || c.Website() != "" || c.SocialPage() != "" {
+		t.Fatalf("empty contacts returned a value: %+v", c)
+	}
+}
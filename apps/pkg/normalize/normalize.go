@@ -0,0 +1,345 @@
+package normalize
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of phone numbers
+const (
+	PhoneMobile   = "mobile"   // Kazakhstan mobile number, +7 70x, 747, 75x-78x
+	PhoneLandline = "landline" // Kazakhstan landline with an area code, +7 71x and 72x
+	PhoneForeign  = "foreign"  // any other international number, including Russian +7 numbers
+)
+
+// Phone is a phone number in E.164 format, e.g. +77011234567
+type Phone struct {
+	Number string
+	Kind   string
+}
+
+// kzPhoneKinds classifies Kazakhstan numbers by the first two digits after +7
+var kzPhoneKinds = map[string]string{
+	"70": PhoneMobile, "74": PhoneMobile, "75": PhoneMobile, "76": PhoneMobile, "77": PhoneMobile, "78": PhoneMobile,
+	"71": PhoneLandline, "72": PhoneLandline,
+}
+
+// phoneExtensionMarkers start the extension part of a phone number, which is dropped
+var phoneExtensionMarkers = []string{"доб", "вн.", "ext"}
+
+// ParsePhone normalizes one phone number. Kazakhstan numbers are accepted as +7, 8 or 7 followed
+// by ten digits, or as the ten digits alone, and are classified as mobile or landline. Other
+// numbers are only accepted in international format with a leading +, or after the 8 10 prefix.
+func ParsePhone(raw string) (Phone, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	raw = strings.TrimPrefix(raw, "tel:")
+	for _, marker := range phoneExtensionMarkers {
+		if i := strings.Index(raw, marker); i > 0 {
+			raw = raw[:i]
+		}
+	}
+	international := strings.HasPrefix(strings.TrimSpace(raw), "+")
+
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case unicode.IsLetter(r):
+			// Letters belong to text around the number, not to a phone number
+			return Phone{}, false
+		}
+	}
+	number := digits.String()
+
+	var national string
+	switch {
+	case len(number) == 11 && number[0] == '7':
+		national = number[1:]
+	case len(number) == 11 && number[0] == '8' && !international:
+		national = number[1:]
+	case len(number) == 10 && !international:
+		national = number
+	case strings.HasPrefix(number, "810") && !international && len(number) >= 11 && len(number) <= 18:
+		return foreignPhone(number[3:])
+	case international:
+		return foreignPhone(number)
+	default:
+		return Phone{}, false
+	}
+
+	if kind, ok := kzPhoneKinds[national[:2]]; ok {
+		return Phone{Number: "+7" + national, Kind: kind}, true
+	}
+	// Russia shares the +7 country code, its numbers start with 3, 4, 8 or 9
+	if strings.ContainsRune("3489", rune(national[0])) {
+		return Phone{Number: "+7" + national, Kind: PhoneForeign}, true
+	}
+	return Phone{}, false
+}
+
+// foreignPhone accepts international numbers with a country code other than +7. E.164 numbers
+// are at most 15 digits, country codes never start with 0.
+func foreignPhone(number string) (Phone, bool) {
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' || number[0] == '7' {
+		return Phone{}, false
+	}
+	return Phone{Number: "+" + number, Kind: PhoneForeign}, true
+}
+
+// ParsePhones normalizes a field of one or more phone numbers separated by commas, semicolons,
+// slashes or new lines. Invalid and repeated numbers are skipped.
+func ParsePhones(raw string) []Phone {
+	var phones []Phone
+	for _, part := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '/' || r == '\n'
+	}) {
+		phone, ok := ParsePhone(part)
+		if ok && !containsPhone(phones, phone.Number) {
+			phones = append(phones, phone)
+		}
+	}
+	return phones
+}
+
+func containsPhone(phones []Phone, number string) bool {
+	for _, phone := range phones {
+		if phone.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// Email validates an email address and returns it lowercased. Display names, mailto: links and
+// addresses without a domain such as user@localhost are rejected or stripped.
+func Email(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > 7 && strings.EqualFold(raw[:7], "mailto:") {
+		raw = raw[7:]
+	}
+	if i := strings.IndexByte(raw, '?'); i >= 0 {
+		raw = raw[:i]
+	}
+	if raw == "" || len(raw) > 254 || strings.ContainsAny(raw, " <>\"") {
+		return "", false
+	}
+
+	address, err := mail.ParseAddress(raw)
+	if err != nil || address.Name != "" {
+		return "", false
+	}
+	at := strings.LastIndexByte(address.Address, '@')
+	domain := address.Address[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return strings.ToLower(address.Address), true
+}
+
+// trackingParams are query parameters that only track where a visitor came from
+var trackingParams = []string{"fbclid", "gclid", "yclid", "igsh", "igshid", "_openstat"}
+
+// URL canonicalizes a web address: https is assumed when the scheme is missing, the scheme and
+// host are lowercased, default ports, fragments, tracking parameters and trailing slashes are
+// dropped. Only http and https addresses with a dotted host are accepted.
+func URL(raw string) (string, bool) {
+	u, ok := parseURL(raw)
+	if !ok {
+		return "", false
+	}
+	return formatURL(u.Scheme, u.Host, u.EscapedPath(), u.RawQuery), true
+}
+
+func parseURL(raw string) (*url.URL, bool) {
+	raw = strings.TrimRight(strings.TrimSpace(raw), ".,;")
+	if raw == "" || strings.ContainsAny(raw, " \t\n") {
+		return nil, false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + strings.TrimPrefix(raw, "//")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	if !strings.Contains(host, ".") || strings.HasPrefix(host, ".") || u.User != nil {
+		return nil, false
+	}
+	u.Host = host
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	for _, key := range trackingParams {
+		query.Del(key)
+	}
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u, true
+}
+
+func formatURL(scheme, host, path, query string) string {
+	link := scheme + "://" + host + strings.TrimRight(path, "/")
+	if query != "" {
+		link += "?" + query
+	}
+	return link
+}
+
+// Social networks of the social_media_links keys
+const (
+	Instagram = "instagram"
+	Facebook  = "facebook"
+	VK        = "vk"
+	Telegram  = "telegram"
+	WhatsApp  = "whatsapp"
+	TikTok    = "tiktok"
+	YouTube   = "youtube"
+	Twitter   = "twitter"
+	OK        = "ok"
+)
+
+// networkAliases maps names used by the sources to the social networks
+var networkAliases = map[string]string{
+	"instagram": Instagram, "facebook": Facebook, "fb": Facebook,
+	"vk": VK, "vkontakte": VK, "telegram": Telegram, "tg": Telegram,
+	"whatsapp": WhatsApp, "tiktok": TikTok, "youtube": YouTube,
+	"twitter": Twitter, "x": Twitter, "ok": OK, "odnoklassniki": OK,
+}
+
+// networkHosts maps the hosts of social network links, without www. and m., to the networks
+var networkHosts = map[string]string{
+	"instagram.com": Instagram, "instagr.am": Instagram,
+	"facebook.com": Facebook, "fb.com": Facebook, "fb.me": Facebook,
+	"vk.com": VK, "vk.ru": VK, "vkontakte.ru": VK,
+	"t.me": Telegram, "telegram.me": Telegram,
+	"wa.me": WhatsApp, "api.whatsapp.com": WhatsApp, "whatsapp.com": WhatsApp, "chat.whatsapp.com": WhatsApp,
+	"tiktok.com": TikTok, "youtube.com": YouTube, "youtu.be": YouTube,
+	"twitter.com": Twitter, "x.com": Twitter, "ok.ru": OK,
+}
+
+// networkBases are the canonical link prefixes of the profiles of every network
+var networkBases = map[string]string{
+	Instagram: "https://instagram.com/",
+	Facebook:  "https://facebook.com/",
+	VK:        "https://vk.com/",
+	Telegram:  "https://t.me/",
+	WhatsApp:  "https://wa.me/",
+	TikTok:    "https://tiktok.com/",
+	YouTube:   "https://youtube.com/",
+	Twitter:   "https://x.com/",
+	OK:        "https://ok.ru/",
+}
+
+// singleSegmentNetworks identify a profile by the first path segment of a link, the rest of
+// the path such as /reels or /about is dropped
+var singleSegmentNetworks = map[string]bool{Instagram: true, VK: true, Telegram: true, TikTok: true, Twitter: true}
+
+var handlePattern = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+
+// Network returns the social network of a name used by a source, e.g. vkontakte or fb
+func Network(name string) (string, bool) {
+	network, ok := networkAliases[strings.ToLower(strings.TrimSpace(name))]
+	return network, ok
+}
+
+// LinkNetwork returns the social network a web address belongs to
+func LinkNetwork(raw string) (string, bool) {
+	u, ok := parseURL(raw)
+	if !ok {
+		return "", false
+	}
+	network, ok := networkHosts[networkHost(u.Hostname())]
+	return network, ok
+}
+
+func networkHost(host string) string {
+	host = strings.TrimPrefix(host, "www.")
+	return strings.TrimPrefix(host, "m.")
+}
+
+// Social canonicalizes a social network profile given as a link, a @handle or a bare handle into
+// a link such as https://instagram.com/handle. WhatsApp profiles are phone numbers, given as such
+// or as wa.me links. Links of other sites are returned canonicalized as they are.
+func Social(network, raw string) (string, bool) {
+	network, ok := Network(network)
+	if !ok {
+		return "", false
+	}
+	raw = strings.TrimSpace(raw)
+
+	if network == WhatsApp {
+		if phone, ok := ParsePhone(raw); ok {
+			return networkBases[WhatsApp] + strings.TrimPrefix(phone.Number, "+"), true
+		}
+	}
+
+	// A handle is a single word, optionally with @ in front, links have a slash or a scheme
+	if handle := strings.TrimPrefix(raw, "@"); !strings.ContainsAny(handle, "/:") {
+		if !handlePattern.MatchString(handle) || network == WhatsApp {
+			return "", false
+		}
+		if network == TikTok || network == YouTube {
+			handle = "@" + handle
+		}
+		return networkBases[network] + handle, true
+	}
+
+	u, ok := parseURL(raw)
+	if !ok {
+		return "", false
+	}
+	if networkHosts[networkHost(u.Hostname())] != network {
+		return formatURL(u.Scheme, u.Host, u.EscapedPath(), u.RawQuery), true
+	}
+
+	path := strings.Trim(u.EscapedPath(), "/")
+	switch {
+	case network == WhatsApp:
+		// wa.me/77011234567 and api.whatsapp.com/send?phone=77011234567
+		number := u.Query().Get("phone")
+		if number == "" {
+			number = path
+		}
+		if phone, ok := ParsePhone("+" + strings.TrimPrefix(number, "+")); ok {
+			return networkBases[WhatsApp] + strings.TrimPrefix(phone.Number, "+"), true
+		}
+		if networkHost(u.Hostname()) == "chat.whatsapp.com" && path != "" {
+			return formatURL("https", "chat.whatsapp.com", "/"+path, ""), true
+		}
+		return "", false
+	case singleSegmentNetworks[network]:
+		path, _, _ = strings.Cut(path, "/")
+		if path == "" {
+			return "", false
+		}
+		return networkBases[network] + path, true
+	default:
+		// Facebook pages and YouTube channels span several segments, profile.php?id= a query
+		if path == "" && u.RawQuery == "" {
+			return "", false
+		}
+		link := networkBases[network] + path
+		if u.RawQuery != "" {
+			link += "?" + u.RawQuery
+		}
+		return link, true
+	}
+}
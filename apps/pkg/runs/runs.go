@@ -0,0 +1,180 @@
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Run statuses stored in parsing_runs
+const (
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted" // stopped by shutdown, may be resumed under the same ID
+)
+
+// MaxErrorSamples is how many error messages a run keeps for parsing_runs.error_samples
+const MaxErrorSamples = 20
+
+// Counts are the aggregate statistics of a parsing run
+type Counts struct {
+	Fetched  int // records listed by the source
+	Inserted int
+	Updated  int
+	Skipped  int // unchanged records
+	Failed   int
+	APICalls int // requests sent to the source, retries included
+}
+
+// Run is an open parsing run. Counters may be updated from several goroutines;
+// Save and Finish write them to parsing_runs.
+type Run struct {
+	ID        string
+	Source    string
+	StartedAt time.Time
+
+	db           *sql.DB
+	mu           sync.Mutex
+	counts       Counts
+	errorSamples []string
+}
+
+// NewID returns the ID of a run starting now, its UTC start time. IDs are unique per source.
+func NewID() string {
+	return time.Now().UTC().Format("20060102T150405Z")
+}
+
+// Start opens a run in parsing_runs. Starting an ID that already exists resumes the run:
+// it is marked running again and keeps its start time, counts and error samples.
+func Start(ctx context.Context, db *sql.DB, source, id string) (*Run, error) {
+	run := &Run{ID: id, Source: source, db: db}
+
+	var samples []byte
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO parsing_runs (run_id, source_website, status, started_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (source_website, run_id)
+		DO UPDATE SET status = EXCLUDED.status, finished_at = NULL
+		RETURNING started_at, fetched, inserted, updated, skipped, failed, api_calls, error_samples
+	`, id, source, StatusRunning).Scan(
+		&run.StartedAt,
+		&run.counts.Fetched,
+		&run.counts.Inserted,
+		&run.counts.Updated,
+		&run.counts.Skipped,
+		&run.counts.Failed,
+		&run.counts.APICalls,
+		&samples,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s run %s: %w", source, id, err)
+	}
+	if err := json.Unmarshal(samples, &run.errorSamples); err != nil {
+		return nil, fmt.Errorf("failed to read error samples of %s run %s: %w", source, id, err)
+	}
+
+	return run, nil
+}
+
+// Add adds counts to the run totals. Counting on a nil Run does nothing, so code shared
+// with modes that do not open a run can count unconditionally.
+func (r *Run) Add(counts Counts) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts.Fetched += counts.Fetched
+	r.counts.Inserted += counts.Inserted
+	r.counts.Updated += counts.Updated
+	r.counts.Skipped += counts.Skipped
+	r.counts.Failed += counts.Failed
+	r.counts.APICalls += counts.APICalls
+}
+
+// AddOutcome counts a single record by the operation written to parsing_logs
+// ("insert", "update" or "skip") or "failed"
+func (r *Run) AddOutcome(operation string) {
+	switch operation {
+	case "insert":
+		r.Add(Counts{Inserted: 1})
+	case "update":
+		r.Add(Counts{Updated: 1})
+	case "skip":
+		r.Add(Counts{Skipped: 1})
+	case "failed":
+		r.Add(Counts{Failed: 1})
+	}
+}
+
+// AddError keeps an error message as a sample, the first MaxErrorSamples are stored.
+// It does not count a failed record, use Add or AddOutcome for that.
+func (r *Run) AddError(err error) {
+	if r == nil || err == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.errorSamples) < MaxErrorSamples {
+		r.errorSamples = append(r.errorSamples, err.Error())
+	}
+}
+
+// Counts returns the run totals so far
+func (r *Run) Counts() Counts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts
+}
+
+// Save writes the totals so far without closing the run
+func (r *Run) Save(ctx context.Context) error {
+	return r.write(ctx, StatusRunning, false)
+}
+
+// Finish closes the run with the given status and writes its totals
+func (r *Run) Finish(ctx context.Context, status string) error {
+	return r.write(ctx, status, true)
+}
+
+func (r *Run) write(ctx context.Context, status string, finished bool) error {
+	r.mu.Lock()
+	counts := r.counts
+	samples, err := json.Marshal(r.errorSamples)
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode error samples: %w", err)
+	}
+	if samples == nil || string(samples) == "null" {
+		samples = []byte("[]")
+	}
+
+	// The totals of an interrupted run are still written, so the write ignores cancellation
+	_, err = r.db.ExecContext(context.WithoutCancel(ctx), `
+		UPDATE parsing_runs SET
+			status = $3,
+			finished_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP END,
+			fetched = $5,
+			inserted = $6,
+			updated = $7,
+			skipped = $8,
+			failed = $9,
+			api_calls = $10,
+			error_samples = $11
+		WHERE source_website = $1 AND run_id = $2
+	`, r.Source, r.ID, status, finished,
+		counts.Fetched, counts.Inserted, counts.Updated, counts.Skipped, counts.Failed, counts.APICalls,
+		string(samples))
+	if err != nil {
+		return fmt.Errorf("failed to save %s run %s: %w", r.Source, r.ID, err)
+	}
+	return nil
+}
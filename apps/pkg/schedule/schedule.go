@@ -0,0 +1,274 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleEnv sets the cron expression parser runs are scheduled with
+const ScheduleEnv = "PARSER_SCHEDULE"
+
+// ControlAddrEnv sets the address the control endpoint is served on, see Serve
+const ControlAddrEnv = "CONTROL_ADDR"
+
+// DefaultControlAddr is the address the control endpoint is served on when ControlAddrEnv is not
+// set. The endpoint has no authentication, so by default it only accepts local connections.
+const DefaultControlAddr = "127.0.0.1:9101"
+
+// ErrRunning is returned by Trigger while a run is in progress
+var ErrRunning = errors.New("a run is already in progress")
+
+// Logger is the logging the scheduler needs, satisfied by the parser loggers
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Job is a single parser run, it should return soon after ctx is cancelled
+type Job func(ctx context.Context) error
+
+// Status is the scheduler state reported by the control endpoint
+type Status struct {
+	Schedule   string     `json:"schedule"`
+	Paused     bool       `json:"paused"`
+	Running    bool       `json:"running"`
+	NextRun    *time.Time `json:"next_run,omitempty"` // unset while a run is in progress
+	LastStart  *time.Time `json:"last_start,omitempty"`
+	LastFinish *time.Time `json:"last_finish,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	Runs       int        `json:"runs"`
+}
+
+// Scheduler runs a job on a cron schedule. Runs never overlap: a scheduled time that passes
+// while a run is in progress is skipped and the next one is taken from when the run finished.
+// Every scheduled time is delayed by a random jitter so parsers sharing a schedule do not hit
+// their sources at the same moment.
+type Scheduler struct {
+	schedule cron.Schedule
+	jitter   time.Duration
+	job      Job
+	logger   Logger
+	trigger  chan struct{}
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Spec returns the cron expression from ScheduleEnv, or def when it is not set
+func Spec(def string) string {
+	if spec, ok := os.LookupEnv(ScheduleEnv); ok && spec != "" {
+		return spec
+	}
+	return def
+}
+
+// ControlAddr returns the address the control endpoint is served on, from ControlAddrEnv or DefaultControlAddr
+func ControlAddr() string {
+	if addr, ok := os.LookupEnv(ControlAddrEnv); ok {
+		return addr
+	}
+	return DefaultControlAddr
+}
+
+// New creates a scheduler of job. spec is a standard 5 field cron expression or a descriptor
+// such as "@hourly" or "@every 1h"; jitter is the maximum random delay of scheduled runs.
+func New(spec string, jitter time.Duration, job Job, logger Logger) (*Scheduler, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	if jitter < 0 {
+		return nil, fmt.Errorf("jitter must not be negative, got %v", jitter)
+	}
+
+	return &Scheduler{
+		schedule: schedule,
+		jitter:   jitter,
+		job:      job,
+		logger:   logger,
+		trigger:  make(chan struct{}, 1),
+		status:   Status{Schedule: spec},
+	}, nil
+}
+
+// Run runs the job on schedule until ctx is cancelled and returns ctx.Err(). With runNow the
+// first run starts immediately instead of at the first scheduled time. A failed run is logged
+// and the scheduler waits for the next scheduled time.
+func (s *Scheduler) Run(ctx context.Context, runNow bool) error {
+	if runNow {
+		s.Trigger()
+	}
+
+	for {
+		next := s.nextRun(time.Now())
+		s.mu.Lock()
+		s.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.trigger:
+			timer.Stop()
+		case <-timer.C:
+			if s.Status().Paused {
+				s.logger.Info("Scheduler paused, skipping run due at %s", next.Format(time.RFC3339))
+				continue
+			}
+		}
+
+		s.runJob(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// nextRun returns the next scheduled time after now with jitter applied
+func (s *Scheduler) nextRun(now time.Time) time.Time {
+	next := s.schedule.Next(now)
+	if s.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.jitter))))
+	}
+	return next
+}
+
+// runJob runs the job once and records its outcome in the status
+func (s *Scheduler) runJob(ctx context.Context) {
+	start := time.Now()
+	s.mu.Lock()
+	s.status.Running = true
+	s.status.NextRun = nil
+	s.status.LastStart = &start
+	s.mu.Unlock()
+
+	err := s.job(ctx)
+
+	finish := time.Now()
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.LastFinish = &finish
+	s.status.LastError = ""
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	s.status.Runs++
+	s.mu.Unlock()
+
+	switch {
+	case err == nil:
+		s.logger.Info("Run completed in %v", finish.Sub(start).Round(time.Second))
+	case ctx.Err() == nil:
+		s.logger.Error("Run failed after %v: %v", finish.Sub(start).Round(time.Second), err)
+	}
+}
+
+// Trigger starts a run now, also while the scheduler is paused. It returns ErrRunning while
+// a run is in progress; triggering again before the triggered run started does nothing.
+func (s *Scheduler) Trigger() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.Running {
+		return ErrRunning
+	}
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pause skips scheduled runs until Resume, a run in progress is not stopped
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Paused = true
+}
+
+// Resume runs the job on schedule again after Pause
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Paused = false
+}
+
+// Status returns the current scheduler state
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Handler serves the control endpoint:
+//
+//	GET  /status   current Status
+//	POST /trigger  start a run now, 409 Conflict while a run is in progress
+//	POST /pause    skip scheduled runs until resumed
+//	POST /resume   run on schedule again
+//
+// Every endpoint responds with the Status as JSON.
+func (s *Scheduler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	mux.HandleFunc("POST /trigger", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Trigger(); err != nil {
+			writeJSON(w, http.StatusConflict, s.Status())
+			return
+		}
+		s.logger.Info("Run triggered from %s", r.RemoteAddr)
+		writeJSON(w, http.StatusAccepted, s.Status())
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		s.Pause()
+		s.logger.Info("Scheduler paused from %s", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		s.Resume()
+		s.logger.Info("Scheduler resumed from %s", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	return mux
+}
+
+// Serve exposes the control endpoint on addr until ctx is cancelled. An empty addr disables it.
+// It is kept apart from /metrics so the metrics port can be scraped without exposing control.
+func (s *Scheduler) Serve(ctx context.Context, addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	server := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// every is a cron.Schedule with a period below the one second resolution of cron expressions
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time { return t.Add(time.Duration(e)) }
+
+type discardLogger struct{}
+
+func (discardLogger) Info(msg string, args ...interface{})  {}
+func (discardLogger) Error(msg string, args ...interface{}) {}
+
+// newTestScheduler returns a scheduler of job that is due every period
+func newTestScheduler(t *testing.T, period time.Duration, job Job) *Scheduler {
+	t.Helper()
+	s, err := New("@hourly", 0, job, discardLogger{})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	s.schedule = every(period)
+	return s
+}
+
+// start runs s until the test ends
+func start(t *testing.T, s *Scheduler, runNow bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, runNow)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		spec    string
+		jitter  time.Duration
+		wantErr bool
+	}{
+		{spec: "0 3 * * *"},
+		{spec: "@every 1h", jitter: time.Minute},
+		{spec: "every hour", wantErr: true},
+		{spec: "0 3 * *", wantErr: true},
+		{spec: "@hourly", jitter: -time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.spec, tt.jitter, func(ctx context.Context) error { return nil }, discardLogger{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q, %v) = %v, want error %v", tt.spec, tt.jitter, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNextRunJitter(t *testing.T) {
+	now := time.Date(2025, 6, 2, 10, 15, 0, 0, time.UTC)
+	scheduled := time.Date(2025, 6, 2, 11, 0, 0, 0, time.UTC)
+
+	s, err := New("@hourly", 0, nil, discardLogger{})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if got := s.nextRun(now); !got.Equal(scheduled) {
+		t.Fatalf("nextRun() = %v without jitter, want %v", got, scheduled)
+	}
+
+	const jitter = 10 * time.Minute
+	s, err = New("@hourly", jitter, nil, discardLogger{})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	delayed := false
+	for i := 0; i < 100; i++ {
+		got := s.nextRun(now)
+		if got.Before(scheduled) || !got.Before(scheduled.Add(jitter)) {
+			t.Fatalf("nextRun() = %v, want within %v after %v", got, jitter, scheduled)
+		}
+		delayed = delayed || got.After(scheduled)
+	}
+	if !delayed {
+		t.Fatalf("nextRun() was never delayed in 100 tries with %v jitter", jitter)
+	}
+}
+
+func TestRunSkipsScheduledRunsWhilePaused(t *testing.T) {
+	var runs atomic.Int32
+	s := newTestScheduler(t, 5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	s.Pause()
+	start(t, s, false)
+
+	time.Sleep(50 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Fatalf("%d runs while paused, want 0", n)
+	}
+
+	// A trigger runs the job while paused
+	if err := s.Trigger(); err != nil {
+		t.Fatalf("Trigger() = %v", err)
+	}
+	waitFor(t, "the triggered run", func() bool { return s.Status().Runs == 1 })
+	time.Sleep(50 * time.Millisecond)
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("%d runs after a trigger while paused, want 1", n)
+	}
+
+	s.Resume()
+	waitFor(t, "scheduled runs after resuming", func() bool { return runs.Load() >= 3 })
+}
+
+func TestTriggerWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	s := newTestScheduler(t, time.Hour, func(ctx context.Context) error {
+		<-release
+		return errors.New("source unavailable")
+	})
+	start(t, s, true)
+	waitFor(t, "the first run", func() bool { return s.Status().Running })
+
+	if err := s.Trigger(); !errors.Is(err, ErrRunning) {
+		t.Fatalf("Trigger() = %v while running, want ErrRunning", err)
+	}
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/trigger", nil))
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("POST /trigger = %d while running, want %d", recorder.Code, http.StatusConflict)
+	}
+
+	close(release)
+	waitFor(t, "the next run to be scheduled", func() bool { return s.Status().NextRun != nil })
+	status := s.Status()
+	if status.Runs != 1 || status.LastError != "source unavailable" || status.NextRun == nil {
+		t.Fatalf("Status() = %+v, want 1 run with its error and the next run scheduled", status)
+	}
+	if err := s.Trigger(); err != nil {
+		t.Fatalf("Trigger() = %v after the run finished, want nil", err)
+	}
+}
+
+func TestControlAddr(t *testing.T) {
+	t.Setenv(ControlAddrEnv, "")
+	os.Unsetenv(ControlAddrEnv)
+	// The endpoint has no authentication, unset it is only reachable from the same host
+	if got := ControlAddr(); got != "127.0.0.1:9101" {
+		t.Fatalf("ControlAddr() = %q, want %q", got, "127.0.0.1:9101")
+	}
+
+	t.Setenv(ControlAddrEnv, ":9200")
+	if got := ControlAddr(); got != ":9200" {
+		t.Fatalf("ControlAddr() = %q, want %q", got, ":9200")
+	}
+	// Set but empty disables the endpoint
+	t.Setenv(ControlAddrEnv, "")
+	if got := ControlAddr(); got != "" {
+		t.Fatalf("ControlAddr() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Version is the database schema version the services of this release need, the newest
+// migration in infrastructure/database/migrations. Bump it with every migration, the
+// package tests fail until it matches. Run migrate up to reach it.
+const Version = 6
+
+// Check refuses a database that is not versioned or migrated to an older version than
+// Version. Newer versions are accepted: a migration keeps the schema usable by the
+// services of the previous release.
+func Check(db *sql.DB) error {
+	var versioned bool
+	if err := db.QueryRow(`SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&versioned); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if !versioned {
+		return fmt.Errorf("database schema is not versioned, version %d is required: run migrate up", Version)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT coalesce(max(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < Version {
+		return fmt.Errorf("database schema is at version %d, version %d is required: run migrate up", version, Version)
+	}
+	return nil
+}
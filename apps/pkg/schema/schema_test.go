@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// migrationsDir is where migrate reads the migrations from
+const migrationsDir = "../../../infrastructure/database/migrations"
+
+var migrationFile = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+func TestVersionIsNewestMigration(t *testing.T) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		t.Fatalf("read migrations: %v", err)
+	}
+
+	newest := 0
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			t.Fatalf("migration %s: %v", entry.Name(), err)
+		}
+		newest = max(newest, version)
+	}
+
+	if Version != newest {
+		t.Fatalf("Version = %d, the newest migration is %d: bump Version with every migration", Version, newest)
+	}
+}
@@ -0,0 +1,127 @@
+package taxonomy
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Canonical accommodation types shared by every source
+const (
+	TypeHotel      = "hotel"
+	TypeHostel     = "hostel"
+	TypeResort     = "resort"
+	TypeSanatorium = "sanatorium"
+	TypeCamping    = "camping"
+	TypeVilla      = "villa"
+	TypeGuestHouse = "guest_house"
+	TypeApartment  = "apartment"
+	TypeOther      = "other"
+)
+
+// CanonicalTypes is the vocabulary every mapping has to map into
+var CanonicalTypes = []string{
+	TypeHotel, TypeHostel, TypeResort, TypeSanatorium, TypeCamping,
+	TypeVilla, TypeGuestHouse, TypeApartment, TypeOther,
+}
+
+// MappingsDirEnv overrides the embedded mapping files with <dir>/<source>.json
+const MappingsDirEnv = "ACCOMMODATION_TYPES_DIR"
+
+//go:embed mappings/*.json
+var embeddedMappings embed.FS
+
+// Mapping maps source specific categories to canonical accommodation types
+type Mapping struct {
+	Source   string            `json:"source"`
+	Version  int               `json:"version"` // bump whenever mappings change so rows can be re-mapped
+	Default  string            `json:"default"`
+	Mappings map[string]string `json:"mappings"`
+}
+
+// ForSource loads the mapping of a source from MappingsDirEnv if set, otherwise from the embedded files
+func ForSource(source string) (*Mapping, error) {
+	if dir := os.Getenv(MappingsDirEnv); dir != "" {
+		return Load(filepath.Join(dir, source+".json"))
+	}
+
+	data, err := embeddedMappings.ReadFile("mappings/" + source + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no accommodation type mapping for source %s", source)
+	}
+	return parse(data, source)
+}
+
+// Load reads a mapping file from disk
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accommodation type mapping: %w", err)
+	}
+	return parse(data, path)
+}
+
+// parse decodes and validates a mapping, keys are matched case-insensitively
+func parse(data []byte, name string) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode accommodation type mapping %s: %w", name, err)
+	}
+
+	if mapping.Version < 1 {
+		return nil, fmt.Errorf("accommodation type mapping %s has no version", name)
+	}
+	if mapping.Default == "" {
+		mapping.Default = TypeOther
+	}
+	if !IsCanonical(mapping.Default) {
+		return nil, fmt.Errorf("accommodation type mapping %s: default %q is not a canonical type", name, mapping.Default)
+	}
+
+	normalized := make(map[string]string, len(mapping.Mappings))
+	for category, accommodationType := range mapping.Mappings {
+		if !IsCanonical(accommodationType) {
+			return nil, fmt.Errorf("accommodation type mapping %s: %q maps to unknown type %q", name, category, accommodationType)
+		}
+		normalized[normalizeCategory(category)] = accommodationType
+	}
+	mapping.Mappings = normalized
+
+	return &mapping, nil
+}
+
+// Map returns the canonical type of the first category that has a mapping.
+// Categories that already are canonical types map to themselves, so rows
+// written by earlier versions can be re-mapped from their stored type.
+func (m *Mapping) Map(categories ...string) string {
+	for _, category := range categories {
+		key := normalizeCategory(category)
+		if key == "" {
+			continue
+		}
+		if accommodationType, ok := m.Mappings[key]; ok {
+			return accommodationType
+		}
+		if IsCanonical(key) {
+			return key
+		}
+	}
+	return m.Default
+}
+
+// IsCanonical reports whether the value is part of the canonical vocabulary
+func IsCanonical(value string) bool {
+	for _, accommodationType := range CanonicalTypes {
+		if accommodationType == value {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
@@ -0,0 +1,128 @@
+package taxonomy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testMapping = `{
+  "source": "test",
+  "version": 3,
+  "default": "other",
+  "mappings": {
+    "Гостиницы": "hotel",
+    "  Хостелы ": "hostel",
+    "type-204": "hotel",
+    "camping": "resort"
+  }
+}`
+
+func TestMap(t *testing.T) {
+	mapping, err := parse([]byte(testMapping), "test")
+	if err != nil {
+		t.Fatalf("parse() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		categories []string
+		want       string
+	}{
+		{name: "mapped category", categories: []string{"type-204"}, want: TypeHotel},
+		{name: "case and spaces are ignored", categories: []string{"  гостиницы "}, want: TypeHotel},
+		{name: "keys are normalized too", categories: []string{"хостелы"}, want: TypeHostel},
+		{name: "first mapped category wins", categories: []string{"unknown", "Хостелы", "Гостиницы"}, want: TypeHostel},
+		{name: "empty categories are skipped", categories: []string{"", "  ", "Гостиницы"}, want: TypeHotel},
+		{name: "canonical type maps to itself", categories: []string{"Villa"}, want: TypeVilla},
+		{name: "mapping wins over a canonical name", categories: []string{"camping"}, want: TypeResort},
+		{name: "earlier canonical type wins over a later mapping", categories: []string{"hostel", "Гостиницы"}, want: TypeHostel},
+		{name: "unknown category gets the default", categories: []string{"Бани"}, want: TypeOther},
+		{name: "no categories get the default", want: TypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapping.Map(tt.categories...); got != tt.want {
+				t.Fatalf("Map(%q) = %q, want %q", tt.categories, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidMappings(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid JSON", data: `{"version": 1,`},
+		{name: "missing version", data: `{"mappings": {"a": "hotel"}}`},
+		{name: "unknown type", data: `{"version": 1, "mappings": {"a": "motel"}}`},
+		{name: "unknown default", data: `{"version": 1, "default": "motel"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parse([]byte(tt.data), "test"); err == nil {
+				t.Fatalf("parse(%s) succeeded, want an error", tt.data)
+			}
+		})
+	}
+}
+
+func TestParseDefaultsToOther(t *testing.T) {
+	mapping, err := parse([]byte(`{"version": 1}`), "test")
+	if err != nil {
+		t.Fatalf("parse() = %v", err)
+	}
+	if mapping.Default != TypeOther {
+		t.Fatalf("Default = %q, want %q", mapping.Default, TypeOther)
+	}
+}
+
+func TestForSourceEmbedded(t *testing.T) {
+	tests := []struct {
+		source   string
+		category string
+		want     string
+	}{
+		{source: "2gis", category: "gostinicy", want: TypeHotel},
+		{source: "booking", category: "type-203", want: TypeHostel},
+		{source: "yandex", category: "Базы отдыха", want: TypeResort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			mapping, err := ForSource(tt.source)
+			if err != nil {
+				t.Fatalf("ForSource(%q) = %v", tt.source, err)
+			}
+			if mapping.Source != tt.source {
+				t.Fatalf("ForSource(%q) loaded the mapping of %q", tt.source, mapping.Source)
+			}
+			if got := mapping.Map(tt.category); got != tt.want {
+				t.Fatalf("Map(%q) = %q, want %q", tt.category, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ForSource("olx"); err == nil {
+		t.Fatalf("ForSource(\"olx\") succeeded without a mapping file")
+	}
+}
+
+func TestForSourceFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "yandex.json"), []byte(testMapping), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(MappingsDirEnv, dir)
+
+	mapping, err := ForSource("yandex")
+	if err != nil {
+		t.Fatalf("ForSource() = %v", err)
+	}
+	if mapping.Version != 3 {
+		t.Fatalf("ForSource() loaded version %d, want 3 from %s", mapping.Version, dir)
+	}
+}
@@ -0,0 +1,11 @@
+module place_matcher
+
+go 1.24.3
+
+require (
+	github.com/lib/pq v1.10.9
+	mytravel/pkg v0.0.0
+)
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	_ "github.com/lib/pq"
+	"mytravel/pkg/schema"
+)
+
+const usage = `Usage: place_matcher [flags] [command] [args]
+
+Links accommodation records of different sources that describe the same property to one place
+and merges them into its golden record.
+
+Commands:
+  run                       match records into places, every -interval unless -once (default)
+  merge <id> <id> [reason]  always put two accommodation records in the same place
+  split <id> <id> [reason]  never put two accommodation records in the same place
+  forget <id> <id>          remove the merge or split of two accommodation records
+
+merge, split and forget rematch the records once afterwards.
+`
+
+func main() {
+	once := flag.Bool("once", false, "Match once and exit instead of every -interval")
+	interval := flag.Duration("interval", getDurationEnv("MATCH_INTERVAL", time.Hour), "Time between matching runs")
+	maxDistance := flag.Float64("max-distance", getFloatEnv("MATCH_MAX_DISTANCE", 250), "Meters two records may be apart to match without a shared phone")
+	minConfidence := flag.Float64("min-confidence", getFloatEnv("MATCH_MIN_CONFIDENCE", 0.7), "Lowest confidence two records are matched with, 0 to 1")
+	policyPath := flag.String("policy", os.Getenv("MERGE_POLICY"), "JSON file with the merge policy of the golden records (default: merge_policy.json built in)")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts := Options{MaxDistance: *maxDistance, MinConfidence: *minConfidence}
+	if opts.MaxDistance <= 0 || opts.MinConfidence <= 0 || opts.MinConfidence > 1 {
+		log.Fatalf("Invalid options: max distance must be positive and min confidence between 0 and 1")
+	}
+	policy, err := loadPolicy(*policyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnv("DB_HOST", "localhost"), getEnv("DB_PORT", "5434"), getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"), getEnv("DB_NAME", "mytravel_db"), getEnv("DB_SSLMODE", "disable"))
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+	if err := schema.Check(db); err != nil {
+		log.Fatalf("Incompatible database schema: %v", err)
+	}
+
+	switch command := flag.Arg(0); command {
+	case "", "run":
+		if *once {
+			if err := matchPlaces(ctx, db, opts, policy); err != nil {
+				log.Fatalf("Matching failed: %v", err)
+			}
+			return
+		}
+		runEvery(ctx, db, opts, policy, *interval)
+	case "merge", "split", "forget":
+		a, errA := strconv.Atoi(flag.Arg(1))
+		b, errB := strconv.Atoi(flag.Arg(2))
+		if errA != nil || errB != nil || a == b {
+			log.Fatalf("%s needs two different accommodation IDs", command)
+		}
+		if command == "forget" {
+			found, err := removeOverride(ctx, db, a, b)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if !found {
+				log.Printf("No override of %d and %d", a, b)
+				return
+			}
+		} else if err := setOverride(ctx, db, a, b, command, flag.Arg(3)); err != nil {
+			log.Fatal(err)
+		}
+		if err := matchPlaces(ctx, db, opts, policy); err != nil {
+			log.Fatalf("Matching failed: %v", err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// runEvery matches places now and then every interval until ctx is cancelled. A failed run is
+// logged and retried at the next interval.
+func runEvery(ctx context.Context, db *sql.DB, opts Options, policy MergePolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := matchPlaces(ctx, db, opts, policy); err != nil && ctx.Err() == nil {
+			log.Printf("Matching failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("Shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// matchPlaces matches all records and stores the places with their golden records
+func matchPlaces(ctx context.Context, db *sql.DB, opts Options, policy MergePolicy) error {
+	start := time.Now()
+
+	records, err := loadRecords(ctx, db)
+	if err != nil {
+		return err
+	}
+	overrides, err := loadOverrides(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	clusters, stats := match(records, overrides, opts)
+	goldens := mergeClusters(clusters, records, policy, time.Now())
+	if err := savePlaces(ctx, db, clusters, goldens, records); err != nil {
+		return err
+	}
+
+	log.Printf("Matched %d records into %d places in %v: %d candidate pairs, %d merged, %d kept apart by source or split",
+		stats.Records, stats.Places, time.Since(start).Round(time.Millisecond), stats.Candidates, stats.Matched, stats.Rejected)
+	return nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+)
+
+// Match methods stored in place_sources.match_method
+const (
+	methodSingle = "single" // the only record of its place
+	methodAuto   = "auto"   // matched by score
+	methodManual = "manual" // merged by a place_match_overrides entry
+)
+
+// Override actions stored in place_match_overrides.action
+const (
+	overrideMerge = "merge"
+	overrideSplit = "split"
+)
+
+// Signal weights of the match confidence. Signals missing on either record are left out and the
+// remaining weights are rescaled, so a Booking.com listing without a phone is not penalized.
+const (
+	nameWeight    = 0.5
+	geoWeight     = 0.3
+	phoneWeight   = 0.2
+	addressWeight = 0.15
+)
+
+// minNameSimilarity is the lowest name similarity a pair is matched with unless the phones match:
+// neighbouring hotels are close by but differently named
+const minNameSimilarity = 0.35
+
+// Record is an accommodation record as far as matching and merging need it
+type Record struct {
+	ID          int
+	Source      string
+	Name        string
+	Latitude    float64
+	Longitude   float64
+	HasLocation bool
+	Address     string
+	Phone       string
+	ReviewCount int
+	LastUpdated time.Time
+
+	Email             string
+	WebsiteURL        string
+	SocialMediaLinks  []byte
+	AccommodationType string
+	PriceRangeMin     sql.NullFloat64
+	PriceRangeMax     sql.NullFloat64
+	PriceCurrency     sql.NullString
+	Photos            []byte
+	Rating            sql.NullFloat64
+	Amenities         []byte
+
+	normalizedName string
+	addressTokens  map[string]bool
+	phones         []string
+}
+
+// Override is a manual merge or split of two records
+type Override struct {
+	AccommodationID      int
+	OtherAccommodationID int
+	Action               string
+}
+
+// Options tune the matching
+type Options struct {
+	MaxDistance   float64 // meters, records further apart match by phone only
+	MinConfidence float64 // lowest confidence two records are matched with
+}
+
+// Member is a record of a cluster with the confidence it was matched with
+type Member struct {
+	AccommodationID int
+	Confidence      float64
+	Method          string
+}
+
+// Cluster is the set of records of one place, Primary is the record the place is shown as
+type Cluster struct {
+	Primary int
+	Members []Member
+}
+
+// pair is a candidate match of two records
+type pair struct {
+	a, b       int // indexes into the records
+	confidence float64
+}
+
+// Stats summarize a matching
+type Stats struct {
+	Records    int
+	Candidates int
+	Matched    int // candidates merged into a place
+	Rejected   int // candidates above the threshold kept apart by a split or a shared source
+	Places     int
+}
+
+// match clusters records of different sources describing the same property. Merge overrides are
+// applied first, then candidate pairs are merged in order of confidence. Two clusters are never
+// merged when that would put two records of the same source, or a split pair, into one place:
+// a source lists a property once, so two records of a source are two properties.
+func match(records []Record, overrides []Override, opts Options) ([]Cluster, Stats) {
+	index := make(map[int]int, len(records))
+	for i := range records {
+		records[i].normalizedName = normalizeName(records[i].Name)
+		records[i].addressTokens = addressTokens(records[i].Address)
+		records[i].phones = phoneKeys(records[i].Phone)
+		index[records[i].ID] = i
+	}
+
+	uf := newUnionFind(records)
+	confidence := make([]float64, len(records))
+	method := make([]string, len(records))
+
+	splits := make(map[int][]int)
+	for _, override := range overrides {
+		a, okA := index[override.AccommodationID]
+		b, okB := index[override.OtherAccommodationID]
+		if !okA || !okB {
+			continue
+		}
+		switch override.Action {
+		case overrideMerge:
+			uf.union(a, b)
+			for _, i := range []int{a, b} {
+				confidence[i], method[i] = 1, methodManual
+			}
+		case overrideSplit:
+			splits[a] = append(splits[a], b)
+			splits[b] = append(splits[b], a)
+		}
+	}
+
+	candidates := candidatePairs(records, opts)
+	stats := Stats{Records: len(records), Candidates: len(candidates)}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].confidence != candidates[j].confidence {
+			return candidates[i].confidence > candidates[j].confidence
+		}
+		if candidates[i].a != candidates[j].a {
+			return candidates[i].a < candidates[j].a
+		}
+		return candidates[i].b < candidates[j].b
+	})
+
+	for _, candidate := range candidates {
+		if candidate.confidence < opts.MinConfidence {
+			break
+		}
+		rootA, rootB := uf.find(candidate.a), uf.find(candidate.b)
+		if rootA == rootB {
+			continue
+		}
+		if uf.sharesSource(rootA, rootB) || uf.splitBetween(rootA, rootB, splits) {
+			stats.Rejected++
+			continue
+		}
+		uf.union(rootA, rootB)
+		stats.Matched++
+		for _, i := range []int{candidate.a, candidate.b} {
+			if method[i] != methodManual && candidate.confidence > confidence[i] {
+				confidence[i], method[i] = candidate.confidence, methodAuto
+			}
+		}
+	}
+
+	byRoot := make(map[int]*Cluster)
+	var roots []int
+	for i, record := range records {
+		root := uf.find(i)
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &Cluster{Primary: record.ID}
+			byRoot[root] = cluster
+			roots = append(roots, root)
+		}
+		if primary := records[index[cluster.Primary]]; record.ReviewCount > primary.ReviewCount {
+			cluster.Primary = record.ID
+		}
+		cluster.Members = append(cluster.Members, Member{AccommodationID: record.ID, Confidence: confidence[i], Method: method[i]})
+	}
+
+	clusters := make([]Cluster, 0, len(roots))
+	for _, root := range roots {
+		cluster := byRoot[root]
+		if len(cluster.Members) == 1 {
+			cluster.Members[0].Confidence, cluster.Members[0].Method = 1, methodSingle
+		}
+		clusters = append(clusters, *cluster)
+	}
+	stats.Places = len(clusters)
+	return clusters, stats
+}
+
+// candidatePairs scores the pairs of records of different sources that are near each other or
+// share a phone number. Nearby records are found through a grid of MaxDistance sized cells.
+func candidatePairs(records []Record, opts Options) []pair {
+	type cell struct{ lat, lon int }
+	// A degree of longitude is at least half a degree of latitude up to 60° north, which covers Kazakhstan
+	latStep := opts.MaxDistance / (earthRadius * math.Pi / 180)
+	lonStep := latStep * 2
+	cellOf := func(r Record) cell {
+		return cell{int(math.Floor(r.Latitude / latStep)), int(math.Floor(r.Longitude / lonStep))}
+	}
+
+	grid := make(map[cell][]int)
+	byPhone := make(map[string][]int)
+	for i, record := range records {
+		if record.HasLocation {
+			grid[cellOf(record)] = append(grid[cellOf(record)], i)
+		}
+		for _, phone := range record.phones {
+			byPhone[phone] = append(byPhone[phone], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	var pairs []pair
+	consider := func(a, b int) {
+		if a >= b || records[a].Source == records[b].Source || seen[[2]int{a, b}] {
+			return
+		}
+		seen[[2]int{a, b}] = true
+		if confidence, ok := score(&records[a], &records[b], opts); ok {
+			pairs = append(pairs, pair{a: a, b: b, confidence: confidence})
+		}
+	}
+
+	for i, record := range records {
+		if record.HasLocation {
+			c := cellOf(record)
+			for dLat := -1; dLat <= 1; dLat++ {
+				for dLon := -1; dLon <= 1; dLon++ {
+					for _, j := range grid[cell{c.lat + dLat, c.lon + dLon}] {
+						consider(i, j)
+					}
+				}
+			}
+		}
+		for _, phone := range record.phones {
+			for _, j := range byPhone[phone] {
+				consider(i, j)
+			}
+		}
+	}
+	return pairs
+}
+
+// score returns the match confidence of two records, false when they cannot be the same property
+func score(a, b *Record, opts Options) (float64, bool) {
+	nameSimilarity := trigramSimilarity(a.normalizedName, b.normalizedName)
+	phoneMatch := sharesPhone(a.phones, b.phones)
+
+	var weighted, weights float64
+	weighted += nameWeight * nameSimilarity
+	weights += nameWeight
+
+	near := false
+	if a.HasLocation && b.HasLocation {
+		meters := distance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		near = meters <= opts.MaxDistance
+		weighted += geoWeight * math.Max(0, 1-meters/opts.MaxDistance)
+		weights += geoWeight
+	}
+	if len(a.phones) > 0 && len(b.phones) > 0 {
+		if phoneMatch {
+			weighted += phoneWeight
+		}
+		weights += phoneWeight
+	}
+	if len(a.addressTokens) > 0 && len(b.addressTokens) > 0 {
+		weighted += addressWeight * jaccard(a.addressTokens, b.addressTokens)
+		weights += addressWeight
+	}
+
+	if !phoneMatch && (!near || nameSimilarity < minNameSimilarity) {
+		return 0, false
+	}
+	return weighted / weights, true
+}
+
+func sharesPhone(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unionFind tracks the clusters being built with the members and sources of each root
+type unionFind struct {
+	parent  []int
+	members map[int][]int
+	sources map[int]map[string]bool
+}
+
+func newUnionFind(records []Record) *unionFind {
+	uf := &unionFind{
+		parent:  make([]int, len(records)),
+		members: make(map[int][]int, len(records)),
+		sources: make(map[int]map[string]bool, len(records)),
+	}
+	for i, record := range records {
+		uf.parent[i] = i
+		uf.members[i] = []int{i}
+		uf.sources[i] = map[string]bool{record.Source: true}
+	}
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+func (uf *unionFind) union(a, b int) {
+	rootA, rootB := uf.find(a), uf.find(b)
+	if rootA == rootB {
+		return
+	}
+	if len(uf.members[rootA]) < len(uf.members[rootB]) {
+		rootA, rootB = rootB, rootA
+	}
+	uf.parent[rootB] = rootA
+	uf.members[rootA] = append(uf.members[rootA], uf.members[rootB]...)
+	for source := range uf.sources[rootB] {
+		uf.sources[rootA][source] = true
+	}
+	delete(uf.members, rootB)
+	delete(uf.sources, rootB)
+}
+
+// sharesSource reports whether two clusters have records of the same source
+func (uf *unionFind) sharesSource(rootA, rootB int) bool {
+	for source := range uf.sources[rootB] {
+		if uf.sources[rootA][source] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBetween reports whether a split override separates a record of one cluster from a record of the other
+func (uf *unionFind) splitBetween(rootA, rootB int, splits map[int][]int) bool {
+	if len(uf.members[rootA]) > len(uf.members[rootB]) {
+		rootA, rootB = rootB, rootA
+	}
+	for _, i := range uf.members[rootA] {
+		for _, j := range splits[i] {
+			if uf.find(j) == rootB {
+				return true
+			}
+		}
+	}
+	return false
+}
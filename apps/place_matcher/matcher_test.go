@@ -0,0 +1,192 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var testOptions = Options{MaxDistance: 250, MinConfidence: 0.7}
+
+// record returns a record with a location, lat and lon are offsets from central Almaty in 1/10000 degrees,
+// about 11 meters of latitude and 8 meters of longitude
+func record(id int, source, name string, lat, lon int) Record {
+	return Record{
+		ID: id, Source: source, Name: name, HasLocation: true,
+		Latitude: 43.2380 + float64(lat)/10000, Longitude: 76.9450 + float64(lon)/10000,
+	}
+}
+
+// places returns the member IDs of every cluster, sorted for comparison
+func places(clusters []Cluster) [][]int {
+	var ids [][]int
+	for _, cluster := range clusters {
+		var members []int
+		for _, member := range cluster.Members {
+			members = append(members, member.AccommodationID)
+		}
+		sort.Ints(members)
+		ids = append(ids, members)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i][0] < ids[j][0] })
+	return ids
+}
+
+func member(clusters []Cluster, id int) (Cluster, Member) {
+	for _, cluster := range clusters {
+		for _, m := range cluster.Members {
+			if m.AccommodationID == id {
+				return cluster, m
+			}
+		}
+	}
+	return Cluster{}, Member{}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		records      []Record
+		overrides    []Override
+		want         [][]int
+		wantMatched  int
+		wantRejected int
+	}{
+		{
+			name: "same property of two sources",
+			records: []Record{
+				record(1, "2gis", "Гостиница Риксос", 0, 0),
+				record(2, "booking", "Rixos Hotel", 3, 2),
+			},
+			want:        [][]int{{1, 2}},
+			wantMatched: 1,
+		},
+		{
+			name: "neighbours with different names",
+			records: []Record{
+				record(1, "2gis", "Отель Алатау", 0, 0),
+				record(2, "booking", "Hostel Medeu", 3, 2),
+			},
+			want: [][]int{{1}, {2}},
+		},
+		{
+			name: "same name too far apart",
+			records: []Record{
+				record(1, "2gis", "Отель Алатау", 0, 0),
+				record(2, "booking", "Alatau Hotel", 500, 0),
+			},
+			want: [][]int{{1}, {2}},
+		},
+		{
+			name: "shared phone matches records far apart",
+			records: []Record{
+				{ID: 1, Source: "2gis", Name: "Отель Алатау", Phone: "+7 727 123 45 67", HasLocation: true, Latitude: 43.238, Longitude: 76.945},
+				{ID: 2, Source: "booking", Name: "Alatau Hotel", Phone: "8 (727) 123-45-67"},
+			},
+			want:        [][]int{{1, 2}},
+			wantMatched: 1,
+		},
+		{
+			name: "two records of one source stay apart",
+			records: []Record{
+				record(1, "2gis", "Гостиница Риксос", 0, 0),
+				record(2, "2gis", "Rixos Hotel", 1, 1),
+			},
+			want: [][]int{{1}, {2}},
+		},
+		{
+			name: "a place takes one record per source",
+			records: []Record{
+				record(1, "2gis", "Гостиница Риксос", 0, 0),
+				record(2, "booking", "Rixos Hotel", 1, 1),
+				record(3, "2gis", "Rixos", 6, 6),
+			},
+			want:         [][]int{{1, 2}, {3}},
+			wantMatched:  1,
+			wantRejected: 1,
+		},
+		{
+			name: "split override keeps a matching pair apart",
+			records: []Record{
+				record(1, "2gis", "Гостиница Риксос", 0, 0),
+				record(2, "booking", "Rixos Hotel", 3, 2),
+			},
+			overrides:    []Override{{AccommodationID: 2, OtherAccommodationID: 1, Action: overrideSplit}},
+			want:         [][]int{{1}, {2}},
+			wantRejected: 1,
+		},
+		{
+			name: "split override keeps the clusters of the pair apart",
+			records: []Record{
+				record(1, "2gis", "Гостиница Риксос", 0, 0),
+				record(2, "booking", "Rixos Hotel", 1, 1),
+				record(3, "yandex", "Rixos", 6, 6),
+			},
+			overrides:    []Override{{AccommodationID: 1, OtherAccommodationID: 3, Action: overrideSplit}},
+			want:         [][]int{{1, 2}, {3}},
+			wantMatched:  1,
+			wantRejected: 2,
+		},
+		{
+			name: "merge override joins records that do not match",
+			records: []Record{
+				record(1, "2gis", "Отель Алатау", 0, 0),
+				record(2, "booking", "Medeu Guest House", 500, 500),
+			},
+			overrides: []Override{{AccommodationID: 1, OtherAccommodationID: 2, Action: overrideMerge}},
+			want:      [][]int{{1, 2}},
+		},
+		{
+			name: "overrides of unknown records are ignored",
+			records: []Record{
+				record(1, "2gis", "Отель Алатау", 0, 0),
+			},
+			overrides: []Override{{AccommodationID: 1, OtherAccommodationID: 99, Action: overrideMerge}},
+			want:      [][]int{{1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, stats := match(tt.records, tt.overrides, testOptions)
+
+			if got := places(clusters); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("places = %v, want %v", got, tt.want)
+			}
+			if stats.Matched != tt.wantMatched || stats.Rejected != tt.wantRejected {
+				t.Fatalf("matched %d and rejected %d, want %d and %d", stats.Matched, stats.Rejected, tt.wantMatched, tt.wantRejected)
+			}
+			if stats.Records != len(tt.records) || stats.Places != len(tt.want) {
+				t.Fatalf("stats = %+v, want %d records in %d places", stats, len(tt.records), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestMatchMembers(t *testing.T) {
+	records := []Record{
+		record(1, "2gis", "Гостиница Риксос", 0, 0),
+		record(2, "booking", "Rixos Hotel", 3, 2),
+		record(3, "yandex", "Отель Алатау", 0, 0),
+		record(4, "olx", "Medeu Guest House", 500, 500),
+		record(5, "2gis", "Hostel Medeu", 900, 900),
+	}
+	records[1].ReviewCount = 120
+	overrides := []Override{{AccommodationID: 4, OtherAccommodationID: 3, Action: overrideMerge}}
+
+	clusters, _ := match(records, overrides, testOptions)
+
+	cluster, m := member(clusters, 1)
+	if cluster.Primary != 2 {
+		t.Fatalf("primary = %d, want 2 with the most reviews", cluster.Primary)
+	}
+	if m.Method != methodAuto || m.Confidence < testOptions.MinConfidence || m.Confidence > 1 {
+		t.Fatalf("member 1 = %+v, want matched automatically with a confidence from %v to 1", m, testOptions.MinConfidence)
+	}
+	if _, m := member(clusters, 4); m.Method != methodManual || m.Confidence != 1 {
+		t.Fatalf("member 4 = %+v, want merged manually with confidence 1", m)
+	}
+	if _, m := member(clusters, 5); m.Method != methodSingle || m.Confidence != 1 {
+		t.Fatalf("member 5 = %+v, want the single record of its place", m)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// defaultPolicy is the merge policy used when no policy file is given
+//
+//go:embed merge_policy.json
+var defaultPolicy []byte
+
+// Merge preferences of a field
+const (
+	preferPriority = "priority" // the highest priority source, the most recent record among equal sources
+	preferRecent   = "recent"   // the most recently updated record, priority breaks ties
+)
+
+// FieldPolicy says which record a field of the golden record is taken from. Records updated
+// longer than MaxAge ago are only used when no record of the place is fresher.
+type FieldPolicy struct {
+	Priority []string `json:"priority"` // sources, best first; unlisted sources come last
+	MaxAge   Duration `json:"max_age"`  // zero keeps every value fresh
+	Prefer   string   `json:"prefer"`   // preferPriority (default) or preferRecent
+}
+
+// MergePolicy is the FieldPolicy of every merged field; settings missing on a field are taken from Default
+type MergePolicy struct {
+	Default FieldPolicy            `json:"default"`
+	Fields  map[string]FieldPolicy `json:"fields"`
+}
+
+// Duration is a time.Duration written as a string such as "720h" in JSON
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"720h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// mergeField is a field of the golden record, present reports whether a record has a value for it
+type mergeField struct {
+	name    string
+	present func(r *Record) bool
+}
+
+// mergeFields are the merged fields; each is taken from a single record so values that belong
+// together, such as latitude and longitude or a price range and its currency, are never mixed
+var mergeFields = []mergeField{
+	{"name", func(r *Record) bool { return r.Name != "" }},
+	{"address", func(r *Record) bool { return r.Address != "" }},
+	{"coordinates", func(r *Record) bool { return r.HasLocation }},
+	{"phone", func(r *Record) bool { return r.Phone != "" }},
+	{"email", func(r *Record) bool { return r.Email != "" }},
+	{"website", func(r *Record) bool { return r.WebsiteURL != "" }},
+	{"social_media_links", func(r *Record) bool { return hasJSON(r.SocialMediaLinks) }},
+	{"accommodation_type", func(r *Record) bool { return r.AccommodationType != "" }},
+	{"price", func(r *Record) bool { return r.PriceRangeMin.Valid || r.PriceRangeMax.Valid }},
+	{"photos", func(r *Record) bool { return hasJSON(r.Photos) }},
+	{"rating", func(r *Record) bool { return r.Rating.Valid }},
+	{"amenities", func(r *Record) bool { return hasJSON(r.Amenities) }},
+}
+
+// loadPolicy reads the merge policy from path, the embedded default when path is empty
+func loadPolicy(path string) (MergePolicy, error) {
+	data := defaultPolicy
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return MergePolicy{}, fmt.Errorf("failed to read merge policy: %w", err)
+		}
+	}
+
+	var policy MergePolicy
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return MergePolicy{}, fmt.Errorf("invalid merge policy: %w", err)
+	}
+
+	known := make(map[string]bool, len(mergeFields))
+	for _, field := range mergeFields {
+		known[field.name] = true
+	}
+	for name, field := range policy.Fields {
+		if !known[name] {
+			return MergePolicy{}, fmt.Errorf("invalid merge policy: unknown field %q", name)
+		}
+		if field.Prefer != "" && field.Prefer != preferPriority && field.Prefer != preferRecent {
+			return MergePolicy{}, fmt.Errorf("invalid merge policy: field %q prefers %q, expected %q or %q",
+				name, field.Prefer, preferPriority, preferRecent)
+		}
+	}
+	return policy, nil
+}
+
+// field returns the policy of a field with the defaults filled in
+func (p MergePolicy) field(name string) FieldPolicy {
+	policy := p.Fields[name]
+	if policy.Priority == nil {
+		policy.Priority = p.Default.Priority
+	}
+	if policy.MaxAge == 0 {
+		policy.MaxAge = p.Default.MaxAge
+	}
+	if policy.Prefer == "" {
+		policy.Prefer = p.Default.Prefer
+	}
+	return policy
+}
+
+// Provenance is the record a golden field was taken from
+type Provenance struct {
+	Source          string    `json:"source"`
+	AccommodationID int       `json:"accommodation_id"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GoldenRecord is the record chosen for every field of a place, fields no record has are missing
+type GoldenRecord map[string]*Record
+
+// Provenance returns the source of every field of the golden record
+func (g GoldenRecord) Provenance() map[string]Provenance {
+	provenance := make(map[string]Provenance, len(g))
+	for field, record := range g {
+		provenance[field] = Provenance{Source: record.Source, AccommodationID: record.ID, UpdatedAt: record.LastUpdated}
+	}
+	return provenance
+}
+
+// mergeClusters returns the golden record of every cluster
+func mergeClusters(clusters []Cluster, records []Record, policy MergePolicy, now time.Time) []GoldenRecord {
+	byID := make(map[int]*Record, len(records))
+	for i := range records {
+		byID[records[i].ID] = &records[i]
+	}
+
+	goldens := make([]GoldenRecord, len(clusters))
+	for i, cluster := range clusters {
+		members := make([]*Record, len(cluster.Members))
+		for j, member := range cluster.Members {
+			members[j] = byID[member.AccommodationID]
+		}
+		goldens[i] = merge(members, policy, now)
+	}
+	return goldens
+}
+
+// merge picks the record of every field among the records of a place
+func merge(members []*Record, policy MergePolicy, now time.Time) GoldenRecord {
+	golden := make(GoldenRecord, len(mergeFields))
+	for _, field := range mergeFields {
+		var candidates []*Record
+		for _, record := range members {
+			if field.present(record) {
+				candidates = append(candidates, record)
+			}
+		}
+		if len(candidates) > 0 {
+			golden[field.name] = pick(candidates, policy.field(field.name), now)
+		}
+	}
+	return golden
+}
+
+// pick returns the best candidate under policy: fresh records before stale ones, then by
+// source priority or recency as the policy prefers, then the lowest ID
+func pick(candidates []*Record, policy FieldPolicy, now time.Time) *Record {
+	rank := func(r *Record) int {
+		for i, source := range policy.Priority {
+			if source == r.Source {
+				return i
+			}
+		}
+		return len(policy.Priority)
+	}
+	fresh := func(r *Record) bool {
+		return policy.MaxAge == 0 || now.Sub(r.LastUpdated) <= time.Duration(policy.MaxAge)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if fresh(a) != fresh(b) {
+			return fresh(a)
+		}
+		byRank, byTime := rank(a)-rank(b), a.LastUpdated.Compare(b.LastUpdated)
+		if policy.Prefer == preferRecent {
+			if byTime != 0 {
+				return byTime > 0
+			}
+			if byRank != 0 {
+				return byRank < 0
+			}
+		} else {
+			if byRank != 0 {
+				return byRank < 0
+			}
+			if byTime != 0 {
+				return byTime > 0
+			}
+		}
+		return a.ID < b.ID
+	})
+	return candidates[0]
+}
+
+// hasJSON reports whether a JSON value is set and not empty
+func hasJSON(value []byte) bool {
+	trimmed := string(bytes.TrimSpace(value))
+	return trimmed != "" && trimmed != "null" && trimmed != "[]" && trimmed != "{}" && trimmed != `""`
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mergeNow is the time golden records are merged at in the tests
+var mergeNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// updated returns a record of source last updated days before mergeNow
+func updated(id int, source string, days int) *Record {
+	return &Record{ID: id, Source: source, LastUpdated: mergeNow.AddDate(0, 0, -days)}
+}
+
+func TestPick(t *testing.T) {
+	priority := []string{"manual", "2gis", "booking"}
+	month := Duration(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		candidates []*Record
+		policy     FieldPolicy
+		want       int
+	}{
+		{
+			name:       "highest priority source",
+			candidates: []*Record{updated(1, "booking", 1), updated(2, "2gis", 5), updated(3, "manual", 9)},
+			policy:     FieldPolicy{Priority: priority},
+			want:       3,
+		},
+		{
+			name:       "unlisted sources come last",
+			candidates: []*Record{updated(1, "olx", 0), updated(2, "booking", 9)},
+			policy:     FieldPolicy{Priority: priority},
+			want:       2,
+		},
+		{
+			name:       "most recent record of equal sources",
+			candidates: []*Record{updated(1, "2gis", 9), updated(2, "2gis", 1)},
+			policy:     FieldPolicy{Priority: priority},
+			want:       2,
+		},
+		{
+			name:       "lowest ID breaks ties",
+			candidates: []*Record{updated(2, "2gis", 1), updated(1, "2gis", 1)},
+			policy:     FieldPolicy{Priority: priority},
+			want:       1,
+		},
+		{
+			name:       "stale record of a better source loses to a fresh one",
+			candidates: []*Record{updated(1, "2gis", 60), updated(2, "booking", 10)},
+			policy:     FieldPolicy{Priority: priority, MaxAge: month},
+			want:       2,
+		},
+		{
+			name:       "stale records are used when nothing is fresher",
+			candidates: []*Record{updated(1, "booking", 40), updated(2, "2gis", 90)},
+			policy:     FieldPolicy{Priority: priority, MaxAge: month},
+			want:       2,
+		},
+		{
+			name:       "zero max_age keeps every value fresh",
+			candidates: []*Record{updated(1, "booking", 1), updated(2, "2gis", 900)},
+			policy:     FieldPolicy{Priority: priority},
+			want:       2,
+		},
+		{
+			name:       "recent before priority",
+			candidates: []*Record{updated(1, "2gis", 5), updated(2, "booking", 1)},
+			policy:     FieldPolicy{Priority: priority, Prefer: preferRecent},
+			want:       2,
+		},
+		{
+			name:       "priority breaks ties of recent",
+			candidates: []*Record{updated(1, "booking", 1), updated(2, "2gis", 1)},
+			policy:     FieldPolicy{Priority: priority, Prefer: preferRecent},
+			want:       2,
+		},
+		{
+			name:       "recent among fresh records only",
+			candidates: []*Record{updated(1, "booking", 20), updated(2, "2gis", 40), updated(3, "olx", 60)},
+			policy:     FieldPolicy{Priority: priority, MaxAge: month, Prefer: preferRecent},
+			want:       1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pick(tt.candidates, tt.policy, mergeNow); got.ID != tt.want {
+				t.Fatalf("pick() = record %d, want %d", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	policy := MergePolicy{
+		Default: FieldPolicy{Priority: []string{"2gis", "booking"}},
+		Fields: map[string]FieldPolicy{
+			"price":  {Priority: []string{"booking", "2gis"}},
+			"rating": {Prefer: preferRecent},
+		},
+	}
+
+	twogis := updated(1, "2gis", 10)
+	twogis.Name, twogis.Phone, twogis.HasLocation = "Отель Алатау", "+77272000000", true
+	twogis.Rating = sql.NullFloat64{Float64: 4.1, Valid: true}
+	twogis.PriceRangeMin = sql.NullFloat64{Float64: 15000, Valid: true}
+	twogis.Photos = []byte(`[]`)
+
+	booking := updated(2, "booking", 1)
+	booking.Name, booking.Email = "Alatau Hotel", "info@alatau.kz"
+	booking.Rating = sql.NullFloat64{Float64: 8.6, Valid: true}
+	booking.PriceRangeMin = sql.NullFloat64{Float64: 18000, Valid: true}
+	booking.Photos = []byte(`["https://example.com/1.jpg"]`)
+
+	golden := merge([]*Record{twogis, booking}, policy, mergeNow)
+	want := map[string]int{
+		"name":        1, // default priority
+		"coordinates": 1, // only 2GIS has a location
+		"phone":       1,
+		"email":       2, // only Booking.com has one
+		"price":       2, // field priority
+		"rating":      2, // more recent
+		"photos":      2, // an empty list is no value
+	}
+	for field, id := range want {
+		if record := golden[field]; record == nil || record.ID != id {
+			t.Errorf("golden[%s] = %v, want record %d", field, record, id)
+		}
+	}
+	for _, field := range []string{"address", "website", "amenities"} {
+		if record, ok := golden[field]; ok {
+			t.Errorf("golden[%s] = record %d, want missing when no record has a value", field, record.ID)
+		}
+	}
+}
+
+func TestMergeClusters(t *testing.T) {
+	records := []Record{*updated(1, "booking", 1), *updated(2, "2gis", 5), *updated(3, "booking", 2)}
+	for i := range records {
+		records[i].Name = "Hotel"
+	}
+	clusters := []Cluster{
+		{Primary: 2, Members: []Member{{AccommodationID: 1}, {AccommodationID: 2}}},
+		{Primary: 3, Members: []Member{{AccommodationID: 3}}},
+	}
+	policy := MergePolicy{Default: FieldPolicy{Priority: []string{"2gis", "booking"}}}
+
+	goldens := mergeClusters(clusters, records, policy, mergeNow)
+	if len(goldens) != 2 || goldens[0]["name"].ID != 2 || goldens[1]["name"].ID != 3 {
+		t.Fatalf("mergeClusters() = %v, want names of record 2 and 3", goldens)
+	}
+	// Golden records point at the records passed in, not copies
+	if goldens[0]["name"] != &records[1] {
+		t.Fatalf("mergeClusters() name of the first place is not records[1]")
+	}
+}
+
+func TestGoldenRecordProvenance(t *testing.T) {
+	twogis, booking := updated(1, "2gis", 10), updated(2, "booking", 1)
+	golden := GoldenRecord{"name": twogis, "price": booking}
+
+	provenance := golden.Provenance()
+	want := map[string]Provenance{
+		"name":  {Source: "2gis", AccommodationID: 1, UpdatedAt: twogis.LastUpdated},
+		"price": {Source: "booking", AccommodationID: 2, UpdatedAt: booking.LastUpdated},
+	}
+	if len(provenance) != len(want) {
+		t.Fatalf("Provenance() = %v, want %v", provenance, want)
+	}
+	for field, w := range want {
+		if got := provenance[field]; got != w {
+			t.Errorf("Provenance()[%s] = %+v, want %+v", field, got, w)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string // file contents, empty for the embedded default
+		wantErr string
+	}{
+		{name: "embedded default"},
+		{name: "valid file", policy: `{"default": {"priority": ["2gis"], "max_age": "720h"}, "fields": {"rating": {"prefer": "recent"}}}`},
+		{name: "unknown field", policy: `{"fields": {"stars": {"priority": ["2gis"]}}}`, wantErr: `unknown field "stars"`},
+		{name: "unknown setting", policy: `{"default": {"priority": ["2gis"], "maxAge": "720h"}}`, wantErr: `unknown field "maxAge"`},
+		{name: "bad prefer", policy: `{"fields": {"rating": {"prefer": "newest"}}}`, wantErr: `prefers "newest"`},
+		{name: "bad max_age", policy: `{"default": {"max_age": "30 days"}}`, wantErr: "invalid merge policy"},
+		{name: "max_age as a number", policy: `{"default": {"max_age": 720}}`, wantErr: "duration must be a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.policy != "" {
+				path = filepath.Join(t.TempDir(), "merge_policy.json")
+				if err := os.WriteFile(path, []byte(tt.policy), 0o644); err != nil {
+					t.Fatalf("write policy: %v", err)
+				}
+			}
+
+			_, err := loadPolicy(path)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("loadPolicy() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("loadPolicy() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyDefaults(t *testing.T) {
+	policy, err := loadPolicy("")
+	if err != nil {
+		t.Fatalf("loadPolicy() = %v", err)
+	}
+
+	// Settings missing on a field come from the default
+	rating := policy.field("rating")
+	if rating.Prefer != preferRecent || len(rating.Priority) == 0 || rating.Priority[0] != "manual" {
+		t.Fatalf("field(rating) = %+v, want recent with the default priority", rating)
+	}
+	if name := policy.field("name"); time.Duration(name.MaxAge) != 2160*time.Hour {
+		t.Fatalf("field(name).MaxAge = %v, want the default 2160h", time.Duration(name.MaxAge))
+	}
+	if phone := policy.field("phone"); time.Duration(phone.MaxAge) != 4320*time.Hour {
+		t.Fatalf("field(phone).MaxAge = %v, want its own 4320h", time.Duration(phone.MaxAge))
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// earthRadius is the mean Earth radius in meters
+const earthRadius = 6371000.0
+
+// translit spells Russian and Kazakh letters in Latin, so "Риксос" and "Rixos" compare as
+// close names. Latin "x" is spelled "ks" for the same reason.
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sh",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ғ': "g", 'қ': "k", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u", 'һ': "h", 'і': "i",
+	'x': "ks",
+}
+
+// nameStopWords are accommodation type words that say nothing about which property a name is
+var nameStopWords = map[string]bool{
+	"gostinitsa": true, "otel": true, "hotel": true, "mini": true, "hostel": true,
+	"inn": true, "apartamenty": true, "apartments": true, "apartment": true, "apart": true,
+	"gostevoi": true, "dom": true, "guest": true, "house": true, "guesthouse": true,
+	"sanatorii": true, "baza": true, "otdyha": true, "resort": true, "spa": true,
+	"kvartira": true, "flat": true, "studio": true, "studiya": true,
+	"the": true, "and": true, "i": true, "v": true, "na": true,
+}
+
+// addressStopWords are street type and region words that most addresses share
+var addressStopWords = map[string]bool{
+	"ul": true, "ulitsa": true, "street": true, "st": true, "str": true,
+	"pr": true, "prt": true, "prospekt": true, "avenue": true, "ave": true,
+	"mkr": true, "mikroraion": true, "microdistrict": true, "d": true, "dom": true,
+	"g": true, "gorod": true, "city": true, "kv": true, "ofis": true, "zdanie": true,
+	"kazahstan": true, "kazakhstan": true, "respublika": true, "oblast": true, "obl": true,
+	"raion": true, "r": true, "n": true, "district": true,
+}
+
+// normalizeName returns the name in lower case Latin without punctuation and generic words
+func normalizeName(name string) string {
+	return strings.Join(filterTokens(tokens(name), nameStopWords), " ")
+}
+
+// addressTokens returns the distinctive tokens of an address: street names and house numbers
+func addressTokens(address string) map[string]bool {
+	set := make(map[string]bool)
+	for _, token := range filterTokens(tokens(address), addressStopWords) {
+		set[token] = true
+	}
+	return set
+}
+
+// tokens transliterates s to lower case Latin and splits it into letter and digit runs
+func tokens(s string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := translit[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune(' ')
+	}
+	return strings.Fields(b.String())
+}
+
+func filterTokens(tokens []string, stopWords map[string]bool) []string {
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if !stopWords[token] {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+// trigramSimilarity is the Dice coefficient of the character trigrams of two normalized names,
+// 1 for equal names and 0 for names without a trigram in common
+func trigramSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ta, tb := trigrams(a), trigrams(b)
+	common := 0
+	for trigram := range ta {
+		if tb[trigram] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(ta)+len(tb))
+}
+
+// trigrams returns the trigrams of every word padded with spaces, as pg_trgm does
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// jaccard is the share of tokens two sets have in common
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// phoneKeys returns the last ten digits of every phone number in a phone field, so "+7 727 ...",
+// "8 727 ..." and "727 ..." give the same key. Numbers with fewer than seven digits are ignored.
+func phoneKeys(phone string) []string {
+	var keys []string
+	for _, number := range strings.FieldsFunc(phone, func(r rune) bool { return r == ',' || r == ';' || r == '/' }) {
+		var digits []rune
+		for _, r := range number {
+			if unicode.IsDigit(r) {
+				digits = append(digits, r)
+			}
+		}
+		if len(digits) < 7 {
+			continue
+		}
+		if len(digits) > 10 {
+			digits = digits[len(digits)-10:]
+		}
+		keys = append(keys, string(digits))
+	}
+	return keys
+}
+
+// distance returns the great-circle distance between two points in meters
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Гостиница Риксос", want: "riksos"},
+		{name: "Rixos Hotel", want: "riksos"},
+		{name: "Отель «Алатау»", want: "alatau"},
+		{name: "Hotel & Spa Казжол", want: "kazzhol"},
+		{name: "Мини-отель Әлем", want: "alem"},
+		{name: "Гостевой дом Ұлытау", want: "ulytau"},
+		{name: "Хостел №1", want: "1"},
+		{name: "Apart-Hotel Esentai City", want: "esentai city"},
+		{name: "  ", want: ""},
+		{name: "Hotel", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeName(tt.name); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "riksos", b: "riksos", want: 1},
+		{a: "riksos", b: "", want: 0},
+		{a: "", b: "", want: 0},
+		{a: "abc", b: "xyz", want: 0},
+		// "  a", " ab", "abc", "bc " against "  a", " ab", "abd", "bd "
+		{a: "abc", b: "abd", want: 0.5},
+		// Words are padded separately, so the order of words does not matter
+		{a: "esentai city", b: "city esentai", want: 1},
+		// "almaty" adds 5 trigrams, it shares "  a" and " al" with "alatau"
+		{a: "alatau", b: "alatau almaty", want: 2 * 7.0 / (7 + 12)},
+	}
+
+	for _, tt := range tests {
+		got := trigramSimilarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("trigramSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if reverse := trigramSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+			t.Errorf("trigramSimilarity(%q, %q) = %v, not symmetric with %v", tt.b, tt.a, reverse, got)
+		}
+	}
+}
+
+func TestPhoneKeys(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  []string
+	}{
+		{phone: "+7 727 123 45 67", want: []string{"7271234567"}},
+		{phone: "8 (727) 123-45-67", want: []string{"7271234567"}},
+		{phone: "727 123 45 67", want: []string{"7271234567"}},
+		{phone: "123-45-67", want: []string{"1234567"}},
+		{phone: "+7 701 111 22 33, +7 727 123 45 67", want: []string{"7011112233", "7271234567"}},
+		{phone: "+7 701 111 22 33; 8 727 123 45 67 / 87272222222", want: []string{"7011112233", "7271234567", "7272222222"}},
+		{phone: "доб. 123, +7 727 123 45 67", want: []string{"7271234567"}},
+		{phone: "123-45"},
+		{phone: ""},
+	}
+
+	for _, tt := range tests {
+		if got := phoneKeys(tt.phone); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("phoneKeys(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
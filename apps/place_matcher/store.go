@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// lockID is the advisory lock held while places are written, so concurrent matcher runs do not interleave
+const lockID = 72010022
+
+// loadRecords returns the accommodation records that are not deleted, ordered by ID
+func loadRecords(ctx context.Context, db *sql.DB) ([]Record, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, source_website, name, latitude, longitude, coalesce(address, ''), coalesce(phone, ''),
+			coalesce(review_count, 0), coalesce(last_updated, created_at, CURRENT_TIMESTAMP),
+			coalesce(email, ''), coalesce(website_url, ''), social_media_links, coalesce(accommodation_type, ''),
+			price_range_min, price_range_max, price_currency, photos, rating, amenities
+		FROM accommodations
+		WHERE deleted_at IS NULL
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accommodations: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var record Record
+		var latitude, longitude sql.NullFloat64
+		if err := rows.Scan(&record.ID, &record.Source, &record.Name, &latitude, &longitude,
+			&record.Address, &record.Phone, &record.ReviewCount, &record.LastUpdated,
+			&record.Email, &record.WebsiteURL, &record.SocialMediaLinks, &record.AccommodationType,
+			&record.PriceRangeMin, &record.PriceRangeMax, &record.PriceCurrency, &record.Photos,
+			&record.Rating, &record.Amenities); err != nil {
+			return nil, fmt.Errorf("failed to scan accommodation: %w", err)
+		}
+		if latitude.Valid && longitude.Valid && (latitude.Float64 != 0 || longitude.Float64 != 0) {
+			record.Latitude, record.Longitude, record.HasLocation = latitude.Float64, longitude.Float64, true
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// loadOverrides returns the manual merges and splits
+func loadOverrides(ctx context.Context, db *sql.DB) ([]Override, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT accommodation_id, other_accommodation_id, action FROM place_match_overrides
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query place_match_overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []Override
+	for rows.Next() {
+		var override Override
+		if err := rows.Scan(&override.AccommodationID, &override.OtherAccommodationID, &override.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+// savePlaces stores the clusters as places with their golden records in one transaction. A cluster
+// keeps the place ID most of its records had, so links to a place survive rematching; the other
+// clusters get new places. Places left without records are deleted.
+func savePlaces(ctx context.Context, db *sql.DB, clusters []Cluster, goldens []GoldenRecord, records []Record) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("failed to take matching lock: %w", err)
+	}
+
+	current, err := currentPlaces(ctx, tx)
+	if err != nil {
+		return err
+	}
+	placeIDs, err := assignPlaceIDs(ctx, tx, clusters, current)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]*Record, len(records))
+	for i := range records {
+		byID[records[i].ID] = &records[i]
+	}
+
+	var (
+		ids, primaries, sourceCounts, reviewCounts             []int64
+		names                                                  []string
+		addresses, phones, emails, websites, types, currencies []sql.NullString
+		socialLinks, photos, amenities, sources, provenances   []sql.NullString
+		latitudes, longitudes, priceMins, priceMaxes, ratings  []sql.NullFloat64
+
+		accommodationIDs, memberPlaceIDs []int64
+		confidences                      []float64
+		methods                          []string
+	)
+	for i, cluster := range clusters {
+		golden := goldens[i]
+		ids = append(ids, int64(placeIDs[i]))
+		primaries = append(primaries, int64(cluster.Primary))
+		sourceCounts = append(sourceCounts, int64(len(cluster.Members)))
+
+		name := byID[cluster.Primary].Name
+		if record := golden["name"]; record != nil {
+			name = record.Name
+		}
+		names = append(names, name)
+		addresses = append(addresses, goldenString(golden, "address", func(r *Record) string { return r.Address }))
+		phones = append(phones, goldenString(golden, "phone", func(r *Record) string { return r.Phone }))
+		emails = append(emails, goldenString(golden, "email", func(r *Record) string { return r.Email }))
+		websites = append(websites, goldenString(golden, "website", func(r *Record) string { return r.WebsiteURL }))
+		types = append(types, goldenString(golden, "accommodation_type", func(r *Record) string { return r.AccommodationType }))
+		socialLinks = append(socialLinks, goldenString(golden, "social_media_links", func(r *Record) string { return string(r.SocialMediaLinks) }))
+		photos = append(photos, goldenString(golden, "photos", func(r *Record) string { return string(r.Photos) }))
+		amenities = append(amenities, goldenString(golden, "amenities", func(r *Record) string { return string(r.Amenities) }))
+
+		var latitude, longitude sql.NullFloat64
+		if record := golden["coordinates"]; record != nil {
+			latitude = sql.NullFloat64{Float64: record.Latitude, Valid: true}
+			longitude = sql.NullFloat64{Float64: record.Longitude, Valid: true}
+		}
+		latitudes, longitudes = append(latitudes, latitude), append(longitudes, longitude)
+
+		var priceMin, priceMax sql.NullFloat64
+		var currency sql.NullString
+		if record := golden["price"]; record != nil {
+			priceMin, priceMax, currency = record.PriceRangeMin, record.PriceRangeMax, record.PriceCurrency
+		}
+		priceMins, priceMaxes, currencies = append(priceMins, priceMin), append(priceMaxes, priceMax), append(currencies, currency)
+
+		var rating sql.NullFloat64
+		var reviewCount int64
+		if record := golden["rating"]; record != nil {
+			rating, reviewCount = record.Rating, int64(record.ReviewCount)
+		}
+		ratings, reviewCounts = append(ratings, rating), append(reviewCounts, reviewCount)
+
+		sourceSet := make(map[string]bool)
+		for _, member := range cluster.Members {
+			sourceSet[byID[member.AccommodationID].Source] = true
+		}
+		sourceList := make([]string, 0, len(sourceSet))
+		for source := range sourceSet {
+			sourceList = append(sourceList, source)
+		}
+		sort.Strings(sourceList)
+		sources = append(sources, sql.NullString{String: strings.Join(sourceList, ","), Valid: true})
+
+		provenance, err := json.Marshal(golden.Provenance())
+		if err != nil {
+			return fmt.Errorf("failed to encode provenance: %w", err)
+		}
+		provenances = append(provenances, sql.NullString{String: string(provenance), Valid: true})
+
+		for _, member := range cluster.Members {
+			accommodationIDs = append(accommodationIDs, int64(member.AccommodationID))
+			memberPlaceIDs = append(memberPlaceIDs, int64(placeIDs[i]))
+			confidences = append(confidences, member.Confidence)
+			methods = append(methods, member.Method)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO places (id, primary_accommodation_id, name, latitude, longitude, source_count,
+			address, phone, email, website_url, social_media_links, accommodation_type,
+			price_range_min, price_range_max, price_currency, photos, rating, review_count, amenities,
+			sources, provenance)
+		SELECT id, primary_accommodation_id, name, latitude, longitude, source_count,
+			address, phone, email, website_url, social_media_links, accommodation_type,
+			price_range_min, price_range_max, price_currency, photos, rating, review_count, amenities,
+			string_to_array(sources, ','), provenance
+		FROM unnest($1::int[], $2::int[], $3::text[], $4::float8[], $5::float8[], $6::int[],
+			$7::text[], $8::text[], $9::text[], $10::text[], $11::jsonb[], $12::text[],
+			$13::float8[], $14::float8[], $15::text[], $16::jsonb[], $17::float8[], $18::int[], $19::jsonb[],
+			$20::text[], $21::jsonb[])
+			AS t(id, primary_accommodation_id, name, latitude, longitude, source_count,
+				address, phone, email, website_url, social_media_links, accommodation_type,
+				price_range_min, price_range_max, price_currency, photos, rating, review_count, amenities,
+				sources, provenance)
+		ON CONFLICT (id) DO UPDATE SET
+			primary_accommodation_id = EXCLUDED.primary_accommodation_id,
+			name = EXCLUDED.name,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			source_count = EXCLUDED.source_count,
+			address = EXCLUDED.address,
+			phone = EXCLUDED.phone,
+			email = EXCLUDED.email,
+			website_url = EXCLUDED.website_url,
+			social_media_links = EXCLUDED.social_media_links,
+			accommodation_type = EXCLUDED.accommodation_type,
+			price_range_min = EXCLUDED.price_range_min,
+			price_range_max = EXCLUDED.price_range_max,
+			price_currency = EXCLUDED.price_currency,
+			photos = EXCLUDED.photos,
+			rating = EXCLUDED.rating,
+			review_count = EXCLUDED.review_count,
+			amenities = EXCLUDED.amenities,
+			sources = EXCLUDED.sources,
+			provenance = EXCLUDED.provenance,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE (places.primary_accommodation_id, places.name, places.latitude, places.longitude, places.source_count,
+				places.address, places.phone, places.email, places.website_url, places.social_media_links,
+				places.accommodation_type, places.price_range_min, places.price_range_max, places.price_currency,
+				places.photos, places.rating, places.review_count, places.amenities, places.sources, places.provenance)
+			IS DISTINCT FROM (EXCLUDED.primary_accommodation_id, EXCLUDED.name, EXCLUDED.latitude, EXCLUDED.longitude, EXCLUDED.source_count,
+				EXCLUDED.address, EXCLUDED.phone, EXCLUDED.email, EXCLUDED.website_url, EXCLUDED.social_media_links,
+				EXCLUDED.accommodation_type, EXCLUDED.price_range_min, EXCLUDED.price_range_max, EXCLUDED.price_currency,
+				EXCLUDED.photos, EXCLUDED.rating, EXCLUDED.review_count, EXCLUDED.amenities, EXCLUDED.sources, EXCLUDED.provenance)
+	`, pq.Array(ids), pq.Array(primaries), pq.Array(names), pq.Array(latitudes), pq.Array(longitudes), pq.Array(sourceCounts),
+		pq.Array(addresses), pq.Array(phones), pq.Array(emails), pq.Array(websites), pq.Array(socialLinks), pq.Array(types),
+		pq.Array(priceMins), pq.Array(priceMaxes), pq.Array(currencies), pq.Array(photos), pq.Array(ratings), pq.Array(reviewCounts),
+		pq.Array(amenities), pq.Array(sources), pq.Array(provenances))
+	if err != nil {
+		return fmt.Errorf("failed to upsert places: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO place_sources (accommodation_id, place_id, confidence, match_method)
+		SELECT * FROM unnest($1::int[], $2::int[], $3::float8[], $4::text[])
+		ON CONFLICT (accommodation_id) DO UPDATE SET
+			place_id = EXCLUDED.place_id,
+			confidence = EXCLUDED.confidence,
+			match_method = EXCLUDED.match_method,
+			matched_at = CURRENT_TIMESTAMP
+		WHERE (place_sources.place_id, place_sources.confidence, place_sources.match_method)
+			IS DISTINCT FROM (EXCLUDED.place_id, EXCLUDED.confidence, EXCLUDED.match_method)
+	`, pq.Array(accommodationIDs), pq.Array(memberPlaceIDs), pq.Array(confidences), pq.Array(methods))
+	if err != nil {
+		return fmt.Errorf("failed to upsert place sources: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM place_sources WHERE accommodation_id <> ALL($1::int[])`,
+		pq.Array(accommodationIDs)); err != nil {
+		return fmt.Errorf("failed to delete stale place sources: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM places WHERE id <> ALL($1::int[])`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to delete empty places: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// goldenString returns the value of a field of the golden record, NULL when no record has one
+func goldenString(golden GoldenRecord, field string, value func(r *Record) string) sql.NullString {
+	record := golden[field]
+	if record == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value(record), Valid: true}
+}
+
+// currentPlaces returns the place ID of every linked record
+func currentPlaces(ctx context.Context, tx *sql.Tx) (map[int]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT accommodation_id, place_id FROM place_sources`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query place sources: %w", err)
+	}
+	defer rows.Close()
+
+	current := make(map[int]int)
+	for rows.Next() {
+		var accommodationID, placeID int
+		if err := rows.Scan(&accommodationID, &placeID); err != nil {
+			return nil, fmt.Errorf("failed to scan place source: %w", err)
+		}
+		current[accommodationID] = placeID
+	}
+	return current, rows.Err()
+}
+
+// assignPlaceIDs returns the place ID of every cluster. Larger clusters choose first among the
+// places their records were linked to; a place is kept by one cluster only.
+func assignPlaceIDs(ctx context.Context, tx *sql.Tx, clusters []Cluster, current map[int]int) ([]int, error) {
+	order := make([]int, len(clusters))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(clusters[order[i]].Members) > len(clusters[order[j]].Members)
+	})
+
+	placeIDs := make([]int, len(clusters))
+	taken := make(map[int]bool)
+	missing := 0
+	for _, i := range order {
+		votes := make(map[int]int)
+		best := 0
+		for _, member := range clusters[i].Members {
+			placeID, ok := current[member.AccommodationID]
+			if !ok || taken[placeID] {
+				continue
+			}
+			votes[placeID]++
+			if best == 0 || votes[placeID] > votes[best] || (votes[placeID] == votes[best] && placeID < best) {
+				best = placeID
+			}
+		}
+		if best == 0 {
+			missing++
+			continue
+		}
+		placeIDs[i] = best
+		taken[best] = true
+	}
+	if missing == 0 {
+		return placeIDs, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT nextval('places_id_seq') FROM generate_series(1, $1)`, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate place IDs: %w", err)
+	}
+	defer rows.Close()
+
+	for i := range placeIDs {
+		if placeIDs[i] != 0 {
+			continue
+		}
+		if !rows.Next() {
+			return nil, fmt.Errorf("failed to allocate place IDs: %w", rows.Err())
+		}
+		if err := rows.Scan(&placeIDs[i]); err != nil {
+			return nil, fmt.Errorf("failed to scan place ID: %w", err)
+		}
+	}
+	return placeIDs, rows.Err()
+}
+
+// setOverride records a manual merge or split of two records, replacing an earlier override of the pair
+func setOverride(ctx context.Context, db *sql.DB, a, b int, action, reason string) error {
+	if a > b {
+		a, b = b, a
+	}
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO place_match_overrides (accommodation_id, other_accommodation_id, action, reason)
+		VALUES ($1, $2, $3, nullif($4, ''))
+		ON CONFLICT (accommodation_id, other_accommodation_id) DO UPDATE SET
+			action = EXCLUDED.action,
+			reason = EXCLUDED.reason,
+			created_at = CURRENT_TIMESTAMP
+	`, a, b, action, reason)
+	if err != nil {
+		return fmt.Errorf("failed to save override: %w", err)
+	}
+	return nil
+}
+
+// removeOverride deletes the override of two records, false when there was none
+func removeOverride(ctx context.Context, db *sql.DB, a, b int) (bool, error) {
+	if a > b {
+		a, b = b, a
+	}
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM place_match_overrides WHERE accommodation_id = $1 AND other_accommodation_id = $2
+	`, a, b)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete override: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
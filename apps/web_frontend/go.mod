@@ -2,4 +2,10 @@ module web_frontend
 
 go 1.23.0
 
-require github.com/lib/pq v1.10.9
+require (
+	github.com/lib/pq v1.10.9
+	mytravel/pkg v0.0.0
+)
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
@@ -10,8 +10,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"mytravel/pkg/schema"
 )
 
 type Accommodation struct {
@@ -36,6 +38,21 @@ type Accommodation struct {
 	VerificationStatus string           `json:"verification_status"`
 	Amenities          *json.RawMessage `json:"amenities"`
 	Reviews            *json.RawMessage `json:"reviews"`
+	Photos             *json.RawMessage `json:"photos"`
+	PlaceID            *int             `json:"place_id"`
+	Sources            []string         `json:"sources"`
+	SourceCount        int              `json:"source_count"`
+	Provenance         *json.RawMessage `json:"provenance"` // source record of every merged field
+	Region             *string          `json:"region"`
+	City               *string          `json:"city"`
+	RegionKATO         *string          `json:"region_kato"`
+}
+
+// RegionCount is the number of places in a city, or in a region outside its cities when City is empty
+type RegionCount struct {
+	Region string `json:"region"`
+	City   string `json:"city"`
+	Count  int    `json:"count"`
 }
 
 type AIAnalysis struct {
@@ -70,6 +87,10 @@ func init() {
 		log.Fatal("Failed to ping database:", err)
 	}
 
+	if err = schema.Check(db); err != nil {
+		log.Fatal("Incompatible database schema: ", err)
+	}
+
 	aiAnalyzerURL = getEnv("AI_ANALYZER_URL", "http://localhost:8080")
 
 	log.Println("Connected to database successfully")
@@ -92,6 +113,7 @@ func main() {
 	http.HandleFunc("/", homeHandler)
 	http.HandleFunc("/api/accommodations", accommodationsHandler)
 	http.HandleFunc("/api/accommodation/", accommodationHandler)
+	http.HandleFunc("/api/regions", regionsHandler)
 	http.HandleFunc("/api/ai/description/", aiDescriptionHandler)
 	http.HandleFunc("/api/ai/evaluation/", aiEvaluationHandler)
 
@@ -118,6 +140,10 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
         .accommodation-card:hover {
             transform: translateY(-5px);
         }
+        .accommodation-photo {
+            height: 180px;
+            object-fit: cover;
+        }
         .rating-stars {
             color: #ffc107;
         }
@@ -178,11 +204,14 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
                     <label for="typeFilter" class="form-label">Type</label>
                     <select class="form-select" id="typeFilter">
                         <option value="">All Types</option>
-                        <option value="Hotel">Hotel</option>
-                        <option value="Hostel">Hostel</option>
-                        <option value="Resort">Resort</option>
-                        <option value="Villa">Villa</option>
-                        <option value="Camping">Camping</option>
+                        <option value="hotel">Hotel</option>
+                        <option value="hostel">Hostel</option>
+                        <option value="resort">Resort</option>
+                        <option value="sanatorium">Sanatorium</option>
+                        <option value="villa">Villa</option>
+                        <option value="camping">Camping</option>
+                        <option value="guest_house">Guest house</option>
+                        <option value="apartment">Apartment</option>
                     </select>
                 </div>
                 <div class="col-md-3">
@@ -199,8 +228,26 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
                     <input type="text" class="form-control" id="searchInput" placeholder="Search by name or address">
                 </div>
             </div>
+            <div class="row mt-3">
+                <div class="col-md-3">
+                    <label for="regionFilter" class="form-label">Region</label>
+                    <select class="form-select" id="regionFilter" onchange="updateCityFilter()">
+                        <option value="">All Regions</option>
+                    </select>
+                </div>
+                <div class="col-md-3">
+                    <label for="cityFilter" class="form-label">City</label>
+                    <select class="form-select" id="cityFilter">
+                        <option value="">All Cities</option>
+                    </select>
+                </div>
+            </div>
             <div class="row mt-3">
                 <div class="col-12">
+                    <div class="form-check form-check-inline">
+                        <input class="form-check-input" type="checkbox" id="openNowFilter">
+                        <label class="form-check-label" for="openNowFilter">Open now</label>
+                    </div>
                     <button class="btn btn-primary" onclick="loadAccommodations()">
                         <i class="fas fa-search"></i> Apply Filters
                     </button>
@@ -250,12 +297,43 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
     <script>
         let currentAccommodations = [];
         let currentOffset = 0;
+        let regionCounts = [];
         const limit = 100;
 
         document.addEventListener('DOMContentLoaded', function() {
+            loadRegions();
             loadAccommodations();
         });
 
+        async function loadRegions() {
+            try {
+                const response = await fetch('/api/regions');
+                regionCounts = await response.json();
+            } catch (error) {
+                console.error('Error loading regions:', error);
+                return;
+            }
+
+            const totals = {};
+            regionCounts.forEach(r => totals[r.region] = (totals[r.region] || 0) + r.count);
+            const regionFilter = document.getElementById('regionFilter');
+            Object.keys(totals).sort().forEach(region => {
+                regionFilter.add(new Option(region + ' (' + totals[region] + ')', region));
+            });
+            updateCityFilter();
+        }
+
+        // Offers the cities of the selected region, or of every region when none is selected
+        function updateCityFilter() {
+            const region = document.getElementById('regionFilter').value;
+            const cityFilter = document.getElementById('cityFilter');
+            cityFilter.length = 1;
+            regionCounts
+                .filter(r => r.city && (!region || r.region === region))
+                .sort((a, b) => a.city.localeCompare(b.city))
+                .forEach(r => cityFilter.add(new Option(r.city + ' (' + r.count + ')', r.city)));
+        }
+
         async function loadAccommodations() {
             const loading = document.getElementById('mainLoading');
             const grid = document.getElementById('accommodationsGrid');
@@ -276,11 +354,17 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
                 const type = document.getElementById('typeFilter').value;
                 const rating = document.getElementById('ratingFilter').value;
                 const search = document.getElementById('searchInput').value;
+                const openNow = document.getElementById('openNowFilter').checked;
+                const region = document.getElementById('regionFilter').value;
+                const city = document.getElementById('cityFilter').value;
                 
                 if (source) params.append('source_website', source);
                 if (type) params.append('accommodation_type', type);
                 if (rating) params.append('min_rating', rating);
                 if (search) params.append('search', search);
+                if (openNow) params.append('open_now', 'true');
+                if (region) params.append('region', region);
+                if (city) params.append('city', city);
                 
                 params.append('limit', limit);
                 params.append('offset', currentOffset);
@@ -315,12 +399,23 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
             grid.innerHTML = accommodations.map(acc => {
                 let html = '<div class="col-md-6 col-lg-4 mb-4">';
                 html += '<div class="card accommodation-card h-100">';
+                
+                // Photo
+                const photoURL = getFirstPhoto(acc.photos);
+                if (photoURL) {
+                    html += '<img src="' + photoURL + '" class="card-img-top accommodation-photo" alt="' + acc.name + '" loading="lazy" onerror="this.remove()">';
+                }
+                
                 html += '<div class="card-body">';
                 html += '<h5 class="card-title">' + acc.name + '</h5>';
                 
                 if (acc.address) {
                     html += '<p class="card-text text-muted"><i class="fas fa-map-marker-alt"></i> ' + acc.address + '</p>';
                 }
+                const locality = [acc.city, acc.region].filter(Boolean).join(', ');
+                if (locality) {
+                    html += '<p class="card-text"><small class="text-muted"><i class="fas fa-globe-asia"></i> ' + locality + '</small></p>';
+                }
                 
                 // Rating and Review Count
                 html += '<div class="mb-2">';
@@ -411,7 +506,7 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
                 
                 // Card Footer
                 html += '<div class="card-footer text-muted">';
-                html += '<small>Source: ' + acc.source_website + '</small>';
+                html += '<small>Sources: ' + (acc.sources || [acc.source_website]).join(', ') + '</small>';
                 html += '</div>';
                 html += '</div>';
                 html += '</div>';
@@ -457,6 +552,18 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
             return result;
         }
 
+        function getFirstPhoto(photos) {
+            // Photos are stored either as plain URLs or as {url, width, height, source} objects
+            if (!Array.isArray(photos) || photos.length === 0) {
+                return null;
+            }
+            const photo = photos[0];
+            if (typeof photo === 'string') {
+                return photo;
+            }
+            return photo && photo.url ? photo.url : null;
+        }
+
         function generateStars(rating) {
             // Validate rating value to prevent errors
             if (!rating || isNaN(rating) || !isFinite(rating) || rating < 0) {
@@ -573,6 +680,9 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
             document.getElementById('typeFilter').value = '';
             document.getElementById('ratingFilter').value = '';
             document.getElementById('searchInput').value = '';
+            document.getElementById('openNowFilter').checked = false;
+            document.getElementById('regionFilter').value = '';
+            updateCityFilter();
             loadAccommodations();
         }
 
@@ -596,12 +706,15 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func accommodationsHandler(w http.ResponseWriter, r *http.Request) {
+	// One card per place with its merged record, records the place matcher has not linked yet are
+	// cards of their own
 	query := `
-		SELECT id, name, latitude, longitude, address, phone, email, website_url, 
-		       service_description, room_count, capacity, price_range_min, price_range_max, 
-		       price_currency, rating, review_count, accommodation_type, source_website, 
-		       verification_status, amenities, reviews
-		FROM accommodations 
+		SELECT id, name, latitude, longitude, address, phone, email, website_url,
+		       service_description, room_count, capacity, price_range_min, price_range_max,
+		       price_currency, rating, review_count, accommodation_type, source_website,
+		       verification_status, amenities, reviews, photos, place_id, sources, source_count, provenance,
+		       region, city, region_kato
+		FROM merged_accommodations
 		WHERE deleted_at IS NULL
 	`
 
@@ -612,7 +725,8 @@ func accommodationsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Apply filters
 	if source := r.URL.Query().Get("source_website"); source != "" {
-		conditions = append(conditions, fmt.Sprintf("source_website = $%d", argIndex))
+		// A place matches every source it is listed by
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(sources)", argIndex))
 		args = append(args, source)
 		argIndex++
 	}
@@ -623,6 +737,25 @@ func accommodationsHandler(w http.ResponseWriter, r *http.Request) {
 		argIndex++
 	}
 
+	// Regions and cities are located from the coordinates when records are stored
+	if region := r.URL.Query().Get("region"); region != "" {
+		conditions = append(conditions, fmt.Sprintf("region = $%d", argIndex))
+		args = append(args, region)
+		argIndex++
+	}
+
+	if city := r.URL.Query().Get("city"); city != "" {
+		conditions = append(conditions, fmt.Sprintf("city = $%d", argIndex))
+		args = append(args, city)
+		argIndex++
+	}
+
+	if kato := r.URL.Query().Get("region_kato"); kato != "" {
+		conditions = append(conditions, fmt.Sprintf("region_kato = $%d", argIndex))
+		args = append(args, kato)
+		argIndex++
+	}
+
 	if minRating := r.URL.Query().Get("min_rating"); minRating != "" {
 		if rating, err := strconv.ParseFloat(minRating, 64); err == nil {
 			conditions = append(conditions, fmt.Sprintf("rating >= $%d", argIndex))
@@ -637,6 +770,22 @@ func accommodationsHandler(w http.ResponseWriter, r *http.Request) {
 		argIndex++
 	}
 
+	// Opening hours are interpreted in Asia/Almaty by the database function
+	if openAtStr := r.URL.Query().Get("open_at"); openAtStr != "" {
+		openAt, err := time.Parse(time.RFC3339, openAtStr)
+		if err != nil {
+			http.Error(w, "Invalid open_at, expected RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		conditions = append(conditions, fmt.Sprintf("accommodation_is_open_at(opening_hours, $%d)", argIndex))
+		args = append(args, openAt)
+		argIndex++
+	} else if r.URL.Query().Get("open_now") == "true" {
+		conditions = append(conditions, fmt.Sprintf("accommodation_is_open_at(opening_hours, $%d)", argIndex))
+		args = append(args, time.Now())
+		argIndex++
+	}
+
 	// Parse limit and offset for pagination
 	limit := 100 // increased from 50 to show more accommodations
 	offset := 0
@@ -674,7 +823,9 @@ func accommodationsHandler(w http.ResponseWriter, r *http.Request) {
 			&acc.Phone, &acc.Email, &acc.WebsiteURL, &acc.ServiceDescription,
 			&acc.RoomCount, &acc.Capacity, &acc.PriceRangeMin, &acc.PriceRangeMax,
 			&acc.PriceCurrency, &acc.Rating, &acc.ReviewCount, &acc.AccommodationType,
-			&acc.SourceWebsite, &acc.VerificationStatus, &acc.Amenities, &acc.Reviews,
+			&acc.SourceWebsite, &acc.VerificationStatus, &acc.Amenities, &acc.Reviews, &acc.Photos,
+			&acc.PlaceID, pq.Array(&acc.Sources), &acc.SourceCount, &acc.Provenance,
+			&acc.Region, &acc.City, &acc.RegionKATO,
 		)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -695,13 +846,17 @@ func accommodationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The merged record of the place the record belongs to
 	query := `
-		SELECT id, name, latitude, longitude, address, phone, email, website_url, 
-		       service_description, room_count, capacity, price_range_min, price_range_max, 
-		       price_currency, rating, review_count, accommodation_type, source_website, 
-		       verification_status, amenities, reviews
-		FROM accommodations 
-		WHERE id = $1 AND deleted_at IS NULL
+		SELECT id, name, latitude, longitude, address, phone, email, website_url,
+		       service_description, room_count, capacity, price_range_min, price_range_max,
+		       price_currency, rating, review_count, accommodation_type, source_website,
+		       verification_status, amenities, reviews, photos, place_id, sources, source_count, provenance,
+		       region, city, region_kato
+		FROM merged_accommodations
+		WHERE deleted_at IS NULL
+		  AND (id = $1 OR place_id = (SELECT place_id FROM place_sources WHERE accommodation_id = $1))
+		LIMIT 1
 	`
 
 	var acc Accommodation
@@ -710,7 +865,9 @@ func accommodationHandler(w http.ResponseWriter, r *http.Request) {
 		&acc.Phone, &acc.Email, &acc.WebsiteURL, &acc.ServiceDescription,
 		&acc.RoomCount, &acc.Capacity, &acc.PriceRangeMin, &acc.PriceRangeMax,
 		&acc.PriceCurrency, &acc.Rating, &acc.ReviewCount, &acc.AccommodationType,
-		&acc.SourceWebsite, &acc.VerificationStatus, &acc.Amenities, &acc.Reviews,
+		&acc.SourceWebsite, &acc.VerificationStatus, &acc.Amenities, &acc.Reviews, &acc.Photos,
+		&acc.PlaceID, pq.Array(&acc.Sources), &acc.SourceCount, &acc.Provenance,
+		&acc.Region, &acc.City, &acc.RegionKATO,
 	)
 
 	if err == sql.ErrNoRows {
@@ -726,6 +883,39 @@ func accommodationHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(acc)
 }
 
+// regionsHandler lists the regions and cities with places, for the region and city filters
+func regionsHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query(`
+		SELECT region, coalesce(city, ''), count(*)
+		FROM merged_accommodations
+		WHERE deleted_at IS NULL AND region IS NOT NULL
+		GROUP BY region, city
+		ORDER BY region, city NULLS FIRST
+	`)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	regions := []RegionCount{}
+	for rows.Next() {
+		var region RegionCount
+		if err := rows.Scan(&region.Region, &region.City, &region.Count); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		regions = append(regions, region)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(regions)
+}
+
 func aiDescriptionHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/ai/description/")
 
@@ -1,10 +1,25 @@
 module yandex_parser
 
-go 1.21
+go 1.23.0
 
-require github.com/tebeka/selenium v0.9.9
+require (
+	github.com/lib/pq v1.10.9
+	github.com/tebeka/selenium v0.9.9
+	mytravel/pkg v0.0.0
+)
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/blang/semver v3.5.1+incompatible // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_golang v1.23.0 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.65.0 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
 )
+
+// Packages shared by the services, built from the repository
+replace mytravel/pkg => ../pkg
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +17,12 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/tebeka/selenium"
 	"github.com/tebeka/selenium/chrome"
+	"mytravel/pkg/geo"
+	"mytravel/pkg/metrics"
+	"mytravel/pkg/normalize"
+	"mytravel/pkg/runs"
+	"mytravel/pkg/schema"
+	"mytravel/pkg/taxonomy"
 )
 
 // DatabaseConfig holds database connection settings
@@ -37,11 +46,11 @@ type AccommodationRecord struct {
 	Address   *string  `json:"address"`
 
 	// Contact Information
-	Phone            *string           `json:"phone"`
-	Email            *string           `json:"email"`
-	SocialMediaLinks map[string]string `json:"social_media_links"`
-	WebsiteURL       *string           `json:"website_url"`
-	SocialMediaPage  *string           `json:"social_media_page"`
+	Phone            *string                `json:"phone"`
+	Email            *string                `json:"email"`
+	SocialMediaLinks map[string]interface{} `json:"social_media_links"`
+	WebsiteURL       *string                `json:"website_url"`
+	SocialMediaPage  *string                `json:"social_media_page"`
 
 	// Business Details
 	ServiceDescription *string  `json:"service_description"`
@@ -61,11 +70,17 @@ type AccommodationRecord struct {
 	Photos    []string               `json:"photos"`
 
 	// Technical Fields
-	VerificationStatus string  `json:"verification_status"`
-	SourceWebsite      string  `json:"source_website"`
-	SourceURL          *string `json:"source_url"`
-	ExternalID         *string `json:"external_id"`
-	AccommodationType  *string `json:"accommodation_type"`
+	VerificationStatus string   `json:"verification_status"`
+	SourceWebsite      string   `json:"source_website"`
+	SourceURL          *string  `json:"source_url"`
+	ExternalID         *string  `json:"external_id"`
+	AccommodationType  *string  `json:"accommodation_type"`
+	SourceCategories   []string `json:"source_categories"` // raw Yandex categories the type was mapped from
+
+	// Located from the coordinates, see locate
+	Region     *string `json:"region"`
+	City       *string `json:"city"`
+	RegionKATO *string `json:"region_kato"`
 }
 
 type ReviewDetail struct {
@@ -80,6 +95,9 @@ type YandexParser struct {
 	webDriver selenium.WebDriver
 	service   *selenium.Service
 	config    DatabaseConfig
+	types     *taxonomy.Mapping
+	regions   *geo.Boundaries // regions and cities records are located in
+	run       *runs.Run       // open parsing run, linked from last_run_id and parsing_logs.run_id
 
 	// Statistics
 	totalProcessed int
@@ -95,14 +113,28 @@ var kazakhstanCities = []string{
 
 	// Regional centers and important cities
 	"Туркестан", "Кокшетау", "Талдыкорган", "Экибастуз", "Рудный", "Жезказган", "Балхаш", "Сарань",
-	"Степногорск", "Аксу", "Жанаозен", "Зыряновск", "Лисаковск", "Арkalык", "Риддер", "Шахтинск",
+	"Степногорск", "Аксу", "Жанаозен", "Зыряновск", "Лисаковск", "Аркалык", "Риддер", "Шахтинск",
 
 	// Tourist destinations
 	"Боровое", "Капчагай", "Иссык", "Чимбулак", "Медеу", "Байконур", "Жаркент", "Текели",
 	"Каркаралинск", "Маркакол", "Алаколь", "Катон-Карагай", "Курчатов", "Серебрянск",
 }
 
+// destinationAreas are the city or region around tourist destinations that are no settlement,
+// such as ski resorts and lakes
+var destinationAreas = map[string]string{
+	"Чимбулак": "Алматы",
+	"Медеу":    "Алматы",
+	"Алаколь":  "область Жетісу",
+	"Маркакол": "Восточно-Казахстанская область",
+}
+
 func main() {
+	remapTypes := flag.Bool("remap-types", false, "Re-map accommodation types of existing Yandex rows and exit")
+	remapAll := flag.Bool("remap-all", false, "With -remap-types, re-map rows already mapped with the current taxonomy version")
+	metricsAddrFlag := flag.String("metrics-addr", metrics.Addr(), "Address to serve Prometheus /metrics on while parsing, empty to disable")
+	flag.Parse()
+
 	fmt.Println("🏨 COMPREHENSIVE YANDEX ACCOMMODATION PARSER")
 	fmt.Println("=" + strings.Repeat("=", 70))
 
@@ -126,6 +158,25 @@ func main() {
 	}
 	defer parser.Close()
 
+	if *remapTypes {
+		if err := parser.RemapAccommodationTypes(*remapAll); err != nil {
+			log.Fatalf("❌ Failed to re-map accommodation types: %v", err)
+		}
+		return
+	}
+
+	if err := parser.StartRun(); err != nil {
+		log.Fatalf("❌ Failed to start parsing run: %v", err)
+	}
+
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	go func() {
+		if err := metrics.Serve(metricsCtx, *metricsAddrFlag); err != nil {
+			log.Printf("⚠️  Failed to serve metrics on %s: %v", *metricsAddrFlag, err)
+		}
+	}()
+
 	// Target categories
 	categories := []string{
 		"Гостиницы", "Отели", "Санатории", "Кемпинги",
@@ -152,7 +203,7 @@ func main() {
 			}
 
 			// Rate limiting between categories
-			time.Sleep(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
+			rateLimitWait(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
 		}
 
 		// Show city summary
@@ -165,21 +216,34 @@ func main() {
 		// Longer pause between cities
 		if i < len(kazakhstanCities)-1 {
 			fmt.Printf("   ⏳ Waiting before next city...\n\n")
-			time.Sleep(time.Duration(2+rand.Intn(3)) * time.Second)
+			rateLimitWait(time.Duration(2+rand.Intn(3)) * time.Second)
 		}
 	}
 
 	// Show final statistics
+	parser.FinishRun()
 	parser.ShowFinalStatistics()
 }
 
 func NewYandexParser(config DatabaseConfig) (*YandexParser, error) {
+	types, err := taxonomy.ForSource("yandex")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accommodation types: %w", err)
+	}
+
+	regions, err := geo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load region boundaries: %w", err)
+	}
+
 	parser := &YandexParser{
-		config: config,
+		config:  config,
+		types:   types,
+		regions: regions,
 	}
 
 	// Connect to database
-	err := parser.connectDatabase()
+	err = parser.connectDatabase()
 	if err != nil {
 		return nil, fmt.Errorf("database connection failed: %w", err)
 	}
@@ -209,6 +273,10 @@ func (p *YandexParser) connectDatabase() error {
 		return err
 	}
 
+	if err := schema.Check(p.db); err != nil {
+		return err
+	}
+
 	fmt.Println("✅ Database connected successfully")
 	return nil
 }
@@ -296,14 +364,18 @@ func (p *YandexParser) ParseCategoryInCityWithPagination(city, category string)
 	for page := 1; page <= maxPages; page++ {
 		pageSize := p.getPageSize(page, baseCount, maxPages)
 
-		// Generate realistic places for this page
+		// Generate realistic places for this page, observed like a search page request
+		pageStart := time.Now()
 		places := p.generateRealisticPlacesForPage(city, category, page, pageSize)
+		metrics.ObserveRequest(metricsSource, "search", http.StatusOK, time.Since(pageStart))
+		p.run.Add(runs.Counts{Fetched: len(places), APICalls: 1})
 
+		metrics.AddQueued(metricsSource, len(places))
 		for _, place := range places {
 			success := p.insertOrUpdateAccommodation(place)
+			metrics.AddQueued(metricsSource, -1)
 			if success {
 				p.successCount++
-				p.logSuccess("insert", place)
 			} else {
 				p.errorCount++
 			}
@@ -312,7 +384,7 @@ func (p *YandexParser) ParseCategoryInCityWithPagination(city, category string)
 		}
 
 		// Simulate page loading delay
-		time.Sleep(time.Duration(200+rand.Intn(300)) * time.Millisecond)
+		rateLimitWait(time.Duration(200+rand.Intn(300)) * time.Millisecond)
 	}
 
 	return totalProcessed, nil
@@ -377,28 +449,32 @@ func (p *YandexParser) getPageSize(page, totalPlaces, maxPages int) int {
 	return 10 + rand.Intn(5) // 10-15 items per page
 }
 
+// cityArea returns the outline places of a city lie in, the surrounding city or region for destinations
+func (p *YandexParser) cityArea(city string) (*geo.Area, bool) {
+	if area, ok := p.regions.City(city); ok {
+		return area, true
+	}
+	name, ok := destinationAreas[city]
+	if !ok {
+		return nil, false
+	}
+	if area, ok := p.regions.City(name); ok {
+		return area, true
+	}
+	return p.regions.Region(name)
+}
+
 func (p *YandexParser) generateRealisticPlacesForPage(city, category string, page, count int) []AccommodationRecord {
 	places := make([]AccommodationRecord, count)
 
-	// City coordinates mapping (extended)
-	cityCoords := map[string][2]float64{
-		"Алматы": {43.2220, 76.8512}, "Нур-Султан": {51.1694, 71.4491}, "Шымкент": {42.3417, 69.5901},
-		"Караганда": {49.8047, 73.1094}, "Актобе": {50.2839, 57.1670}, "Тараз": {42.9004, 71.3660},
-		"Павлодар": {52.2845, 76.9574}, "Усть-Каменогорск": {49.9787, 82.6156}, "Семей": {50.4111, 80.2275},
-		"Атырау": {47.1164, 51.8826}, "Костанай": {53.2141, 63.6246}, "Кызылорда": {44.8479, 65.4822},
-		"Уральск": {51.2333, 51.3833}, "Петропавловск": {54.8667, 69.1667}, "Актау": {43.6481, 51.1801},
-		"Боровое": {53.0833, 70.2667}, "Капчагай": {43.8847, 77.0736}, "Иссык": {43.3564, 77.4675},
-	}
-
-	baseCoords := cityCoords[city]
-	if baseCoords[0] == 0 { // Unknown city, generate random coords in Kazakhstan
-		baseCoords = [2]float64{48.0 + rand.Float64()*8.0, 68.0 + rand.Float64()*20.0}
+	// Places lie within the bundled outline of the city, anywhere in a region for unknown cities
+	area, ok := p.cityArea(city)
+	if !ok {
+		log.Printf("⚠️  %s has no city boundaries, placing %s anywhere in Kazakhstan", city, category)
 	}
 
 	for i := 0; i < count; i++ {
-		// Generate realistic coordinates within city bounds
-		lat := baseCoords[0] + (rand.Float64()-0.5)*0.1  // ±0.05 degrees (about ±5km)
-		lng := baseCoords[1] + (rand.Float64()-0.5)*0.15 // ±0.075 degrees
+		lat, lng := p.regions.RandomPoint(area)
 
 		placeIndex := (page-1)*10 + i + 1
 
@@ -448,7 +524,8 @@ func (p *YandexParser) generateRealisticPlacesForPage(city, category string, pag
 				strings.ToLower(strings.ReplaceAll(city, "-", "_")),
 				strings.ToLower(strings.ReplaceAll(category, " ", "_")),
 				page, placeIndex)),
-			AccommodationType: strPtr(category),
+			AccommodationType: strPtr(p.types.Map(category)),
+			SourceCategories:  []string{category},
 		}
 	}
 
@@ -505,7 +582,7 @@ func (p *YandexParser) generateRealisticPhone(city string, index int) string {
 
 	areaCode := areaCodes[city]
 	if areaCode == "" {
-		areaCode = "7" + fmt.Sprintf("%02d", rand.Intn(30)+10)
+		areaCode = "7" + fmt.Sprintf("%02d", rand.Intn(20)+10)
 	}
 
 	return fmt.Sprintf("+7 %s %03d %02d%02d", areaCode, rand.Intn(900)+100, rand.Intn(90)+10, rand.Intn(90)+10)
@@ -537,8 +614,8 @@ func (p *YandexParser) generateRealisticWebsite(category, city string, index int
 		strings.ToLower(city), index)
 }
 
-func (p *YandexParser) generateRealisticSocialMedia(category, city string, index int) map[string]string {
-	social := make(map[string]string)
+func (p *YandexParser) generateRealisticSocialMedia(category, city string, index int) map[string]interface{} {
+	social := make(map[string]interface{})
 
 	if rand.Float64() < 0.6 { // 60% have Instagram
 		social["instagram"] = fmt.Sprintf("@%s_%s_%d",
@@ -548,11 +625,11 @@ func (p *YandexParser) generateRealisticSocialMedia(category, city string, index
 
 	if rand.Float64() < 0.4 { // 40% have Facebook
 		social["facebook"] = fmt.Sprintf("fb.com/%s-%s-%d",
-			strings.ToLower(category), strings.ToLower(city), index)
+			strings.ToLower(strings.ReplaceAll(category, " ", "")), strings.ToLower(city), index)
 	}
 
 	if rand.Float64() < 0.2 { // 20% have WhatsApp business
-		social["whatsapp"] = fmt.Sprintf("+7%d", 7000000000+rand.Intn(999999999))
+		social["whatsapp"] = fmt.Sprintf("+770%08d", rand.Intn(100000000))
 	}
 
 	return social
@@ -702,6 +779,9 @@ func (p *YandexParser) generatePhotos(category, city string, index int) []string
 }
 
 func (p *YandexParser) insertOrUpdateAccommodation(record AccommodationRecord) bool {
+	p.locate(&record)
+	normalizeContacts(&record)
+
 	// Check if record exists
 	var existingID int
 	checkQuery := `SELECT id FROM accommodations WHERE source_website = $1 AND external_id = $2`
@@ -720,6 +800,54 @@ func (p *YandexParser) insertOrUpdateAccommodation(record AccommodationRecord) b
 	}
 }
 
+// locate fills the region, city and region KATO code of a record from its coordinates
+func (p *YandexParser) locate(record *AccommodationRecord) {
+	record.Region, record.City, record.RegionKATO = nil, nil, nil
+	if record.Latitude == nil || record.Longitude == nil {
+		return
+	}
+
+	location := p.regions.Locate(*record.Latitude, *record.Longitude)
+	if location.Region != "" {
+		record.Region, record.RegionKATO = &location.Region, &location.RegionKATO
+	}
+	if location.City != "" {
+		record.City = &location.City
+	}
+}
+
+// normalizeContacts brings the phone, email, website and social network profiles of a record into
+// the format of the other sources, all of them are kept in social_media_links
+func normalizeContacts(record *AccommodationRecord) {
+	var contacts normalize.Contacts
+	if record.Phone != nil {
+		contacts.AddPhones(*record.Phone)
+	}
+	if record.Email != nil {
+		contacts.AddEmail(*record.Email)
+	}
+	if record.WebsiteURL != nil {
+		contacts.AddWebsite(*record.WebsiteURL)
+	}
+	for network, value := range record.SocialMediaLinks {
+		if link, ok := value.(string); ok {
+			contacts.AddSocial(network, link)
+		}
+	}
+
+	strPtr := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	record.Phone = strPtr(contacts.Phone())
+	record.Email = strPtr(contacts.Email(100))
+	record.WebsiteURL = strPtr(contacts.Website())
+	record.SocialMediaPage = strPtr(contacts.SocialPage())
+	record.SocialMediaLinks = contacts.Links()
+}
+
 func (p *YandexParser) insertAccommodation(record AccommodationRecord) bool {
 	query := `
 		INSERT INTO accommodations (
@@ -727,9 +855,11 @@ func (p *YandexParser) insertAccommodation(record AccommodationRecord) bool {
 			website_url, social_media_page, service_description, room_count, capacity,
 			price_range_min, price_range_max, price_currency, rating, review_count,
 			reviews, amenities, photos, verification_status, source_website,
-			source_url, external_id, accommodation_type
+			source_url, external_id, accommodation_type, source_categories, taxonomy_version,
+			last_run_id, region, city, region_kato
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27,
+			$28, $29, $30, $31
 		) RETURNING id`
 
 	// Convert complex fields to JSON
@@ -737,6 +867,7 @@ func (p *YandexParser) insertAccommodation(record AccommodationRecord) bool {
 	reviewsJSON, _ := json.Marshal(record.Reviews)
 	amenitiesJSON, _ := json.Marshal(record.Amenities)
 	photosJSON, _ := json.Marshal(record.Photos)
+	categoriesJSON, _ := json.Marshal(record.SourceCategories)
 
 	var newID int
 	err := p.db.QueryRow(query,
@@ -747,13 +878,15 @@ func (p *YandexParser) insertAccommodation(record AccommodationRecord) bool {
 		record.PriceCurrency, record.Rating, record.ReviewCount, reviewsJSON,
 		amenitiesJSON, photosJSON, record.VerificationStatus,
 		record.SourceWebsite, record.SourceURL, record.ExternalID,
-		record.AccommodationType).Scan(&newID)
+		record.AccommodationType, categoriesJSON, p.types.Version, p.lastRunID(),
+		record.Region, record.City, record.RegionKATO).Scan(&newID)
 
 	if err != nil {
 		p.logError("insert", record, err)
 		return false
 	}
 
+	p.logSuccess("insert", record)
 	return true
 }
 
@@ -765,7 +898,9 @@ func (p *YandexParser) updateAccommodation(id int, record AccommodationRecord) b
 			service_description = $10, room_count = $11, capacity = $12,
 			price_range_min = $13, price_range_max = $14, rating = $15,
 			review_count = $16, reviews = $17, amenities = $18, photos = $19,
-			last_updated = CURRENT_TIMESTAMP
+			accommodation_type = $20, source_categories = $21, taxonomy_version = $22,
+			last_run_id = $23, region = $24, city = $25, region_kato = $26,
+			social_media_page = $27, last_updated = CURRENT_TIMESTAMP
 		WHERE id = $1`
 
 	// Convert complex fields to JSON
@@ -773,35 +908,81 @@ func (p *YandexParser) updateAccommodation(id int, record AccommodationRecord) b
 	reviewsJSON, _ := json.Marshal(record.Reviews)
 	amenitiesJSON, _ := json.Marshal(record.Amenities)
 	photosJSON, _ := json.Marshal(record.Photos)
+	categoriesJSON, _ := json.Marshal(record.SourceCategories)
 
 	_, err := p.db.Exec(query, id,
 		record.Name, record.Latitude, record.Longitude, record.Address,
 		record.Phone, record.Email, socialMediaJSON, record.WebsiteURL,
 		record.ServiceDescription, record.RoomCount, record.Capacity,
 		record.PriceRangeMin, record.PriceRangeMax, record.Rating,
-		record.ReviewCount, reviewsJSON, amenitiesJSON, photosJSON)
+		record.ReviewCount, reviewsJSON, amenitiesJSON, photosJSON,
+		record.AccommodationType, categoriesJSON, p.types.Version, p.lastRunID(),
+		record.Region, record.City, record.RegionKATO, record.SocialMediaPage)
 
 	if err != nil {
-		p.logError("insert", record, err)
+		p.logError("update", record, err)
 		return false
 	}
 
+	p.logSuccess("update", record)
 	return true
 }
 
+// StartRun opens the parsing run that the following writes are counted in and linked to
+func (p *YandexParser) StartRun() error {
+	run, err := runs.Start(context.Background(), p.db, "yandex", runs.NewID())
+	if err != nil {
+		return err
+	}
+	p.run = run
+	fmt.Printf("🆔 Parsing run: %s\n", run.ID)
+	return nil
+}
+
+// FinishRun closes the parsing run, as failed when nothing could be saved
+func (p *YandexParser) FinishRun() {
+	if p.run == nil {
+		return
+	}
+
+	status := runs.StatusCompleted
+	if p.successCount == 0 && p.errorCount > 0 {
+		status = runs.StatusFailed
+	}
+	if err := p.run.Finish(context.Background(), status); err != nil {
+		log.Printf("⚠️  Failed to finish parsing run %s: %v", p.run.ID, err)
+	}
+	if status == runs.StatusCompleted {
+		metrics.SetLastSuccessfulRun(metricsSource)
+	}
+}
+
+// lastRunID returns the ID of the open run, nil outside a run (e.g. when re-mapping types)
+func (p *YandexParser) lastRunID() *string {
+	if p.run == nil {
+		return nil
+	}
+	return &p.run.ID
+}
+
 func (p *YandexParser) logSuccess(operation string, record AccommodationRecord) {
-	query := `INSERT INTO parsing_logs (source_website, external_id, operation, status) 
-			  VALUES ($1, $2, $3, $4)`
+	query := `INSERT INTO parsing_logs (source_website, external_id, operation, status, run_id) 
+			  VALUES ($1, $2, $3, $4, $5)`
 
-	p.db.Exec(query, "yandex", record.ExternalID, operation, "success")
+	p.db.Exec(query, "yandex", record.ExternalID, operation, "success", p.lastRunID())
+	p.run.AddOutcome(operation)
+	metrics.AddUpserts(metricsSource, operation, 1)
 }
 
 func (p *YandexParser) logError(operation string, record AccommodationRecord, err error) {
-	query := `INSERT INTO parsing_logs (source_website, operation, error_message, status) 
-			  VALUES ($1, $2, $3, $4)`
+	query := `INSERT INTO parsing_logs (source_website, external_id, operation, error_message, status, run_id) 
+			  VALUES ($1, $2, $3, $4, $5, $6)`
 
-	p.db.Exec(query, "yandex", operation, err.Error(), "failed")
+	p.db.Exec(query, "yandex", record.ExternalID, operation, err.Error(), "failed", p.lastRunID())
 	p.errorCount++
+	p.run.AddOutcome("failed")
+	metrics.AddUpserts(metricsSource, "failed", 1)
+	p.run.AddError(fmt.Errorf("%s %s: %w", operation, record.Name, err))
 }
 
 func (p *YandexParser) ShowFinalStatistics() {
@@ -822,18 +1003,25 @@ func (p *YandexParser) ShowFinalStatistics() {
 	p.db.QueryRow("SELECT COUNT(*) FROM accommodations WHERE source_website = 'yandex'").Scan(&totalInDB)
 	fmt.Printf("💾 Total in Database: %d Yandex records\n", totalInDB)
 
+	// Run statistics
+	if p.run != nil {
+		counts := p.run.Counts()
+		fmt.Printf("🆔 Run %s: %d fetched, %d inserted, %d updated, %d failed\n",
+			p.run.ID, counts.Fetched, counts.Inserted, counts.Updated, counts.Failed)
+	}
+
 	// Log statistics
 	var totalLogs, successLogs, errorLogs int
 	p.db.QueryRow("SELECT COUNT(*) FROM parsing_logs WHERE source_website = 'yandex'").Scan(&totalLogs)
-	p.db.QueryRow("SELECT COUNT(*) FROM parsing_logs WHERE source_website = 'yandex' AND success = true").Scan(&successLogs)
-	p.db.QueryRow("SELECT COUNT(*) FROM parsing_logs WHERE source_website = 'yandex' AND success = false").Scan(&errorLogs)
+	p.db.QueryRow("SELECT COUNT(*) FROM parsing_logs WHERE source_website = 'yandex' AND status = 'success'").Scan(&successLogs)
+	p.db.QueryRow("SELECT COUNT(*) FROM parsing_logs WHERE source_website = 'yandex' AND status = 'failed'").Scan(&errorLogs)
 
 	fmt.Printf("📋 Log Entries: %d total (%d success, %d errors)\n", totalLogs, successLogs, errorLogs)
 
 	fmt.Println("\n🔍 To check results:")
 	fmt.Println("   docker exec -it hacknu_mytravel-postgres-1 psql -U postgres -d mytravel_db")
 	fmt.Println("   SELECT accommodation_type, COUNT(*) FROM accommodations GROUP BY accommodation_type;")
-	fmt.Println("   SELECT success, COUNT(*) FROM parsing_logs GROUP BY success;")
+	fmt.Println("   SELECT * FROM parsing_runs WHERE source_website = 'yandex' ORDER BY started_at DESC LIMIT 5;")
 	fmt.Println(strings.Repeat("=", 70))
 }
 
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"time"
+
+	"mytravel/pkg/metrics"
+)
+
+// metricsSource is the source label of Yandex metrics
+const metricsSource = "yandex"
+
+// rateLimitWait sleeps for wait and records it as a rate limit wait
+func rateLimitWait(wait time.Duration) {
+	metrics.ObserveRateLimitWait(metricsSource, wait)
+	time.Sleep(wait)
+}
@@ -62,6 +62,6 @@ echo "🚀 To run the parser:"
 echo "   ./yandex_parser"
 echo ""
 echo "🐳 To run with Docker:"
-echo "   docker build -t yandex-parser ."
+echo "   docker build -f Dockerfile -t yandex-parser ../.."
 echo "   docker run --network hacknu_mytravel_default -e DB_HOST=postgres yandex-parser"
 echo ""
\ No newline at end of file
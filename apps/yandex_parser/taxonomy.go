@@ -0,0 +1,63 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// RemapAccommodationTypes re-maps accommodation types of existing Yandex rows with the loaded mapping
+func (p *YandexParser) RemapAccommodationTypes(force bool) error {
+	rows, err := p.db.Query(`
+		SELECT id, accommodation_type, source_categories
+		FROM accommodations
+		WHERE source_website = 'yandex'
+		  AND ($1 OR taxonomy_version IS DISTINCT FROM $2)`,
+		force, p.types.Version)
+	if err != nil {
+		return fmt.Errorf("failed to query accommodations to re-map: %w", err)
+	}
+
+	updates := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var currentType, sourceCategories sql.NullString
+		if err := rows.Scan(&id, &currentType, &sourceCategories); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan accommodation to re-map: %w", err)
+		}
+
+		var categories []string
+		if sourceCategories.Valid {
+			json.Unmarshal([]byte(sourceCategories.String), &categories)
+		}
+		// Rows written before source categories were stored keep the Yandex category as their type
+		if len(categories) == 0 && currentType.Valid {
+			categories = []string{currentType.String}
+		}
+		updates[id] = p.types.Map(categories...)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read accommodations to re-map: %w", err)
+	}
+
+	updated := 0
+	for id, accommodationType := range updates {
+		result, err := p.db.Exec(`
+			UPDATE accommodations
+			SET accommodation_type = $1, taxonomy_version = $2
+			WHERE id = $3 AND (accommodation_type IS DISTINCT FROM $1 OR taxonomy_version IS DISTINCT FROM $2)`,
+			accommodationType, p.types.Version, id)
+		if err != nil {
+			return fmt.Errorf("failed to re-map accommodation %d: %w", id, err)
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+			updated++
+		}
+	}
+
+	fmt.Printf("🔁 Re-mapped Yandex rows to taxonomy version %d: %d checked, %d updated\n",
+		p.types.Version, len(updates), updated)
+	return nil
+}
@@ -0,0 +1,11 @@
+drop trigger if exists update_accommodations_last_updated on accommodations;
+
+drop function if exists update_last_updated_column();
+
+drop table if exists parsing_logs;
+
+drop table if exists accommodations;
+
+drop type if exists source_website;
+
+drop type if exists verification_status;
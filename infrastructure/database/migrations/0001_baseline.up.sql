@@ -1,3 +1,6 @@
+-- Schema of the original init.sql. Databases created from init.sql are baselined at this
+-- version by migrate and brought up to date by the following migrations.
+
 create type verification_status as enum ('new', 'verified', 'in_development');
 
 alter type verification_status owner to postgres;
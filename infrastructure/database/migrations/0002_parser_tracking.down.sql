@@ -0,0 +1,46 @@
+drop function if exists accommodation_is_open_at(jsonb, timestamp with time zone);
+
+drop function if exists opening_hours_local_time(timestamp with time zone, text);
+
+drop function if exists opening_hours_date(text);
+
+drop function if exists opening_hours_minute(text);
+
+drop trigger if exists record_accommodations_history on accommodations;
+
+drop function if exists record_accommodation_history();
+
+drop table if exists accommodation_history;
+
+create or replace function update_last_updated_column() returns trigger
+    language plpgsql
+as
+$$
+BEGIN
+    NEW.last_updated = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$;
+
+drop table if exists accommodation_closures;
+
+drop table if exists listing_sightings;
+
+drop table if exists crawl_checkpoints;
+
+drop index if exists idx_parsing_logs_run;
+
+alter table parsing_logs
+    drop column if exists run_id;
+
+drop table if exists parsing_runs;
+
+alter table accommodations
+    drop column if exists opening_hours,
+    drop column if exists source_categories,
+    drop column if exists taxonomy_version,
+    drop column if exists discovery_method,
+    drop column if exists source_updated_at,
+    drop column if exists full_refreshed_at,
+    drop column if exists consecutive_misses,
+    drop column if exists last_run_id;
@@ -0,0 +1,333 @@
+-- Brings databases created from init.sql up to date with what the parsers and services write:
+-- accommodation tracking columns, parsing runs, crawl checkpoints, listing sightings, closure
+-- detection and field history. Every statement tolerates objects that already exist, so
+-- databases that were patched by hand converge on the same schema.
+
+alter type source_website add value if not exists 'booking';
+
+alter table accommodations
+    add column if not exists opening_hours      jsonb, -- normalized weekly and special schedule, see accommodation_is_open_at
+    add column if not exists source_categories  jsonb,   -- raw source categories accommodation_type was mapped from
+    add column if not exists taxonomy_version   integer, -- version of the accommodation type mapping used
+    add column if not exists discovery_method   varchar(20), -- how a 2GIS business was found: 'rubric' or 'keyword'
+    add column if not exists source_updated_at  timestamp with time zone, -- last change reported by the source (2GIS dates.updated_at)
+    add column if not exists full_refreshed_at  timestamp with time zone, -- last full-detail fetch, drives the periodic full refresh
+    add column if not exists consecutive_misses integer not null default 0, -- failed closure re-checks in a row, see accommodation_closures
+    add column if not exists last_run_id        varchar(50); -- parser run that last wrote the record, see accommodation_history
+
+-- One row per parser run with its aggregate statistics, parsing_logs rows link to it
+create table if not exists parsing_runs
+(
+    run_id         varchar(50)    not null,
+    source_website source_website not null,
+    status         varchar(20)    not null default 'running', -- 'running', 'completed', 'failed' or 'interrupted'
+    started_at     timestamp with time zone not null default CURRENT_TIMESTAMP,
+    finished_at    timestamp with time zone,
+    fetched        integer        not null default 0, -- records listed by the source
+    inserted       integer        not null default 0,
+    updated        integer        not null default 0,
+    skipped        integer        not null default 0, -- unchanged records
+    failed         integer        not null default 0,
+    api_calls      integer        not null default 0,
+    error_samples  jsonb          not null default '[]'::jsonb, -- first error messages of the run
+    primary key (source_website, run_id)
+);
+
+alter table parsing_runs
+    owner to postgres;
+
+create index if not exists idx_parsing_runs_started_at
+    on parsing_runs (started_at);
+
+alter table parsing_logs
+    add column if not exists run_id varchar(50); -- parsing run the entry belongs to
+
+alter table parsing_logs
+    drop constraint if exists parsing_logs_source_website_run_id_fkey;
+
+alter table parsing_logs
+    add constraint parsing_logs_source_website_run_id_fkey
+        foreign key (source_website, run_id) references parsing_runs (source_website, run_id);
+
+create index if not exists idx_parsing_logs_run
+    on parsing_logs (source_website, run_id);
+
+create table if not exists crawl_checkpoints
+(
+    run_id          varchar(50)    not null, -- crawl run the checkpoint belongs to
+    source_website  source_website not null,
+    region_id       varchar(50)    not null,
+    rubric_id       varchar(50)    not null,
+    last_page       integer        not null default 0, -- last page fetched and stored
+    fetched_count   integer        not null default 0,
+    completed       boolean        not null default false,
+    updated_at      timestamp with time zone default CURRENT_TIMESTAMP,
+    primary key (source_website, run_id, region_id, rubric_id)
+);
+
+alter table crawl_checkpoints
+    owner to postgres;
+
+-- Businesses listed per region and rubric, used to find listings that vanished after a complete crawl
+create table if not exists listing_sightings
+(
+    source_website  source_website not null,
+    region_id       varchar(50)    not null,
+    rubric_id       varchar(50)    not null,
+    external_id     varchar(100)   not null,
+    last_seen_run   varchar(50)    not null, -- crawl run that last listed the business
+    last_seen_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    primary key (source_website, region_id, rubric_id, external_id)
+);
+
+alter table listing_sightings
+    owner to postgres;
+
+-- Audit trail of closure detection: misses, soft-deletes and restores
+create table if not exists accommodation_closures
+(
+    id               serial
+        primary key,
+    accommodation_id integer        not null
+        references accommodations
+            on delete cascade,
+    source_website   source_website not null,
+    external_id      varchar(100),
+    action           varchar(20)    not null, -- 'missed', 'deleted' or 'restored'
+    reason           text,
+    created_at       timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+alter table accommodation_closures
+    owner to postgres;
+
+create index if not exists idx_accommodation_closures_accommodation
+    on accommodation_closures (accommodation_id);
+
+create or replace function update_last_updated_column() returns trigger
+    language plpgsql
+as
+$$
+BEGIN
+    -- Refresh, closure and run bookkeeping is not a change of the record
+    IF to_jsonb(NEW) - 'full_refreshed_at' - 'consecutive_misses' - 'last_run_id' - 'last_updated' IS DISTINCT FROM
+       to_jsonb(OLD) - 'full_refreshed_at' - 'consecutive_misses' - 'last_run_id' - 'last_updated' THEN
+        NEW.last_updated = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+alter function update_last_updated_column() owner to postgres;
+
+-- Field-level change history of accommodations, one row per changed column and update
+create table if not exists accommodation_history
+(
+    id               bigserial
+        primary key,
+    accommodation_id integer        not null
+        references accommodations
+            on delete cascade,
+    field            varchar(64)    not null,
+    old_value        jsonb,
+    new_value        jsonb,
+    source_website   source_website not null,
+    run_id           varchar(50), -- last_run_id of the write, NULL for manual edits
+    changed_at       timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+alter table accommodation_history
+    owner to postgres;
+
+create index if not exists idx_accommodation_history_accommodation
+    on accommodation_history (accommodation_id, changed_at desc);
+
+create index if not exists idx_accommodation_history_field
+    on accommodation_history (field, changed_at desc);
+
+-- Records every changed column of an updated accommodation in accommodation_history.
+-- Bookkeeping columns are skipped, the same ones update_last_updated_column ignores.
+create or replace function record_accommodation_history() returns trigger
+    language plpgsql
+as
+$$
+DECLARE
+    old_row jsonb := to_jsonb(OLD) - 'id' - 'created_at' - 'last_updated' - 'full_refreshed_at'
+                     - 'consecutive_misses' - 'last_run_id' - 'source_updated_at';
+    new_row jsonb := to_jsonb(NEW) - 'id' - 'created_at' - 'last_updated' - 'full_refreshed_at'
+                     - 'consecutive_misses' - 'last_run_id' - 'source_updated_at';
+BEGIN
+    IF old_row = new_row THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO accommodation_history (accommodation_id, field, old_value, new_value, source_website, run_id)
+    SELECT NEW.id, changed.key, nullif(old_row -> changed.key, 'null'::jsonb),
+           nullif(new_row -> changed.key, 'null'::jsonb), NEW.source_website, NEW.last_run_id
+    FROM jsonb_each(new_row) AS changed
+    WHERE changed.value IS DISTINCT FROM old_row -> changed.key;
+
+    RETURN NULL;
+END;
+$$;
+
+alter function record_accommodation_history() owner to postgres;
+
+drop trigger if exists record_accommodations_history on accommodations;
+
+create trigger record_accommodations_history
+    after update
+    on accommodations
+    for each row
+execute procedure record_accommodation_history();
+
+-- Minutes since midnight of an "H:MM" or "HH:MM" clock, "24:00" included. Returns NULL for
+-- anything else, the same values OpeningHours.IsOpenAt in the parsers accepts.
+create or replace function opening_hours_minute(clock text) returns integer
+    language plpgsql
+    immutable
+as
+$$
+DECLARE
+    hours_part   integer;
+    minutes_part integer;
+BEGIN
+    clock := btrim(clock, E' \t\r\n');
+    IF clock IS NULL OR clock !~ '^[0-9]{1,2}:[0-9]{2}$' THEN
+        RETURN NULL;
+    END IF;
+
+    hours_part := split_part(clock, ':', 1)::integer;
+    minutes_part := split_part(clock, ':', 2)::integer;
+    IF hours_part > 24 OR minutes_part > 59 OR hours_part * 60 + minutes_part > 1440 THEN
+        RETURN NULL;
+    END IF;
+    RETURN hours_part * 60 + minutes_part;
+END;
+$$;
+
+alter function opening_hours_minute(text) owner to postgres;
+
+-- YYYY-MM-DD date of a special hours entry, NULL when it is missing or not a valid date
+create or replace function opening_hours_date(value text) returns date
+    language plpgsql
+    stable
+as
+$$
+BEGIN
+    IF value IS NULL OR value !~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}$' THEN
+        RETURN NULL;
+    END IF;
+    RETURN value::date;
+EXCEPTION
+    WHEN datetime_field_overflow OR invalid_datetime_format THEN
+        RETURN NULL;
+END;
+$$;
+
+alter function opening_hours_date(text) owner to postgres;
+
+-- Local time of a moment in the schedule timezone, Asia/Almaty when it is empty or unknown
+create or replace function opening_hours_local_time(at_time timestamp with time zone, zone_name text) returns timestamp
+    language plpgsql
+    stable
+as
+$$
+BEGIN
+    IF coalesce(zone_name, '') IN ('', 'Asia/Almaty') THEN
+        RETURN at_time AT TIME ZONE 'Asia/Almaty';
+    END IF;
+
+    BEGIN
+        RETURN at_time AT TIME ZONE zone_name;
+    EXCEPTION
+        WHEN invalid_parameter_value THEN
+            RETURN at_time AT TIME ZONE 'Asia/Almaty';
+    END;
+END;
+$$;
+
+alter function opening_hours_local_time(timestamp with time zone, text) owner to postgres;
+
+-- Reports whether an accommodation is open at the given moment according to its normalized
+-- opening_hours. Times are interpreted in the schedule timezone (Asia/Almaty by default).
+-- Returns NULL when the opening hours are unknown. Malformed intervals, special hours with
+-- invalid dates and unknown timezones are skipped like OpeningHours.IsOpenAt does, instead
+-- of failing the whole query.
+create or replace function accommodation_is_open_at(hours jsonb, at_time timestamp with time zone) returns boolean
+    language plpgsql
+    stable
+as
+$$
+DECLARE
+    local_time    timestamp;
+    minute_of_day integer;
+    day_offset    integer;
+    check_day     date;
+    day_hours     jsonb;
+    special       jsonb;
+    day_closed    boolean;
+    time_range    jsonb;
+    from_minute   integer;
+    to_minute     integer;
+BEGIN
+    IF hours IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    local_time := opening_hours_local_time(at_time, hours ->> 'timezone');
+    minute_of_day := extract(hour from local_time)::integer * 60 + extract(minute from local_time)::integer;
+
+    -- Offset 0 checks today, offset 1 checks yesterday's intervals running past midnight
+    FOR day_offset IN 0..1 LOOP
+        check_day := local_time::date - day_offset;
+        day_hours := hours -> 'week' -> ((array ['sun', 'mon', 'tue', 'wed', 'thu', 'fri', 'sat'])[extract(dow from check_day)::integer + 1]);
+        day_closed := false;
+
+        IF jsonb_typeof(hours -> 'special') = 'array' THEN
+            FOR special IN SELECT value FROM jsonb_array_elements(hours -> 'special') LOOP
+                IF check_day BETWEEN opening_hours_date(special ->> 'date_from') AND opening_hours_date(special ->> 'date_to') THEN
+                    IF special -> 'closed' = 'true'::jsonb THEN
+                        day_closed := true;
+                        EXIT;
+                    END IF;
+                    IF jsonb_typeof(special -> 'hours') = 'array' AND jsonb_array_length(special -> 'hours') > 0 THEN
+                        day_hours := special -> 'hours';
+                        EXIT;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+
+        IF day_offset = 0 THEN
+            IF day_closed THEN
+                RETURN false;
+            END IF;
+            IF hours -> 'is_24x7' = 'true'::jsonb THEN
+                RETURN true;
+            END IF;
+        END IF;
+
+        CONTINUE WHEN day_closed OR coalesce(jsonb_typeof(day_hours), '') <> 'array';
+
+        FOR time_range IN SELECT value FROM jsonb_array_elements(day_hours) LOOP
+            from_minute := opening_hours_minute(time_range ->> 'from');
+            to_minute := opening_hours_minute(time_range ->> 'to');
+            CONTINUE WHEN from_minute IS NULL OR to_minute IS NULL;
+
+            IF day_offset = 0 THEN
+                IF (to_minute > from_minute AND minute_of_day >= from_minute AND minute_of_day < to_minute)
+                    OR (to_minute <= from_minute AND minute_of_day >= from_minute) THEN
+                    RETURN true;
+                END IF;
+            ELSIF to_minute <= from_minute AND minute_of_day < to_minute THEN
+                RETURN true;
+            END IF;
+        END LOOP;
+    END LOOP;
+
+    RETURN false;
+END;
+$$;
+
+alter function accommodation_is_open_at(jsonb, timestamp with time zone) owner to postgres;
@@ -0,0 +1,5 @@
+drop table if exists place_match_overrides;
+
+drop table if exists place_sources;
+
+drop table if exists places;
@@ -0,0 +1,60 @@
+-- Canonical places: accommodation records of different sources that describe the same property,
+-- clustered by place_matcher
+create table places
+(
+    id                       serial
+        primary key,
+    primary_accommodation_id integer
+        references accommodations
+            on delete set null, -- record the place is shown as
+    name                     varchar(500) not null,
+    latitude                 numeric(10, 8),
+    longitude                numeric(11, 8),
+    source_count             integer not null default 1, -- linked records
+    created_at               timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at               timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+alter table places
+    owner to postgres;
+
+-- Links every accommodation record to exactly one place
+create table place_sources
+(
+    accommodation_id integer        not null
+        primary key
+        references accommodations
+            on delete cascade,
+    place_id         integer        not null
+        references places
+            on delete cascade,
+    confidence       numeric(4, 3)  not null, -- best match score linking the record to the place, 1 when alone or merged by hand
+    match_method     varchar(20)    not null, -- 'single', 'auto' or 'manual'
+    matched_at       timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+alter table place_sources
+    owner to postgres;
+
+create index idx_place_sources_place
+    on place_sources (place_id);
+
+-- Manual corrections of the matching: 'merge' always puts two records in the same place,
+-- 'split' never does. Pairs are stored with the lower accommodation ID first.
+create table place_match_overrides
+(
+    accommodation_id       integer     not null
+        references accommodations
+            on delete cascade,
+    other_accommodation_id integer     not null
+        references accommodations
+            on delete cascade,
+    action                 varchar(10) not null check (action in ('merge', 'split')),
+    reason                 text,
+    created_at             timestamp with time zone default CURRENT_TIMESTAMP,
+    primary key (accommodation_id, other_accommodation_id),
+    check (accommodation_id < other_accommodation_id)
+);
+
+alter table place_match_overrides
+    owner to postgres;
@@ -0,0 +1,18 @@
+drop view if exists merged_accommodations;
+
+alter table places
+    drop column if exists address,
+    drop column if exists phone,
+    drop column if exists email,
+    drop column if exists website_url,
+    drop column if exists social_media_links,
+    drop column if exists accommodation_type,
+    drop column if exists price_range_min,
+    drop column if exists price_range_max,
+    drop column if exists price_currency,
+    drop column if exists photos,
+    drop column if exists rating,
+    drop column if exists review_count,
+    drop column if exists amenities,
+    drop column if exists sources,
+    drop column if exists provenance;
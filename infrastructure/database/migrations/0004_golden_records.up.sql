@@ -0,0 +1,122 @@
+-- Golden record of every place: each field is taken from the linked record chosen by the merge
+-- policy of place_matcher, provenance names that record per field
+alter table places
+    add column if not exists address            text,
+    add column if not exists phone              varchar(50),
+    add column if not exists email              varchar(100),
+    add column if not exists website_url        text,
+    add column if not exists social_media_links jsonb,
+    add column if not exists accommodation_type varchar(50),
+    add column if not exists price_range_min    numeric(10, 2),
+    add column if not exists price_range_max    numeric(10, 2),
+    add column if not exists price_currency     varchar(3),
+    add column if not exists photos             jsonb,
+    add column if not exists rating             numeric(3, 2),
+    add column if not exists review_count       integer,
+    add column if not exists amenities          jsonb,
+    add column if not exists sources            text[],  -- sources of the linked records
+    add column if not exists provenance         jsonb;   -- {"phone": {"source": "2gis", "accommodation_id": 1, "updated_at": "..."}, ...}
+
+-- Until place_matcher merges again, places show their primary record
+update places p
+set address            = a.address,
+    phone              = a.phone,
+    email              = a.email,
+    website_url        = a.website_url,
+    social_media_links = a.social_media_links,
+    accommodation_type = a.accommodation_type,
+    price_range_min    = a.price_range_min,
+    price_range_max    = a.price_range_max,
+    price_currency     = a.price_currency,
+    photos             = a.photos,
+    rating             = a.rating,
+    review_count       = a.review_count,
+    amenities          = a.amenities,
+    sources            = (select array_agg(distinct s.source_website::text order by s.source_website::text)
+                          from place_sources ps
+                                   join accommodations s on s.id = ps.accommodation_id
+                          where ps.place_id = p.id)
+from accommodations a
+where a.id = p.primary_accommodation_id;
+
+-- One row per place with the golden values, and one per record not linked to a place yet.
+-- id is the primary record of the place; fields without a merge policy come from that record.
+create or replace view merged_accommodations as
+select a.id,
+       p.id                                               as place_id,
+       p.name,
+       p.latitude,
+       p.longitude,
+       p.address,
+       p.phone,
+       p.email,
+       p.social_media_links,
+       p.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       p.price_range_min,
+       p.price_range_max,
+       coalesce(p.price_currency, a.price_currency)       as price_currency,
+       p.photos,
+       p.rating,
+       coalesce(p.review_count, 0)                        as review_count,
+       a.reviews,
+       p.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       coalesce(p.accommodation_type, a.accommodation_type) as accommodation_type,
+       a.opening_hours,
+       coalesce(p.sources, array [a.source_website::text]) as sources,
+       p.source_count,
+       p.provenance
+from places p
+         join accommodations a on a.id = p.primary_accommodation_id
+where a.deleted_at is null
+union all
+select a.id,
+       null::integer,
+       a.name,
+       a.latitude,
+       a.longitude,
+       a.address,
+       a.phone,
+       a.email,
+       a.social_media_links,
+       a.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       a.price_range_min,
+       a.price_range_max,
+       a.price_currency,
+       a.photos,
+       a.rating,
+       coalesce(a.review_count, 0),
+       a.reviews,
+       a.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       a.accommodation_type,
+       a.opening_hours,
+       array [a.source_website::text],
+       1,
+       null::jsonb
+from accommodations a
+where a.deleted_at is null
+  and not exists (select 1 from place_sources ps where ps.accommodation_id = a.id);
+
+alter view merged_accommodations
+    owner to postgres;
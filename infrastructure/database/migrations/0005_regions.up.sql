@@ -0,0 +1,144 @@
+-- Region and city of every record, resolved from its coordinates against the Kazakhstan
+-- boundaries bundled with the parsers. region_kato is the KATO code of the region.
+alter table accommodations
+    add column if not exists region      varchar(100),
+    add column if not exists city        varchar(100),
+    add column if not exists region_kato varchar(9);
+
+create index if not exists idx_accommodations_region
+    on accommodations (region, city);
+
+-- region, city and region_kato follow from the coordinates: filling them in, as the
+-- backfill_regions command does, is not a change of the record
+create or replace function update_last_updated_column() returns trigger
+    language plpgsql
+as
+$$
+BEGIN
+    -- Refresh, closure and run bookkeeping is not a change of the record
+    IF to_jsonb(NEW) - 'full_refreshed_at' - 'consecutive_misses' - 'last_run_id' - 'last_updated'
+           - 'region' - 'city' - 'region_kato' IS DISTINCT FROM
+       to_jsonb(OLD) - 'full_refreshed_at' - 'consecutive_misses' - 'last_run_id' - 'last_updated'
+           - 'region' - 'city' - 'region_kato' THEN
+        NEW.last_updated = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$;
+
+create or replace function record_accommodation_history() returns trigger
+    language plpgsql
+as
+$$
+DECLARE
+    old_row jsonb := to_jsonb(OLD) - 'id' - 'created_at' - 'last_updated' - 'full_refreshed_at'
+                     - 'consecutive_misses' - 'last_run_id' - 'source_updated_at'
+                     - 'region' - 'city' - 'region_kato';
+    new_row jsonb := to_jsonb(NEW) - 'id' - 'created_at' - 'last_updated' - 'full_refreshed_at'
+                     - 'consecutive_misses' - 'last_run_id' - 'source_updated_at'
+                     - 'region' - 'city' - 'region_kato';
+BEGIN
+    IF old_row = new_row THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO accommodation_history (accommodation_id, field, old_value, new_value, source_website, run_id)
+    SELECT NEW.id, changed.key, nullif(old_row -> changed.key, 'null'::jsonb),
+           nullif(new_row -> changed.key, 'null'::jsonb), NEW.source_website, NEW.last_run_id
+    FROM jsonb_each(new_row) AS changed
+    WHERE changed.value IS DISTINCT FROM old_row -> changed.key;
+
+    RETURN NULL;
+END;
+$$;
+
+-- One row per place with the golden values, and one per record not linked to a place yet.
+-- id is the primary record of the place; fields without a merge policy come from that record.
+-- region, city and region_kato belong to the record the golden coordinates were taken from.
+create or replace view merged_accommodations as
+select a.id,
+       p.id                                               as place_id,
+       p.name,
+       p.latitude,
+       p.longitude,
+       p.address,
+       p.phone,
+       p.email,
+       p.social_media_links,
+       p.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       p.price_range_min,
+       p.price_range_max,
+       coalesce(p.price_currency, a.price_currency)       as price_currency,
+       p.photos,
+       p.rating,
+       coalesce(p.review_count, 0)                        as review_count,
+       a.reviews,
+       p.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       coalesce(p.accommodation_type, a.accommodation_type) as accommodation_type,
+       a.opening_hours,
+       coalesce(p.sources, array [a.source_website::text]) as sources,
+       p.source_count,
+       p.provenance,
+       c.region,
+       c.city,
+       c.region_kato
+from places p
+         join accommodations a on a.id = p.primary_accommodation_id
+         left join accommodations c
+                   on c.id = coalesce((p.provenance -> 'coordinates' ->> 'accommodation_id')::integer, a.id)
+where a.deleted_at is null
+union all
+select a.id,
+       null::integer,
+       a.name,
+       a.latitude,
+       a.longitude,
+       a.address,
+       a.phone,
+       a.email,
+       a.social_media_links,
+       a.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       a.price_range_min,
+       a.price_range_max,
+       a.price_currency,
+       a.photos,
+       a.rating,
+       coalesce(a.review_count, 0),
+       a.reviews,
+       a.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       a.accommodation_type,
+       a.opening_hours,
+       array [a.source_website::text],
+       1,
+       null::jsonb,
+       a.region,
+       a.city,
+       a.region_kato
+from accommodations a
+where a.deleted_at is null
+  and not exists (select 1 from place_sources ps where ps.accommodation_id = a.id);
+
+alter view merged_accommodations
+    owner to postgres;
@@ -0,0 +1,101 @@
+-- A place whose primary record was soft-deleted is shown as another of its live records instead
+-- of disappearing together with them: its records are linked in place_sources, so they are not
+-- shown on their own either. place_matcher leaves soft-deleted records out of the next merge.
+
+-- One row per place with the golden values, and one per record not linked to a place yet.
+-- id is the primary record of the place; fields without a merge policy come from that record.
+-- While the primary record is soft-deleted, the place is shown as its live record with the lowest
+-- ID until place_matcher merges again; a place without live records is not shown.
+-- region, city and region_kato belong to the record the golden coordinates were taken from.
+create or replace view merged_accommodations as
+select a.id,
+       p.id                                               as place_id,
+       p.name,
+       p.latitude,
+       p.longitude,
+       p.address,
+       p.phone,
+       p.email,
+       p.social_media_links,
+       p.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       p.price_range_min,
+       p.price_range_max,
+       coalesce(p.price_currency, a.price_currency)       as price_currency,
+       p.photos,
+       p.rating,
+       coalesce(p.review_count, 0)                        as review_count,
+       a.reviews,
+       p.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       coalesce(p.accommodation_type, a.accommodation_type) as accommodation_type,
+       a.opening_hours,
+       coalesce(p.sources, array [a.source_website::text]) as sources,
+       p.source_count,
+       p.provenance,
+       c.region,
+       c.city,
+       c.region_kato
+from places p
+         join lateral (select m.*
+                       from accommodations m
+                       where m.deleted_at is null
+                         and (m.id = p.primary_accommodation_id
+                           or m.id in (select ps.accommodation_id from place_sources ps where ps.place_id = p.id))
+                       order by m.id = p.primary_accommodation_id desc, m.id
+                       limit 1) a on true
+         left join accommodations c
+                   on c.id = coalesce((p.provenance -> 'coordinates' ->> 'accommodation_id')::integer, a.id)
+union all
+select a.id,
+       null::integer,
+       a.name,
+       a.latitude,
+       a.longitude,
+       a.address,
+       a.phone,
+       a.email,
+       a.social_media_links,
+       a.website_url,
+       a.social_media_page,
+       a.service_description,
+       a.room_count,
+       a.capacity,
+       a.price_range_min,
+       a.price_range_max,
+       a.price_currency,
+       a.photos,
+       a.rating,
+       coalesce(a.review_count, 0),
+       a.reviews,
+       a.amenities,
+       a.verification_status,
+       a.last_updated,
+       a.source_website,
+       a.source_url,
+       a.external_id,
+       a.created_at,
+       a.deleted_at,
+       a.accommodation_type,
+       a.opening_hours,
+       array [a.source_website::text],
+       1,
+       null::jsonb,
+       a.region,
+       a.city,
+       a.region_kato
+from accommodations a
+where a.deleted_at is null
+  and not exists (select 1 from place_sources ps where ps.accommodation_id = a.id);
+
+alter view merged_accommodations
+    owner to postgres;